@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// ageArmorType is the armor type of age encrypted shares.
+const ageArmorType = "AGE ENCRYPTED FILE"
+
+// ageWorkFactor is the scrypt work factor for encrypting shares. Tests lower it to run faster.
+var ageWorkFactor = 18
+
+// ageEncryptShare encrypts a share line to an age scrypt passphrase recipient and returns the ASCII armored file.
+func ageEncryptShare(line string, passphrase string) (string, error) {
+	if passphrase == "" {
+		return "", errors.New("Passphrase must not be empty.")
+	}
+
+	recipient, err := age.NewScryptRecipient(passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	recipient.SetWorkFactor(ageWorkFactor)
+
+	var buf bytes.Buffer
+
+	aw := armor.NewWriter(&buf)
+
+	w, err := age.Encrypt(aw, recipient)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = io.WriteString(w, line)
+	if err != nil {
+		return "", err
+	}
+
+	err = w.Close()
+	if err != nil {
+		return "", err
+	}
+
+	err = aw.Close()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// ageDecryptShare decrypts an ASCII armored file created by ageEncryptShare.
+func ageDecryptShare(file string, passphrase string) (string, error) {
+	identity, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	r, err := age.Decrypt(armor.NewReader(strings.NewReader(file)), identity)
+	if err != nil {
+		return "", fmt.Errorf("decrypting age file: %w", err)
+	}
+
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("decrypting age file: %w", err)
+	}
+
+	return strings.TrimSpace(string(buf)), nil
+}
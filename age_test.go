@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+func TestAge_roundtrip(t *testing.T) {
+	defer func(w int) { ageWorkFactor = w }(ageWorkFactor)
+	ageWorkFactor = 10
+
+	passphrase := "correct horse battery staple"
+
+	opts := generateOptions{
+		encryptShare: func(line string) (string, error) {
+			return ageEncryptShare(line, passphrase)
+		},
+	}
+
+	var genBuf bytes.Buffer
+
+	err := cmdGenerate(5, 3, opts, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	output := genBuf.String()
+	if n := strings.Count(output, armor.Header); n != 5 {
+		t.Fatalf("want 5 age files, have %d: %q", n, output)
+	}
+
+	for _, tc := range []struct {
+		name       string
+		passphrase string
+		wantErr    bool
+	}{
+		{"correct passphrase", passphrase, false},
+		{"wrong passphrase", "incorrect horse battery staple", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := recoverOptions{
+				decryptShares: func(in io.Reader) (io.Reader, error) {
+					return decryptArmoredShares(in, ageArmorType, func(file string) (string, error) {
+						return ageDecryptShare(file, tc.passphrase)
+					})
+				},
+			}
+
+			var (
+				errBuf bytes.Buffer
+				outBuf bytes.Buffer
+			)
+
+			err := cmdRecover(strings.NewReader(output), opts, &errBuf, &outBuf)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, recovered %q", outBuf.String())
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if errBuf.Len() != 0 {
+				t.Errorf("unexpected diagnostic: %q", errBuf.String())
+			}
+
+			secret := strings.TrimPrefix(strings.SplitN(output, "\n", 2)[0], "secret: ")
+			if outBuf.String() != secret+"\n" {
+				t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
+			}
+		})
+	}
+}
+
+func TestAge_scryptInterop(t *testing.T) {
+	defer func(w int) { ageWorkFactor = w }(ageWorkFactor)
+	ageWorkFactor = 10
+
+	file, err := ageEncryptShare("1,2", "passphrase")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	identity, err := age.NewScryptIdentity("passphrase")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	r, err := age.Decrypt(armor.NewReader(strings.NewReader(file)), identity)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if string(buf) != "1,2" {
+		t.Errorf("want %q, have %q", "1,2", buf)
+	}
+
+	recipient, err := age.NewScryptRecipient("passphrase")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	recipient.SetWorkFactor(10)
+
+	var enc bytes.Buffer
+
+	aw := armor.NewWriter(&enc)
+
+	w, err := age.Encrypt(aw, recipient)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	io.WriteString(w, "3,4")
+	w.Close()
+	aw.Close()
+
+	line, err := ageDecryptShare(enc.String(), "passphrase")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if line != "3,4" {
+		t.Errorf("want %q, have %q", "3,4", line)
+	}
+}
+
+func TestAge_sinks(t *testing.T) {
+	defer func(w int) { ageWorkFactor = w }(ageWorkFactor)
+	ageWorkFactor = 10
+
+	var stored []storedShare
+
+	opts := generateOptions{
+		sinks: []shareSink{shareSinkFunc(func(shares []storedShare) error {
+			stored = shares
+			return nil
+		})},
+		encryptShare: func(line string) (string, error) {
+			return ageEncryptShare(line, "passphrase")
+		},
+	}
+
+	err := cmdGenerate(5, 3, opts, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(stored) != 5 {
+		t.Fatalf("want 5 stored shares, have %d", len(stored))
+	}
+
+	for _, share := range stored {
+		if !strings.HasPrefix(share.line, armor.Header) {
+			t.Fatalf("share %s stored unencrypted: %q", share.index, share.line)
+		}
+
+		line, err := ageDecryptShare(share.line, "passphrase")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		_, err = parseShare(line)
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"filippo.io/age"
+)
+
+// ageRecipientSink encrypts each share to the age public key of its custodian and writes it to <label>.age in dir.
+// Custodians decrypt their share with age -d -i <identity> <label>.age and paste the share line for recovery.
+type ageRecipientSink struct {
+	custodians []ageCustodian
+	dir        string
+}
+
+// newAgeRecipientSink reads the public keys of n custodians from the files <label>.pub in keysDir.
+func newAgeRecipientSink(keysDir, dir string, n int) (*ageRecipientSink, error) {
+	custodians, err := readAgeCustodians(keysDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(custodians) != n {
+		return nil, fmt.Errorf("There are %d age public keys in %s for %d shares.", len(custodians), keysDir, n)
+	}
+
+	return &ageRecipientSink{custodians: custodians, dir: dir}, nil
+}
+
+// ageCustodian is a custodian with their age public keys.
+type ageCustodian struct {
+	label      string
+	recipients []age.Recipient
+}
+
+// readAgeCustodians reads the public keys of the custodians from the .pub files in dir, sorted by label.
+func readAgeCustodians(dir string) ([]ageCustodian, error) {
+	names, err := filepath.Glob(filepath.Join(dir, "*.pub"))
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(names)
+
+	custodians := make([]ageCustodian, 0, len(names))
+
+	for _, name := range names {
+		fh, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+
+		recipients, err := age.ParseRecipients(fh)
+		fh.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", name, err)
+		}
+
+		custodians = append(custodians, ageCustodian{label: strings.TrimSuffix(filepath.Base(name), ".pub"), recipients: recipients})
+	}
+
+	return custodians, nil
+}
+
+func (a *ageRecipientSink) StoreShares(shares []storedShare) error {
+	if len(a.custodians) != len(shares) {
+		return fmt.Errorf("There are %d age public keys for %d shares.", len(a.custodians), len(shares))
+	}
+
+	for i, custodian := range a.custodians {
+		name := filepath.Join(a.dir, custodian.label+".age")
+
+		err := writeAgeFile(name, shares[i].line+"\n", custodian.recipients)
+		if err != nil {
+			return fmt.Errorf("writing %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// writeAgeFile encrypts text to recipients and writes it to the named file, which must not exist.
+func writeAgeFile(name, text string, recipients []age.Recipient) error {
+	fh, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+
+	w, err := age.Encrypt(fh, recipients...)
+	if err != nil {
+		fh.Close()
+		return err
+	}
+
+	_, err = io.WriteString(w, text)
+	if err != nil {
+		fh.Close()
+		return err
+	}
+
+	err = w.Close()
+	if err != nil {
+		fh.Close()
+		return err
+	}
+
+	return fh.Close()
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+)
+
+func TestAgeRecipientSink(t *testing.T) {
+	keysDir := t.TempDir()
+	dir := t.TempDir()
+
+	identities := make(map[string]*age.X25519Identity)
+
+	for _, label := range []string{"alice", "bob", "carol"} {
+		identity, err := age.GenerateX25519Identity()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		identities[label] = identity
+
+		pub := "# " + label + "\n" + identity.Recipient().String() + "\n"
+
+		err = os.WriteFile(filepath.Join(keysDir, label+".pub"), []byte(pub), 0600)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	sink, err := newAgeRecipientSink(keysDir, dir, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var genBuf bytes.Buffer
+
+	err = cmdGenerate(3, 2, generateOptions{sinks: []shareSink{sink}, withholdShares: true}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var pasted []string
+
+	for label, identity := range identities {
+		fh, err := os.Open(filepath.Join(dir, label+".age"))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		defer fh.Close()
+
+		r, err := age.Decrypt(fh, identity)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		buf, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		pasted = append(pasted, string(buf))
+
+		// A custodian can not decrypt the share of another custodian.
+		if label != "alice" {
+			other, _ := os.ReadFile(filepath.Join(dir, "alice.age"))
+
+			_, err := age.Decrypt(bytes.NewReader(other), identity)
+			if err == nil {
+				t.Errorf("%s decrypted the share of alice", label)
+			}
+		}
+	}
+
+	var outBuf bytes.Buffer
+
+	err = cmdRecover(strings.NewReader(strings.Join(pasted[:2], "")), recoverOptions{}, &bytes.Buffer{}, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !strings.HasPrefix(genBuf.String(), "secret: "+outBuf.String()) {
+		t.Errorf("unexpected recovered secret %q", outBuf.String())
+	}
+}
+
+func TestNewAgeRecipientSink_errors(t *testing.T) {
+	keysDir := t.TempDir()
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err = os.WriteFile(filepath.Join(keysDir, "alice.pub"), []byte(identity.Recipient().String()+"\n"), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, err = newAgeRecipientSink(keysDir, t.TempDir(), 2)
+	if err == nil {
+		t.Fatal("expected error for too few keys, got nil")
+	}
+
+	err = os.WriteFile(filepath.Join(keysDir, "bob.pub"), []byte("not a key\n"), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, err = newAgeRecipientSink(keysDir, t.TempDir(), 2)
+	if err == nil {
+		t.Fatal("expected error for an invalid key, got nil")
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// ethAnchor anchors the hash of a ceremony on Ethereum. The hash is the calldata of a transaction without value from
+// the anchoring account to itself, so that it is stored on the chain without deploying a contract.
+type ethAnchor struct {
+	client     *ethclient.Client
+	key        *ecdsa.PrivateKey
+	k          int
+	ceremonyID string
+	diag       io.Writer
+}
+
+func newEthAnchor(rpcURL, hexKey string, k int, ceremonyID string, diag io.Writer) (*ethAnchor, error) {
+	if hexKey == "" {
+		return nil, errors.New("Ethereum private key must not be empty.")
+	}
+
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(hexKey, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("parsing Ethereum private key: %w", err)
+	}
+
+	client, err := ethclient.Dial(rpcURL)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to %s: %w", rpcURL, err)
+	}
+
+	return &ethAnchor{client: client, key: key, k: k, ceremonyID: ceremonyID, diag: diag}, nil
+}
+
+func (a *ethAnchor) Close() {
+	a.client.Close()
+}
+
+// ceremonyHash returns SHA-256(ceremony parameters || root hash of the shares).
+func ceremonyHash(n, k int, ceremonyID string, shares []storedShare) []byte {
+	root := sharesRootHash(shares)
+
+	h := sha256.New()
+	fmt.Fprintf(h, "n=%d k=%d ceremony_id=%s\n", n, k, ceremonyID)
+	h.Write(root)
+
+	return h.Sum(nil)
+}
+
+// sharesRootHash returns the root of a binary Merkle tree over the SHA-256 hashes of the share lines in lexical order.
+// The last node of a level with an odd number of nodes is paired with itself.
+func sharesRootHash(shares []storedShare) []byte {
+	lines := make([]string, len(shares))
+	for i, share := range shares {
+		lines[i] = share.line
+	}
+
+	sort.Strings(lines)
+
+	level := make([][]byte, len(lines))
+	for i, line := range lines {
+		sum := sha256.Sum256([]byte(line))
+		level[i] = sum[:]
+	}
+
+	if len(level) == 0 {
+		sum := sha256.Sum256(nil)
+		return sum[:]
+	}
+
+	for len(level) > 1 {
+		var next [][]byte
+
+		for i := 0; i < len(level); i += 2 {
+			right := level[i]
+			if i+1 < len(level) {
+				right = level[i+1]
+			}
+
+			sum := sha256.Sum256(append(append([]byte{}, level[i]...), right...))
+			next = append(next, sum[:])
+		}
+
+		level = next
+	}
+
+	return level[0]
+}
+
+func (a *ethAnchor) StoreShares(shares []storedShare) error {
+	ctx := context.Background()
+	hash := ceremonyHash(len(shares), a.k, a.ceremonyID, shares)
+
+	tx, err := a.send(ctx, hash)
+	if err != nil {
+		return fmt.Errorf("anchoring ceremony on Ethereum: %w", err)
+	}
+
+	fmt.Fprintf(a.diag, "ceremony hash %x anchored in transaction %s\n", hash, tx.Hash().Hex())
+
+	return nil
+}
+
+// send signs and submits a transaction with data as calldata.
+func (a *ethAnchor) send(ctx context.Context, data []byte) (*types.Transaction, error) {
+	from := crypto.PubkeyToAddress(a.key.PublicKey)
+
+	chainID, err := a.client.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := a.client.PendingNonceAt(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+
+	gasPrice, err := a.client.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	gas, err := a.client.EstimateGas(ctx, ethereum.CallMsg{From: from, To: &from, Data: data})
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := types.SignNewTx(a.key, types.LatestSignerForChainID(chainID), &types.LegacyTx{
+		Nonce:    nonce,
+		To:       &from,
+		Gas:      gas,
+		GasPrice: gasPrice,
+		Data:     data,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.client.SendTransaction(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
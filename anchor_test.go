@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// fakeEthRPC implements the JSON-RPC methods used by ethAnchor and records the submitted transactions.
+type fakeEthRPC struct {
+	mu  sync.Mutex
+	txs []*types.Transaction
+}
+
+func (f *fakeEthRPC) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var result interface{}
+
+	switch req.Method {
+	case "eth_chainId":
+		result = "0x539"
+	case "eth_getTransactionCount":
+		result = "0x7"
+	case "eth_gasPrice":
+		result = "0x3b9aca00"
+	case "eth_estimateGas":
+		result = "0x61a8"
+	case "eth_sendRawTransaction":
+		var raw string
+
+		json.Unmarshal(req.Params[0], &raw)
+
+		buf, _ := hex.DecodeString(strings.TrimPrefix(raw, "0x"))
+
+		var tx types.Transaction
+
+		err := tx.UnmarshalBinary(buf)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		f.mu.Lock()
+		f.txs = append(f.txs, &tx)
+		f.mu.Unlock()
+
+		result = tx.Hash().Hex()
+	default:
+		http.Error(w, "unknown method "+req.Method, http.StatusBadRequest)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+}
+
+func TestEthAnchor_storeShares(t *testing.T) {
+	rpc := &fakeEthRPC{}
+
+	srv := httptest.NewServer(rpc)
+	defer srv.Close()
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var diag bytes.Buffer
+
+	anchor, err := newEthAnchor(srv.URL, hex.EncodeToString(crypto.FromECDSA(key)), 3, "2026-10", &diag)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer anchor.Close()
+
+	var lines []storedShare
+
+	record := shareSinkFunc(func(shares []storedShare) error {
+		lines = shares
+		return nil
+	})
+
+	err = cmdGenerate(5, 3, generateOptions{sinks: []shareSink{record, anchor}, ceremonyID: "2026-10"}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(rpc.txs) != 1 {
+		t.Fatalf("want 1 transaction, have %d", len(rpc.txs))
+	}
+
+	tx := rpc.txs[0]
+	want := ceremonyHash(5, 3, "2026-10", lines)
+
+	if !bytes.Equal(tx.Data(), want) {
+		t.Errorf("unexpected calldata. want %x, have %x", want, tx.Data())
+	}
+
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	if tx.To() == nil || *tx.To() != from || tx.Value().Sign() != 0 || tx.Nonce() != 7 {
+		t.Errorf("unexpected transaction: to=%v value=%s nonce=%d", tx.To(), tx.Value(), tx.Nonce())
+	}
+
+	if !strings.Contains(diag.String(), tx.Hash().Hex()) {
+		t.Errorf("transaction hash not reported: %q", diag.String())
+	}
+}
+
+func TestSharesRootHash(t *testing.T) {
+	a := []storedShare{{"1", "1,a"}, {"2", "2,b"}, {"3", "3,c"}}
+	b := []storedShare{{"3", "3,c"}, {"1", "1,a"}, {"2", "2,b"}}
+
+	if !bytes.Equal(sharesRootHash(a), sharesRootHash(b)) {
+		t.Error("root hash depends on the order of the shares")
+	}
+
+	if bytes.Equal(sharesRootHash(a), sharesRootHash(a[:2])) {
+		t.Error("root hash does not depend on all shares")
+	}
+}
+
+func TestNewEthAnchor_invalidKey(t *testing.T) {
+	for _, key := range []string{"", "0xnothex"} {
+		_, err := newEthAnchor("http://127.0.0.1:8545", key, 3, "", &bytes.Buffer{})
+		if err == nil {
+			t.Errorf("%q: expected an error", key)
+		}
+	}
+}
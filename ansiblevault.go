@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Parameters of the Ansible Vault 1.1 format.
+const (
+	ansibleVaultHeader     = "$ANSIBLE_VAULT;1.1;AES256"
+	ansibleVaultSaltLen    = 32
+	ansibleVaultIterations = 10000
+	ansibleVaultLineLen    = 80
+)
+
+// ansibleVaultKeys derives the AES key, the HMAC key and the counter IV of an Ansible Vault from password and salt.
+func ansibleVaultKeys(password, salt []byte) (aesKey, hmacKey, iv []byte, err error) {
+	key, err := pbkdf2.Key(sha256.New, string(password), salt, ansibleVaultIterations, 2*32+aes.BlockSize)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return key[:32], key[32:64], key[64:], nil
+}
+
+// ansibleVaultEncrypt encrypts plaintext in the Ansible Vault 1.1 format, which ansible-vault decrypt reads.
+func ansibleVaultEncrypt(plaintext, password []byte) (string, error) {
+	if len(password) == 0 {
+		return "", errors.New("Vault password must not be empty.")
+	}
+
+	salt := make([]byte, ansibleVaultSaltLen)
+
+	_, err := rand.Read(salt)
+	if err != nil {
+		return "", err
+	}
+
+	aesKey, hmacKey, iv, err := ansibleVaultKeys(password, salt)
+	if err != nil {
+		return "", err
+	}
+
+	block, err := aes.NewCipher(aesKey)
+	if err != nil {
+		return "", err
+	}
+
+	// Ansible pads the plaintext with PKCS#7 even though CTR mode does not need it.
+	pad := aes.BlockSize - len(plaintext)%aes.BlockSize
+	padded := append(append([]byte{}, plaintext...), bytes.Repeat([]byte{byte(pad)}, pad)...)
+
+	ciphertext := make([]byte, len(padded))
+	cipher.NewCTR(block, iv).XORKeyStream(ciphertext, padded)
+
+	mac := hmac.New(sha256.New, hmacKey)
+	mac.Write(ciphertext)
+
+	body := hex.EncodeToString(salt) + "\n" + hex.EncodeToString(mac.Sum(nil)) + "\n" + hex.EncodeToString(ciphertext)
+	encoded := hex.EncodeToString([]byte(body))
+
+	var b strings.Builder
+
+	b.WriteString(ansibleVaultHeader + "\n")
+
+	for len(encoded) > 0 {
+		n := min(ansibleVaultLineLen, len(encoded))
+		b.WriteString(encoded[:n] + "\n")
+		encoded = encoded[n:]
+	}
+
+	return b.String(), nil
+}
+
+// ansibleVaultSink writes each share to its own Ansible Vault encrypted YAML file share-<index>.yml in a directory.
+// The decrypted file holds the share line in the variable secret_share.
+type ansibleVaultSink struct {
+	dir      string
+	password []byte
+}
+
+func (a *ansibleVaultSink) StoreShares(shares []storedShare) error {
+	for _, share := range shares {
+		vault, err := ansibleVaultEncrypt([]byte("secret_share: "+strconv.Quote(share.line)+"\n"), a.password)
+		if err != nil {
+			return err
+		}
+
+		name := filepath.Join(a.dir, "share-"+share.index+".yml")
+
+		fh, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			return err
+		}
+
+		_, err = fh.WriteString(vault)
+		if err != nil {
+			fh.Close()
+			return fmt.Errorf("writing %s: %w", name, err)
+		}
+
+		err = fh.Close()
+		if err != nil {
+			return fmt.Errorf("writing %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// ansibleVaultDecrypt decrypts an Ansible Vault 1.1 file like ansible-vault decrypt.
+func ansibleVaultDecrypt(t *testing.T, vault string, password []byte) []byte {
+	t.Helper()
+
+	header, encoded, ok := strings.Cut(vault, "\n")
+	if !ok || header != ansibleVaultHeader {
+		t.Fatalf("unexpected vault header: %q", header)
+	}
+
+	body, err := hex.DecodeString(strings.ReplaceAll(encoded, "\n", ""))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	parts := strings.Split(string(body), "\n")
+	if len(parts) != 3 {
+		t.Fatalf("want 3 parts in the vault body, have %d", len(parts))
+	}
+
+	var raw [3][]byte
+
+	for i, part := range parts {
+		raw[i], err = hex.DecodeString(part)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	salt, sum, ciphertext := raw[0], raw[1], raw[2]
+
+	aesKey, hmacKey, iv, err := ansibleVaultKeys(password, salt)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	mac := hmac.New(sha256.New, hmacKey)
+	mac.Write(ciphertext)
+
+	if !hmac.Equal(mac.Sum(nil), sum) {
+		t.Fatal("HMAC mismatch")
+	}
+
+	block, err := aes.NewCipher(aesKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCTR(block, iv).XORKeyStream(plaintext, ciphertext)
+
+	pad := int(plaintext[len(plaintext)-1])
+	if pad < 1 || pad > aes.BlockSize || !bytes.Equal(plaintext[len(plaintext)-pad:], bytes.Repeat([]byte{byte(pad)}, pad)) {
+		t.Fatalf("invalid padding: %q", plaintext)
+	}
+
+	return plaintext[:len(plaintext)-pad]
+}
+
+func TestAnsibleVault_sink(t *testing.T) {
+	dir := t.TempDir()
+	password := []byte("vault password")
+
+	var lines []storedShare
+
+	record := shareSinkFunc(func(shares []storedShare) error {
+		lines = shares
+		return nil
+	})
+
+	opts := generateOptions{sinks: []shareSink{record, &ansibleVaultSink{dir: dir, password: password}}, withholdShares: true}
+
+	err := cmdGenerate(5, 3, opts, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, share := range lines {
+		buf, err := os.ReadFile(filepath.Join(dir, "share-"+share.index+".yml"))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if !strings.HasPrefix(string(buf), "$ANSIBLE_VAULT;1.1;AES256\n") {
+			t.Fatalf("missing vault header: %q", buf)
+		}
+
+		for _, line := range strings.Split(strings.TrimSpace(string(buf)), "\n")[1:] {
+			if len(line) > ansibleVaultLineLen {
+				t.Errorf("line longer than %d characters: %q", ansibleVaultLineLen, line)
+			}
+		}
+
+		want := "secret_share: " + strconv.Quote(share.line) + "\n"
+		if have := string(ansibleVaultDecrypt(t, string(buf), password)); have != want {
+			t.Errorf("unexpected plaintext. want %q, have %q", want, have)
+		}
+	}
+}
+
+func TestAnsibleVault_emptyPassword(t *testing.T) {
+	_, err := ansibleVaultEncrypt([]byte("secret_share: x\n"), nil)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
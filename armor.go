@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/farhaven/secret/shamir"
+)
+
+// decryptArmoredShares replaces the ASCII armored blocks of the given type in the input, like "PGP MESSAGE", with the
+// share lines decrypt returns for them. Other lines are passed through unchanged.
+func decryptArmoredShares(in io.Reader, armorType string, decrypt func(block string) (string, error)) (io.Reader, error) {
+	begin := "-----BEGIN " + armorType + "-----"
+	end := "-----END " + armorType + "-----"
+
+	var (
+		out   strings.Builder
+		block []string
+	)
+
+	scanner := shamir.NewScanner(in)
+	for scanner.Scan() {
+		t := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case t == begin:
+			block = []string{t}
+		case block == nil:
+			fmt.Fprintln(&out, t)
+		case t == end:
+			line, err := decrypt(strings.Join(append(block, t), "\n") + "\n")
+			if err != nil {
+				return nil, err
+			}
+
+			fmt.Fprintln(&out, line)
+
+			block = nil
+		default:
+			block = append(block, t)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if block != nil {
+		return nil, fmt.Errorf("Unterminated %s block.", armorType)
+	}
+
+	return strings.NewReader(out.String()), nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDecryptArmoredShares(t *testing.T) {
+	in := `secret: abc
+-----BEGIN TEST-----
+MQ==
+-----END TEST-----
+2,3
+`
+
+	decrypt := func(block string) (string, error) {
+		if block != "-----BEGIN TEST-----\nMQ==\n-----END TEST-----\n" {
+			t.Errorf("unexpected block %q", block)
+		}
+
+		return "1,2", nil
+	}
+
+	r, err := decryptArmoredShares(strings.NewReader(in), "TEST", decrypt)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	buf, _ := io.ReadAll(r)
+	if want := "secret: abc\n1,2\n2,3\n"; string(buf) != want {
+		t.Errorf("unexpected output. want %q, have %q", want, buf)
+	}
+
+	_, err = decryptArmoredShares(strings.NewReader("-----BEGIN TEST-----\nMQ==\n"), "TEST", decrypt)
+	if err == nil {
+		t.Error("expected an error for an unterminated block")
+	}
+}
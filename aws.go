@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// loadAWSConfig loads the AWS configuration from the default sources: environment, shared configuration files and
+// instance or task roles. region overrides the configured region if set. If roleARN is set, the loaded credentials are
+// used to assume that role.
+func loadAWSConfig(ctx context.Context, region, roleARN string) (aws.Config, error) {
+	var opts []func(*config.LoadOptions) error
+
+	if region != "" {
+		opts = append(opts, config.WithRegion(region))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return aws.Config{}, err
+	}
+
+	if roleARN != "" {
+		provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), roleARN)
+		cfg.Credentials = aws.NewCredentialsCache(provider)
+	}
+
+	return cfg, nil
+}
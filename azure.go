@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+)
+
+// azureCredential returns a credential for Azure. A service principal configured in the environment is preferred:
+// AZURE_TENANT_ID and AZURE_CLIENT_ID together with either AZURE_CLIENT_SECRET or AZURE_CLIENT_CERTIFICATE_PATH. The
+// managed identity of the host is used otherwise.
+func azureCredential() (azcore.TokenCredential, error) {
+	var creds []azcore.TokenCredential
+
+	env, err := azidentity.NewEnvironmentCredential(nil)
+	if err == nil {
+		creds = append(creds, env)
+	}
+
+	managed, err := azidentity.NewManagedIdentityCredential(nil)
+	if err == nil {
+		creds = append(creds, managed)
+	}
+
+	if len(creds) == 0 {
+		return nil, errors.New("No Azure credentials available.")
+	}
+
+	return azidentity.NewChainedTokenCredential(creds, nil)
+}
+
+// newAzureKeyVaultClient returns a client for the secrets in the key vault at vaultURL, using azureCredential.
+func newAzureKeyVaultClient(vaultURL string) (*azsecrets.Client, error) {
+	if vaultURL == "" {
+		return nil, errors.New("Azure Key Vault requires -vault-url.")
+	}
+
+	cred, err := azureCredential()
+	if err != nil {
+		return nil, err
+	}
+
+	return azsecrets.NewClient(vaultURL, cred, nil)
+}
+
+// azureKeyVaultStore stores shares as secrets named <prefix>-share-<index> in an Azure Key Vault.
+type azureKeyVaultStore struct {
+	client     *azsecrets.Client
+	prefix     string
+	ceremonyID string
+}
+
+func newAzureKeyVaultStore(client *azsecrets.Client, prefix, ceremonyID string) *azureKeyVaultStore {
+	return &azureKeyVaultStore{client: client, prefix: prefix, ceremonyID: ceremonyID}
+}
+
+func (a *azureKeyVaultStore) StoreShares(shares []storedShare) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	for _, share := range shares {
+		index := share.index
+		value := share.line
+		contentType := "text/plain"
+
+		tags := map[string]*string{
+			"managed-by":  strPtr("github.com/farhaven/secret"),
+			"share-index": &index,
+		}
+
+		if a.ceremonyID != "" {
+			tags["ceremony-id"] = &a.ceremonyID
+		}
+
+		_, err := a.client.SetSecret(ctx, a.prefix+"-share-"+index, azsecrets.SetSecretParameters{
+			Value:       &value,
+			ContentType: &contentType,
+			Tags:        tags,
+		}, nil)
+		if err != nil {
+			return fmt.Errorf("storing share in Azure Key Vault: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (a *azureKeyVaultStore) LoadShares() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	prefix := a.prefix + "-share-"
+
+	var names []string
+
+	pager := a.client.NewListSecretPropertiesPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing shares in Azure Key Vault: %w", err)
+		}
+
+		for _, props := range page.Value {
+			if props.ID == nil {
+				continue
+			}
+
+			if name := props.ID.Name(); strings.HasPrefix(name, prefix) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	sort.Strings(names)
+
+	lines := make([]string, 0, len(names))
+
+	for _, name := range names {
+		resp, err := a.client.GetSecret(ctx, name, "", nil)
+		if err != nil {
+			return nil, fmt.Errorf("reading share %s from Azure Key Vault: %w", name, err)
+		}
+
+		if resp.Value == nil {
+			return nil, fmt.Errorf("reading share %s from Azure Key Vault: secret has no value", name)
+		}
+
+		lines = append(lines, *resp.Value)
+	}
+
+	return lines, nil
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+)
+
+// fakeCredential hands out a static token.
+type fakeCredential struct{}
+
+func (fakeCredential) GetToken(ctx context.Context, opts policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+type fakeSecret struct {
+	Value       string             `json:"value,omitempty"`
+	ID          string             `json:"id,omitempty"`
+	ContentType string             `json:"contentType,omitempty"`
+	Tags        map[string]*string `json:"tags,omitempty"`
+}
+
+// fakeKeyVault is an in-memory implementation of the secrets API of Azure Key Vault.
+type fakeKeyVault struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	secrets map[string]fakeSecret
+}
+
+func newFakeKeyVault() *fakeKeyVault {
+	f := &fakeKeyVault{secrets: make(map[string]fakeSecret)}
+	f.Server = httptest.NewTLSServer(http.HandlerFunc(f.serveHTTP))
+
+	return f
+}
+
+func (f *fakeKeyVault) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer token" {
+		w.Header().Set("WWW-Authenticate", `Bearer authorization="https://login.microsoftonline.com/tenant" resource="https://vault.azure.net"`)
+		w.WriteHeader(http.StatusUnauthorized)
+
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/secrets"), "/")
+	id := f.URL + "/secrets/" + name
+
+	switch {
+	case r.Method == http.MethodGet && name == "":
+		var list struct {
+			Value []fakeSecret `json:"value"`
+		}
+
+		for name := range f.secrets {
+			list.Value = append(list.Value, fakeSecret{ID: f.URL + "/secrets/" + name})
+		}
+
+		json.NewEncoder(w).Encode(list)
+	case r.Method == http.MethodGet:
+		secret, ok := f.secrets[name]
+		if !ok {
+			http.Error(w, `{"error":{"code":"SecretNotFound"}}`, http.StatusNotFound)
+			return
+		}
+
+		json.NewEncoder(w).Encode(secret)
+	case r.Method == http.MethodPut:
+		var secret fakeSecret
+
+		err := json.NewDecoder(r.Body).Decode(&secret)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		secret.ID = id + "/1"
+		f.secrets[name] = secret
+
+		json.NewEncoder(w).Encode(secret)
+	default:
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+	}
+}
+
+func TestAzureKeyVault_roundtrip(t *testing.T) {
+	vault := newFakeKeyVault()
+	defer vault.Close()
+
+	vault.secrets["other-share-1"] = fakeSecret{Value: "1,1"}
+
+	client, err := azsecrets.NewClient(vault.URL, fakeCredential{}, &azsecrets.ClientOptions{
+		ClientOptions:                        azcore.ClientOptions{Transport: vault.Client()},
+		DisableChallengeResourceVerification: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	store := newAzureKeyVaultStore(client, "ceremony", "2026-10")
+
+	var genBuf bytes.Buffer
+
+	err = cmdGenerate(5, 3, generateOptions{sinks: []shareSink{store}}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(vault.secrets) != 6 {
+		t.Fatalf("want 6 secrets, have %d", len(vault.secrets))
+	}
+
+	for name, secret := range vault.secrets {
+		if strings.HasPrefix(name, "other-") {
+			continue
+		}
+
+		index := strings.SplitN(secret.Value, ",", 2)[0]
+		if name != "ceremony-share-"+index {
+			t.Errorf("unexpected name %q for share %q", name, secret.Value)
+		}
+
+		tags := secret.Tags
+		if tags["share-index"] == nil || *tags["share-index"] != index || tags["ceremony-id"] == nil || *tags["ceremony-id"] != "2026-10" {
+			t.Errorf("unexpected tags for %q: %v", name, tags)
+		}
+	}
+
+	in, err := readSource(store)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var (
+		errBuf bytes.Buffer
+		outBuf bytes.Buffer
+	)
+
+	err = cmdRecover(in, recoverOptions{}, &errBuf, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if errBuf.Len() != 0 {
+		t.Errorf("unexpected diagnostic: %q", errBuf.String())
+	}
+
+	secret := strings.TrimPrefix(strings.SplitN(genBuf.String(), "\n", 2)[0], "secret: ")
+	if outBuf.String() != secret+"\n" {
+		t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
+	}
+}
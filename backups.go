@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// shareLabelPattern matches the label of a share of a ceremony with backup custodians.
+var shareLabelPattern = regexp.MustCompile(`^(PRIMARY|BACKUP)-[0-9]+: `)
+
+// shareLabel returns the label of the i-th of n shares of which the last backups are held by backup custodians.
+func shareLabel(i, n, backups int) string {
+	primaries := n - backups
+	if i < primaries {
+		return fmt.Sprintf("PRIMARY-%d", i+1)
+	}
+
+	return fmt.Sprintf("BACKUP-%d", i-primaries+1)
+}
+
+// splitShareLabel splits a share line into its label and the share. The label is empty for lines without one.
+func splitShareLabel(line string) (label, share string) {
+	m := shareLabelPattern.FindString(line)
+	if m == "" {
+		return "", line
+	}
+
+	return m[:len(m)-2], line[len(m):]
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGenerate_backups(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := cmdGenerate(5, 3, generateOptions{backups: 2}, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 8 {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+
+	shares := lines[3:]
+
+	for i, want := range []string{"PRIMARY-1", "PRIMARY-2", "PRIMARY-3", "BACKUP-1", "BACKUP-2"} {
+		label, _ := splitShareLabel(shares[i])
+		if label != want {
+			t.Errorf("unexpected label for share %d. want %q, have %q", i, want, label)
+		}
+	}
+
+	secret := strings.TrimPrefix(lines[0], "secret: ")
+
+	for _, tc := range []struct {
+		name   string
+		shares []string
+	}{
+		{"primaries", shares[:3]},
+		{"primaries and backups", []string{shares[0], shares[3], shares[4]}},
+		{"backups and a primary", shares[2:]},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				errBuf bytes.Buffer
+				outBuf bytes.Buffer
+			)
+
+			err := cmdRecover(strings.NewReader(strings.Join(tc.shares, "\n")), recoverOptions{}, &errBuf, &outBuf)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if errBuf.Len() != 0 {
+				t.Errorf("unexpected diagnostic: %q", errBuf.String())
+			}
+
+			if outBuf.String() != secret+"\n" {
+				t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
+			}
+		})
+	}
+}
+
+func TestGenerate_onlyBackups(t *testing.T) {
+	err := cmdGenerate(3, 2, generateOptions{backups: 3}, &bytes.Buffer{})
+	if err == nil {
+		t.Error("expected an error without primary custodians")
+	}
+}
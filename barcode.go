@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/oned"
+)
+
+// Dimensions of the generated barcodes.
+const (
+	barcodeModuleWidth = 3   // Pixels per bar module, so that the barcodes survive printing and scanning.
+	barcodeHeight      = 150 // Pixels.
+)
+
+// barcodeSink writes each share as a Code128 barcode to the PNG file share-<index>.png in a directory. Code128 encodes
+// arbitrary ASCII, so the complete share lines fit.
+type barcodeSink struct {
+	dir string
+}
+
+// encodeBarcode returns line as a Code128 barcode image.
+func encodeBarcode(line string) (image.Image, error) {
+	writer := oned.NewCode128Writer()
+
+	// Encode once in the minimal width to learn the number of modules.
+	matrix, err := writer.Encode(line, gozxing.BarcodeFormat_CODE_128, 0, barcodeHeight, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return writer.Encode(line, gozxing.BarcodeFormat_CODE_128, matrix.GetWidth()*barcodeModuleWidth, barcodeHeight, nil)
+}
+
+func (b *barcodeSink) StoreShares(shares []storedShare) error {
+	for _, share := range shares {
+		img, err := encodeBarcode(share.line)
+		if err != nil {
+			return fmt.Errorf("encoding share %s as barcode: %w", share.index, err)
+		}
+
+		err = writePNG(filepath.Join(b.dir, "share-"+share.index+".png"), img)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// barcodeSource reads shares from the Code128 barcodes in the image files matching a glob pattern.
+type barcodeSource struct {
+	pattern string
+}
+
+// decodeBarcode returns the text of the Code128 barcode in the named PNG or JPEG file.
+func decodeBarcode(name string) (string, error) {
+	fh, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer fh.Close()
+
+	img, _, err := image.Decode(fh)
+	if err != nil {
+		return "", err
+	}
+
+	bmp, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		return "", err
+	}
+
+	hints := map[gozxing.DecodeHintType]interface{}{gozxing.DecodeHintType_TRY_HARDER: true}
+
+	result, err := oned.NewCode128Reader().Decode(bmp, hints)
+	if err != nil {
+		return "", err
+	}
+
+	return result.GetText(), nil
+}
+
+func (b barcodeSource) LoadShares() ([]string, error) {
+	names, err := filepath.Glob(b.pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%s: no images found", b.pattern)
+	}
+
+	lines := make([]string, 0, len(names))
+
+	for _, name := range names {
+		line, err := decodeBarcode(name)
+		if err != nil {
+			return nil, fmt.Errorf("reading barcode from %s: %w", name, err)
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBarcode_roundtrip(t *testing.T) {
+	dir := t.TempDir()
+
+	opts := generateOptions{
+		sinks:          []shareSink{&barcodeSink{dir: dir}},
+		ceremonyID:     "2026-10",
+		withholdShares: true,
+	}
+
+	var genBuf bytes.Buffer
+
+	err := cmdGenerate(5, 3, opts, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lines, err := barcodeSource{filepath.Join(dir, "*.png")}.LoadShares()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(lines) != 5 {
+		t.Fatalf("want 5 shares, have %d", len(lines))
+	}
+
+	var outBuf bytes.Buffer
+
+	err = cmdRecover(strings.NewReader(strings.Join(lines[:3], "\n")), recoverOptions{}, &bytes.Buffer{}, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := strings.TrimPrefix(strings.SplitN(genBuf.String(), "\n", 2)[0], "secret: ")
+	if outBuf.String() != want+"\n" {
+		t.Errorf("unexpected recovered secret. want %q, have %q", want, outBuf.String())
+	}
+}
+
+func TestBarcodeSource_noImages(t *testing.T) {
+	_, err := barcodeSource{filepath.Join(t.TempDir(), "*.png")}.LoadShares()
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
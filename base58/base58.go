@@ -0,0 +1,101 @@
+// Package base58 implements the base58 and base58check encodings used by Bitcoin.
+package base58
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var (
+	// ErrInvalidCharacter is returned when decoding a string with a character outside of the alphabet.
+	ErrInvalidCharacter = errors.New("base58: invalid character")
+
+	// ErrChecksum is returned when the checksum of a base58check string does not match.
+	ErrChecksum = errors.New("base58: checksum mismatch")
+)
+
+var radix = big.NewInt(58)
+
+// Encode encodes buf in base58. Every leading zero byte is encoded as a leading '1'.
+func Encode(buf []byte) string {
+	n := new(big.Int).SetBytes(buf)
+	mod := new(big.Int)
+
+	var out []byte
+
+	for n.Sign() > 0 {
+		n.QuoRem(n, radix, mod)
+		out = append(out, alphabet[mod.Int64()])
+	}
+
+	for _, b := range buf {
+		if b != 0 {
+			break
+		}
+
+		out = append(out, alphabet[0])
+	}
+
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+
+	return string(out)
+}
+
+// Decode decodes a base58 string.
+func Decode(s string) ([]byte, error) {
+	n := new(big.Int)
+
+	for i := 0; i < len(s); i++ {
+		d := bytes.IndexByte([]byte(alphabet), s[i])
+		if d < 0 {
+			return nil, ErrInvalidCharacter
+		}
+
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(d)))
+	}
+
+	zeros := 0
+	for zeros < len(s) && s[zeros] == alphabet[0] {
+		zeros++
+	}
+
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
+
+func checksum(buf []byte) []byte {
+	first := sha256.Sum256(buf)
+	second := sha256.Sum256(first[:])
+
+	return second[:4]
+}
+
+// CheckEncode encodes payload in base58check: payload followed by the first four bytes of its double SHA-256.
+func CheckEncode(payload []byte) string {
+	return Encode(append(append([]byte(nil), payload...), checksum(payload)...))
+}
+
+// CheckDecode decodes a base58check string and returns its payload.
+func CheckDecode(s string) ([]byte, error) {
+	buf, err := Decode(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(buf) < 4 {
+		return nil, ErrChecksum
+	}
+
+	payload, sum := buf[:len(buf)-4], buf[len(buf)-4:]
+	if !bytes.Equal(checksum(payload), sum) {
+		return nil, ErrChecksum
+	}
+
+	return payload, nil
+}
@@ -0,0 +1,73 @@
+package base58
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	for _, tc := range []struct {
+		hex  string
+		want string
+	}{
+		{"", ""},
+		{"00", "1"},
+		{"0000", "11"},
+		{"61", "2g"},
+		{"626262", "a3gV"},
+		{"636363", "aPEr"},
+		{"73696d706c792061206c6f6e6720737472696e67", "2cFupjhnEsSn59qHXstmK2ffpLv2"},
+		{"00eb15231dfceb60925886b67d065299925915aeb172c06647", "1NS17iag9jJgTHD1VXjvLCEnZuQ3rJDE9L"},
+		{"000000287fb4cd", "111233QC4"},
+	} {
+		buf, err := hex.DecodeString(tc.hex)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		have := Encode(buf)
+		if have != tc.want {
+			t.Errorf("unexpected encoding of %s. want %q, have %q", tc.hex, tc.want, have)
+		}
+
+		decoded, err := Decode(have)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if !bytes.Equal(decoded, buf) {
+			t.Errorf("unexpected decoding of %q. want %x, have %x", have, buf, decoded)
+		}
+	}
+}
+
+func TestDecode_invalid(t *testing.T) {
+	for _, s := range []string{"0", "O", "I", "l", "abc!"} {
+		_, err := Decode(s)
+		if !errors.Is(err, ErrInvalidCharacter) {
+			t.Errorf("unexpected error for %q: %v", s, err)
+		}
+	}
+}
+
+func TestCheckDecode(t *testing.T) {
+	payload, err := CheckDecode("1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if want := "0062e907b15cbf27d5425399ebf6f0fb50ebb88f18"; hex.EncodeToString(payload) != want {
+		t.Errorf("unexpected payload. want %s, have %x", want, payload)
+	}
+
+	if s := CheckEncode(payload); s != "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa" {
+		t.Errorf("unexpected encoding: %q", s)
+	}
+
+	_, err = CheckDecode("1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNb")
+	if !errors.Is(err, ErrChecksum) {
+		t.Errorf("unexpected error for a wrong checksum: %v", err)
+	}
+}
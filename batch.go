@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// cmdGenerateBatch generates count independent secrets and writes the output of cmdGenerate for each of them to out,
+// separated by "--- secret <i> ---" lines. Every block recovers on its own. The ceremony ID of every block is suffixed
+// with the number of the block, so that shares of different blocks are not mixed up.
+func cmdGenerateBatch(count, n, k int, opts generateOptions, out io.Writer) error {
+	if count == 1 {
+		return cmdGenerate(n, k, opts, out)
+	}
+
+	if count < 1 {
+		return fmt.Errorf("Invalid count %d.", count)
+	}
+
+	if opts.secret != nil {
+		return errors.New("A caller provided secret can only be split once.")
+	}
+
+	if len(opts.sinks) > 0 || opts.withholdShares || opts.secretOut != nil || opts.instructions != nil {
+		return errors.New("Generating several secrets requires the shares and the secrets to be written to the output.")
+	}
+
+	if opts.dryRun {
+		// The parameters are the same for every block.
+		return cmdGenerate(n, k, opts, out)
+	}
+
+	ceremonyID := opts.ceremonyID
+
+	for i := 1; i <= count; i++ {
+		if ceremonyID != "" {
+			opts.ceremonyID = ceremonyID + "-" + strconv.Itoa(i)
+		}
+
+		// Each block is generated before it is written, so that nothing is written for a block that fails.
+		var block bytes.Buffer
+
+		err := cmdGenerate(n, k, opts, &block)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "--- secret %d ---\n", i)
+
+		_, err = block.WriteTo(out)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestGenerateBatch(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := cmdGenerateBatch(3, 5, 3, generateOptions{ceremonyID: "2026-10"}, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	blocks := strings.Split(buf.String(), "--- secret ")
+	if len(blocks) != 4 || blocks[0] != "" {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+
+	seen := make(map[string]bool)
+
+	for i, block := range blocks[1:] {
+		header, content, _ := strings.Cut(block, "\n")
+		if header != fmt.Sprintf("%d ---", i+1) {
+			t.Errorf("unexpected separator %q", header)
+		}
+
+		lines := strings.Split(strings.TrimSpace(content), "\n")
+		secret := strings.TrimPrefix(lines[0], "secret: ")
+
+		if seen[secret] {
+			t.Errorf("secret %q generated twice", secret)
+		}
+
+		seen[secret] = true
+
+		var outBuf bytes.Buffer
+
+		err := cmdRecover(strings.NewReader(strings.Join(lines[3:6], "\n")), recoverOptions{ceremonyID: fmt.Sprintf("2026-10-%d", i+1)}, &bytes.Buffer{}, &outBuf)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if outBuf.String() != secret+"\n" {
+			t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
+		}
+	}
+}
+
+func TestGenerateBatch_errors(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		count int
+		opts  generateOptions
+	}{
+		{"zero", 0, generateOptions{}},
+		{"caller provided secret", 2, generateOptions{secret: big.NewInt(42)}},
+		{"sinks", 2, generateOptions{sinks: []shareSink{&outDirSink{dir: t.TempDir()}}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			err := cmdGenerateBatch(tc.count, 5, 3, tc.opts, &buf)
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+
+			if buf.Len() != 0 {
+				t.Errorf("unexpected output: %q", buf.String())
+			}
+		})
+	}
+}
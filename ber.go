@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/asn1"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/farhaven/secret/shamir"
+	"github.com/posener/sharedsecret"
+)
+
+// berShare is the ASN.1 structure of a share in the ber-tlv format:
+//
+//	Share ::= SEQUENCE {
+//	    index INTEGER,
+//	    value INTEGER
+//	}
+type berShare struct {
+	Index *big.Int
+	Value *big.Int
+}
+
+// marshalBERShare returns the hex encoded BER-TLV encoding of share.
+func marshalBERShare(share sharedsecret.Share) (string, error) {
+	x, y := shamir.ShareXY(share)
+
+	buf, err := asn1.Marshal(berShare{Index: x, Value: y})
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
+// isBERShare reports whether line looks like a hex encoded BER-TLV share, that is a hex encoded SEQUENCE.
+func isBERShare(line string) bool {
+	if !strings.HasPrefix(line, "30") {
+		return false
+	}
+
+	_, err := hex.DecodeString(line)
+
+	return err == nil
+}
+
+func unmarshalBERShare(line string) (sharedsecret.Share, error) {
+	buf, err := hex.DecodeString(line)
+	if err != nil {
+		return sharedsecret.Share{}, err
+	}
+
+	var s berShare
+
+	rest, err := asn1.Unmarshal(buf, &s)
+	if err != nil {
+		return sharedsecret.Share{}, err
+	}
+
+	if len(rest) != 0 {
+		return sharedsecret.Share{}, errors.New("trailing data after share")
+	}
+
+	return shamir.NewShare(s.Index, s.Value), nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestBERShare_knownEncoding(t *testing.T) {
+	share, err := parseShare("2,161872477868088873785792630750634181303")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	have, err := marshalBERShare(share)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// SEQUENCE (21 bytes) { INTEGER (1 byte) 2, INTEGER (16 bytes) 0x79c7... }
+	want := "3015020102021079c78156be022b81d0e782eb8ade02b7"
+	if have != want {
+		t.Errorf("unexpected encoding: want %s, have %s", want, have)
+	}
+
+	buf, _ := hex.DecodeString(have)
+
+	var raw asn1.RawValue
+
+	_, err = asn1.Unmarshal(buf, &raw)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if raw.Class != asn1.ClassUniversal || raw.Tag != asn1.TagSequence || !raw.IsCompound {
+		t.Errorf("unexpected outer structure: %+v", raw)
+	}
+
+	parsed, err := parseShare(have)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if parsed.String() != share.String() {
+		t.Errorf("unexpected parsed share: want %s, have %s", share, parsed)
+	}
+}
+
+func TestBERShare_invalid(t *testing.T) {
+	for _, line := range []string{"3015", "300602010102010105ff"} {
+		_, err := parseShare(line)
+		if err == nil {
+			t.Errorf("%s: expected error, got nil", line)
+		}
+	}
+}
+
+func TestRoundtrip_berTLV(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		errBuf bytes.Buffer
+		outBuf bytes.Buffer
+	)
+
+	err := cmdGenerate(5, 3, generateOptions{format: "ber-tlv"}, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	for _, line := range lines[3:] {
+		if !isBERShare(line) {
+			t.Errorf("not a BER-TLV share: %q", line)
+		}
+	}
+
+	secret := strings.TrimPrefix(lines[0], "secret: ")
+
+	err = cmdRecover(&buf, recoverOptions{}, &errBuf, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if errBuf.Len() != 0 {
+		t.Errorf("unexpected diagnostic: %q", errBuf.String())
+	}
+
+	if outBuf.String() != secret+"\n" {
+		t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
+	}
+}
+
+func TestGenerate_unknownFormat(t *testing.T) {
+	err := cmdGenerate(5, 3, generateOptions{format: "xml"}, &bytes.Buffer{})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/farhaven/secret/shamir"
+)
+
+// binaryEncoding is the value of the encoding header for secrets that are raw bytes. It is followed by the length of
+// the secret in bytes, so that leading zero bytes are restored on recovery.
+const binaryEncoding = "binary"
+
+// maxBinarySecretLen is the length of the longest binary secret that fits into the field.
+var maxBinarySecretLen = (shamir.Prime.BitLen() - 1) / 8
+
+// readBinarySecret reads a secret of raw bytes from r and returns the number that is shared along with the length of
+// the secret. The bytes are the big endian digits of the number.
+func readBinarySecret(r io.Reader) (*big.Int, int, error) {
+	raw, err := io.ReadAll(io.LimitReader(r, int64(maxBinarySecretLen)+1))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if len(raw) == 0 {
+		return nil, 0, errors.New("The secret must not be empty.")
+	}
+
+	if len(raw) > maxBinarySecretLen {
+		return nil, 0, fmt.Errorf("The secret is longer than the %d bytes the field supports.", maxBinarySecretLen)
+	}
+
+	return new(big.Int).SetBytes(raw), len(raw), nil
+}
+
+// binaryEncodingHeader returns the value of the encoding header for a binary secret of length bytes.
+func binaryEncodingHeader(length int) string {
+	return fmt.Sprintf("%s %d", binaryEncoding, length)
+}
+
+// parseBinaryEncoding returns the length of the secret noted in the value of an encoding header. ok is false if the
+// header does not describe a binary secret.
+func parseBinaryEncoding(v string) (length int, ok bool, err error) {
+	rest, ok := strings.CutPrefix(v, binaryEncoding+" ")
+	if !ok {
+		return 0, false, nil
+	}
+
+	length, err = strconv.Atoi(rest)
+	if err != nil || length < 1 || length > maxBinarySecretLen {
+		return 0, true, fmt.Errorf("Invalid binary secret length %q.", rest)
+	}
+
+	return length, true, nil
+}
+
+// binarySecretBytes returns secret as raw bytes, padded with leading zero bytes to length.
+func binarySecretBytes(secret *big.Int, length int) ([]byte, error) {
+	if (secret.BitLen()+7)/8 > length {
+		return nil, fmt.Errorf("The recovered secret is longer than %d bytes.", length)
+	}
+
+	return secret.FillBytes(make([]byte, length)), nil
+}
+
+// binarySecretHex returns an encoder that formats a binary secret of length bytes in hex, for the "secret:" line.
+func binarySecretHex(length int) func(*big.Int) (string, error) {
+	return func(secret *big.Int) (string, error) {
+		raw, err := binarySecretBytes(secret, length)
+		if err != nil {
+			return "", err
+		}
+
+		return hex.EncodeToString(raw), nil
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestBinarySecret_roundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		raw  []byte
+	}{
+		{"leading zero bytes", []byte{0, 0, 0xff, 0x10, 0}},
+		{"single zero byte", []byte{0}},
+		{"longest", bytes.Repeat([]byte{0xff}, maxBinarySecretLen)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			secret, length, err := readBinarySecret(bytes.NewReader(tc.raw))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			opts := generateOptions{
+				secret:       secret,
+				encodeSecret: binarySecretHex(length),
+				header:       []string{encodingHeader + ": " + binaryEncodingHeader(length)},
+			}
+
+			var genBuf bytes.Buffer
+
+			err = cmdGenerate(5, 3, opts, &genBuf)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			var outBuf bytes.Buffer
+
+			err = cmdRecover(strings.NewReader(genBuf.String()), recoverOptions{binary: true}, &bytes.Buffer{}, &outBuf)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !bytes.Equal(outBuf.Bytes(), tc.raw) {
+				t.Errorf("unexpected recovered secret. want %x, have %x", tc.raw, outBuf.Bytes())
+			}
+		})
+	}
+}
+
+func TestBinarySecret_errors(t *testing.T) {
+	for _, raw := range [][]byte{nil, bytes.Repeat([]byte{1}, maxBinarySecretLen+1)} {
+		_, _, err := readBinarySecret(bytes.NewReader(raw))
+		if err == nil {
+			t.Errorf("expected error for %d bytes, got nil", len(raw))
+		}
+	}
+
+	var binaryShares bytes.Buffer
+
+	err := cmdGenerate(3, 2, generateOptions{header: []string{encodingHeader + ": " + binaryEncodingHeader(4)}}, &binaryShares)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var textShares bytes.Buffer
+
+	err = cmdGenerate(3, 2, generateOptions{}, &textShares)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, tc := range []struct {
+		name  string
+		input string
+		opts  recoverOptions
+	}{
+		{"binary shares without -binary", binaryShares.String(), recoverOptions{}},
+		{"-binary without binary shares", textShares.String(), recoverOptions{binary: true}},
+		{"JSON", binaryShares.String(), recoverOptions{binary: true, format: "json"}},
+		// A random secret does not fit into 4 bytes.
+		{"too long", binaryShares.String(), recoverOptions{binary: true}},
+		{"invalid length", "secret-encoding: binary x\n1,19943338053965968504353533017903769217", recoverOptions{binary: true}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var outBuf bytes.Buffer
+
+			err := cmdRecover(strings.NewReader(tc.input), tc.opts, &bytes.Buffer{}, &outBuf)
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+
+			if outBuf.Len() != 0 {
+				t.Errorf("unexpected output: %q", outBuf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// cborShares is the output of cmdGenerate in the cbor format, a CBOR map with integer keys. The secret is left out if
+// it is written to a separate output.
+type cborShares struct {
+	Secret    string   `cbor:"1,keyasint,omitempty"`
+	Threshold int      `cbor:"2,keyasint"`
+	Shares    []string `cbor:"3,keyasint"`
+}
+
+// errNoCBOR is returned for the cbor format by builds without the cbor tag, which leave out the CBOR library.
+var errNoCBOR = errors.New("The cbor format requires a build with the cbor tag.")
+
+// isCBORMap reports whether buf starts with the initial byte of a CBOR map. Text never starts with one of these bytes,
+// since they are UTF-8 continuation bytes.
+func isCBORMap(buf []byte) bool {
+	return len(buf) > 0 && buf[0]>>5 == 5
+}
+
+// readCBORShares converts the input to the text format if it is the cbor output of cmdGenerate. Other input is
+// returned unchanged.
+func readCBORShares(in io.Reader) (io.Reader, error) {
+	buf, err := io.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isCBORMap(buf) {
+		return bytes.NewReader(buf), nil
+	}
+
+	if !cborBuild {
+		return nil, errNoCBOR
+	}
+
+	v, err := decodeCBORShares(buf)
+	if err != nil {
+		return nil, fmt.Errorf("reading CBOR shares: %w", err)
+	}
+
+	var text strings.Builder
+
+	if v.Threshold > 0 {
+		fmt.Fprintf(&text, "shares (need at least %d of these for recovery):\n", v.Threshold)
+	}
+
+	for _, share := range v.Shares {
+		text.WriteString(share + "\n")
+	}
+
+	return strings.NewReader(text.String()), nil
+}
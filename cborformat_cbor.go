@@ -0,0 +1,30 @@
+//go:build cbor
+
+package main
+
+import (
+	"io"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// cborBuild is set in builds with the cbor tag.
+const cborBuild = true
+
+// writeCBORShares writes the secret and the share lines as a single CBOR map.
+func writeCBORShares(out io.Writer, secret string, k int, shares []storedShare) error {
+	v := cborShares{Secret: secret, Threshold: k, Shares: make([]string, len(shares))}
+	for i, share := range shares {
+		v.Shares[i] = share.line
+	}
+
+	return cbor.NewEncoder(out).Encode(v)
+}
+
+func decodeCBORShares(buf []byte) (cborShares, error) {
+	var v cborShares
+
+	err := cbor.Unmarshal(buf, &v)
+
+	return v, err
+}
@@ -0,0 +1,16 @@
+//go:build !cbor
+
+package main
+
+import "io"
+
+// cborBuild is set in builds with the cbor tag.
+const cborBuild = false
+
+func writeCBORShares(out io.Writer, secret string, k int, shares []storedShare) error {
+	return errNoCBOR
+}
+
+func decodeCBORShares(buf []byte) (cborShares, error) {
+	return cborShares{}, errNoCBOR
+}
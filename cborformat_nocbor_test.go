@@ -0,0 +1,27 @@
+//go:build !cbor
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestCBORFormat_notBuilt(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := cmdGenerate(5, 3, generateOptions{format: "cbor"}, &buf)
+	if !errors.Is(err, errNoCBOR) {
+		t.Fatalf("want %v, have %v", errNoCBOR, err)
+	}
+
+	if buf.Len() != 0 {
+		t.Errorf("unexpected output: %q", buf.String())
+	}
+
+	err = cmdRecover(bytes.NewReader([]byte{0xa1, 0x02, 0x03}), recoverOptions{}, &bytes.Buffer{}, &bytes.Buffer{})
+	if !errors.Is(err, errNoCBOR) {
+		t.Fatalf("want %v, have %v", errNoCBOR, err)
+	}
+}
@@ -0,0 +1,99 @@
+//go:build cbor
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+func TestCBORFormat_roundtrip(t *testing.T) {
+	var genBuf bytes.Buffer
+
+	err := cmdGenerate(5, 3, generateOptions{format: "cbor", ceremonyID: "2026-10"}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !isCBORMap(genBuf.Bytes()) {
+		t.Fatalf("output is not a CBOR map: %x", genBuf.Bytes())
+	}
+
+	// The keys of the map are integers.
+	var raw map[int]any
+
+	err = cbor.Unmarshal(genBuf.Bytes(), &raw)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	secret, _ := raw[1].(string)
+	if secret == "" || len(raw) != 3 {
+		t.Fatalf("unexpected output %v", raw)
+	}
+
+	var generated cborShares
+
+	err = cbor.Unmarshal(genBuf.Bytes(), &generated)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if generated.Threshold != 3 || len(generated.Shares) != 5 {
+		t.Fatalf("unexpected output %+v", generated)
+	}
+
+	// Two of the shares are not enough, so the threshold must be read from the map.
+	generated.Shares = generated.Shares[:2]
+
+	var in bytes.Buffer
+
+	err = cbor.NewEncoder(&in).Encode(generated)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err = cmdRecover(bytes.NewReader(in.Bytes()), recoverOptions{}, &bytes.Buffer{}, &bytes.Buffer{})
+	if err == nil {
+		t.Fatal("expected error for too few shares, got nil")
+	}
+
+	var outBuf bytes.Buffer
+
+	err = cmdRecover(&genBuf, recoverOptions{}, &bytes.Buffer{}, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if outBuf.String() != secret+"\n" {
+		t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
+	}
+}
+
+func TestCBORFormat_errors(t *testing.T) {
+	for name, opts := range map[string]generateOptions{
+		"headers":      {format: "cbor", header: []string{"salt: x"}},
+		"withheld":     {format: "cbor", withholdShares: true},
+		"instructions": {format: "cbor", secretOut: &bytes.Buffer{}, instructions: &recoveryInstructions{}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			err := cmdGenerate(5, 3, opts, &buf)
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+
+			if buf.Len() != 0 {
+				t.Errorf("unexpected output: %q", buf.String())
+			}
+		})
+	}
+
+	err := cmdRecover(bytes.NewReader([]byte{0xa1, 0x01}), recoverOptions{}, &bytes.Buffer{}, &bytes.Buffer{})
+	if err == nil {
+		t.Fatal("expected error for truncated CBOR, got nil")
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/posener/sharedsecret"
+)
+
+// ceremonyPrefix starts a share line that carries the ID of the ceremony it was generated in:
+// "id:<ceremony-id>:<index>,<value>".
+const ceremonyPrefix = "id:"
+
+// validateCeremonyID checks that id can be embedded in a share line.
+func validateCeremonyID(id string) error {
+	if strings.ContainsAny(id, ": \t\r\n") {
+		return errors.New("Ceremony ID must not contain colons or whitespace.")
+	}
+
+	return nil
+}
+
+// withCeremonyID prefixes a share line with the ceremony ID. Share lines are returned unchanged if id is empty.
+func withCeremonyID(id, share string) string {
+	if id == "" {
+		return share
+	}
+
+	return ceremonyPrefix + id + ":" + share
+}
+
+// splitCeremonyID splits a share line into the ceremony ID and the share. The ID is empty for share lines without one.
+func splitCeremonyID(line string) (id, share string) {
+	rest, ok := strings.CutPrefix(line, ceremonyPrefix)
+	if !ok {
+		return "", line
+	}
+
+	id, share, ok = strings.Cut(rest, ":")
+	if !ok {
+		return "", line
+	}
+
+	return id, share
+}
+
+// A ceremonyShare is a share read for recovery along with the ceremony ID it carries.
+type ceremonyShare struct {
+	line  string // The share line, for diagnostics.
+	id    string
+	share sharedsecret.Share
+}
+
+// majorityCeremonyID returns the ceremony ID most of ids are equal to. It returns an error if several IDs are equally
+// common, because it is unclear which ceremony to recover the secret of.
+func majorityCeremonyID(ids []string) (string, error) {
+	count := make(map[string]int)
+	for _, id := range ids {
+		count[id]++
+	}
+
+	candidates := make([]string, 0, len(count))
+	for id := range count {
+		candidates = append(candidates, id)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if count[candidates[i]] != count[candidates[j]] {
+			return count[candidates[i]] > count[candidates[j]]
+		}
+
+		return candidates[i] < candidates[j]
+	})
+
+	if len(candidates) == 0 {
+		return "", nil
+	}
+
+	if len(candidates) > 1 && count[candidates[0]] == count[candidates[1]] {
+		return "", fmt.Errorf("As many shares belong to ceremony %q as to ceremony %q, select one with -ceremony-id.", candidates[0], candidates[1])
+	}
+
+	return candidates[0], nil
+}
+
+// selectCeremony returns the shares of the ceremony want, or of the ceremony most shares belong to if want is empty,
+// along with the ID of that ceremony. Shares of other ceremonies are reported to diag.
+func selectCeremony(want string, shares []ceremonyShare, diag io.Writer) (string, []sharedsecret.Share, error) {
+	if want == "" {
+		ids := make([]string, len(shares))
+		for i, s := range shares {
+			ids[i] = s.id
+		}
+
+		var err error
+
+		want, err = majorityCeremonyID(ids)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	var selected []sharedsecret.Share
+
+	for _, s := range shares {
+		if s.id != want {
+			fmt.Fprintf(diag, "reading share %q: ceremony ID %q does not match %q\n", s.line, s.id, want)
+			continue
+		}
+
+		selected = append(selected, s.share)
+	}
+
+	return want, selected, nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGenerate_ceremonyID(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := cmdGenerate(5, 3, generateOptions{ceremonyID: "2026-10"}, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	for _, line := range lines[3:] {
+		if !strings.HasPrefix(line, "id:2026-10:") {
+			t.Errorf("share line %q does not carry the ceremony ID", line)
+		}
+	}
+
+	var (
+		errBuf bytes.Buffer
+		outBuf bytes.Buffer
+	)
+
+	err = cmdRecover(&buf, recoverOptions{}, &errBuf, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if errBuf.Len() != 0 {
+		t.Errorf("unexpected diagnostic: %q", errBuf.String())
+	}
+
+	secret := strings.TrimPrefix(lines[0], "secret: ")
+	if outBuf.String() != secret+"\n" {
+		t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
+	}
+}
+
+func TestGenerate_invalidCeremonyID(t *testing.T) {
+	err := cmdGenerate(5, 3, generateOptions{ceremonyID: "a:b"}, &bytes.Buffer{})
+	if err == nil {
+		t.Fatal("expected an error for a ceremony ID with a colon")
+	}
+}
+
+func TestRecover_ceremonyIDMismatch(t *testing.T) {
+	in := strings.NewReader(`id:2026-10:1,19943338053965968504353533017903769217
+id:2026-10:2,161872477868088873785792630750634181303
+id:2025-04:3,42
+2,161872477868088873785792630750634181303
+id:2026-10:5,160274174127002500413544256698187925606
+`)
+
+	var (
+		errBuf bytes.Buffer
+		outBuf bytes.Buffer
+	)
+
+	err := cmdRecover(in, recoverOptions{}, &errBuf, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	wantDiag := `reading share "id:2025-04:3,42": ceremony ID "2025-04" does not match "2026-10"
+reading share "2,161872477868088873785792630750634181303": ceremony ID "" does not match "2026-10"
+`
+	if errBuf.String() != wantDiag {
+		t.Errorf("unexpected diagnostic. want %q, have %q", wantDiag, errBuf.String())
+	}
+
+	wantSecret := "7uPIBqGKMPpProBYFFR3S\n"
+	if outBuf.String() != wantSecret {
+		t.Errorf("unexpected secret. want %q, have %q", wantSecret, outBuf.String())
+	}
+}
+
+func TestRecover_ceremonyIDMajority(t *testing.T) {
+	// A stray share listed first does not decide the ceremony.
+	shares := `id:2025-04:3,42
+id:2026-10:1,19943338053965968504353533017903769217
+id:2026-10:2,161872477868088873785792630750634181303
+id:2026-10:5,160274174127002500413544256698187925606
+`
+
+	for _, tc := range []struct {
+		name       string
+		ceremonyID string
+		wantDiag   string
+		wantErr    bool
+	}{
+		{"majority", "", `reading share "id:2025-04:3,42": ceremony ID "2025-04" does not match "2026-10"` + "\n", false},
+		{"selected", "2026-10", `reading share "id:2025-04:3,42": ceremony ID "2025-04" does not match "2026-10"` + "\n", false},
+		{"selected other", "2025-04", "", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				errBuf bytes.Buffer
+				outBuf bytes.Buffer
+			)
+
+			err := cmdRecover(strings.NewReader(shares), recoverOptions{ceremonyID: tc.ceremonyID}, &errBuf, &outBuf)
+			if tc.wantErr {
+				// A single share of 2025-04 recovers garbage, but none of 2026-10 may be used.
+				if strings.Count(errBuf.String(), `does not match "2025-04"`) != 3 {
+					t.Errorf("unexpected diagnostic: %q", errBuf.String())
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if errBuf.String() != tc.wantDiag {
+				t.Errorf("unexpected diagnostic. want %q, have %q", tc.wantDiag, errBuf.String())
+			}
+
+			wantSecret := "7uPIBqGKMPpProBYFFR3S\n"
+			if outBuf.String() != wantSecret {
+				t.Errorf("unexpected secret. want %q, have %q", wantSecret, outBuf.String())
+			}
+		})
+	}
+}
+
+func TestRecover_ceremonyIDTie(t *testing.T) {
+	in := strings.NewReader(`id:2025-04:3,42
+id:2025-04:4,43
+id:2026-10:1,19943338053965968504353533017903769217
+id:2026-10:2,161872477868088873785792630750634181303
+`)
+
+	err := cmdRecover(in, recoverOptions{}, &bytes.Buffer{}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "select one with -ceremony-id") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/time/rate"
+)
+
+// Limits of the ceremony server.
+const (
+	maxCeremonyRequestSize = 1 << 20 // Bytes of a request body.
+	maxCeremonyShares      = 100     // Shares of a generated secret, which bounds the work of a request.
+	maxCeremonyClients     = 10000   // Clients that are rate limited before the limiters are reset.
+)
+
+// ceremonyRequestInterval is the interval a client has to wait between requests to the ceremony server.
+const ceremonyRequestInterval = time.Second
+
+// generateRequest is the body of a request to /generate.
+type generateRequest struct {
+	N int `json:"n"`
+	K int `json:"k"`
+}
+
+// ceremonyServer generates secrets at POST /generate and recovers them at POST /recover, for a coordinator that runs
+// the key ceremony for an organization. Each client IP may send one request per ceremonyRequestInterval.
+type ceremonyServer struct {
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+func newCeremonyServer() *ceremonyServer {
+	return &ceremonyServer{limiters: make(map[string]*rate.Limiter)}
+}
+
+// allow reports whether the client with the remote address addr may send a request now.
+func (s *ceremonyServer) allow(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.limiters[host]
+	if !ok {
+		if len(s.limiters) >= maxCeremonyClients {
+			// Forgetting the limiters lets every client send one more request, but keeps the map from growing
+			// without bounds.
+			s.limiters = make(map[string]*rate.Limiter)
+		}
+
+		l = rate.NewLimiter(rate.Every(ceremonyRequestInterval), 1)
+		s.limiters[host] = l
+	}
+
+	return l.Allow()
+}
+
+func (s *ceremonyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var handle func(body io.Reader, out io.Writer) error
+
+	switch r.URL.Path {
+	case "/generate":
+		handle = serveGenerate
+	case "/recover":
+		handle = serveRecover
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !s.allow(r.RemoteAddr) {
+		http.Error(w, "too many requests", http.StatusTooManyRequests)
+		return
+	}
+
+	var out bytes.Buffer
+
+	err := handle(http.MaxBytesReader(w, r.Body, maxCeremonyRequestSize), &out)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The responses carry secrets.
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+
+	out.WriteTo(w)
+}
+
+// serveGenerate generates a secret for the generateRequest in body and writes it along with its shares in the json
+// format of cmdGenerate to out.
+func serveGenerate(body io.Reader, out io.Writer) error {
+	var req generateRequest
+
+	dec := json.NewDecoder(body)
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(&req)
+	if err != nil {
+		return fmt.Errorf("Invalid request: %w", err)
+	}
+
+	return generateJSON(req.N, req.K, out)
+}
+
+// serveRecover recovers the secret from the JSON array of share lines in body and writes it in the json format of
+// cmdRecover to out.
+func serveRecover(body io.Reader, out io.Writer) error {
+	var shares []string
+
+	err := json.NewDecoder(body).Decode(&shares)
+	if err != nil {
+		return fmt.Errorf("Invalid request: %w", err)
+	}
+
+	return recoverJSON(shares, out)
+}
+
+// generateJSON generates a secret with n shares, k of which recover it, and writes it along with its shares in the
+// json format of cmdGenerate to out.
+func generateJSON(n, k int, out io.Writer) error {
+	if n > maxCeremonyShares {
+		return fmt.Errorf("At most %d shares can be generated.", maxCeremonyShares)
+	}
+
+	return cmdGenerate(n, k, generateOptions{format: "json", ceremonyID: uuid.NewString()}, out)
+}
+
+// recoverJSON recovers the secret from the share lines and writes it in the json format of cmdRecover to out.
+func recoverJSON(shares []string, out io.Writer) error {
+	return cmdRecover(strings.NewReader(strings.Join(shares, "\n")), recoverOptions{format: "json"}, io.Discard, out)
+}
+
+// cmdServe runs the ceremony server on addr until it fails. It uses HTTPS if certFile and keyFile are set, and warns on
+// diag otherwise.
+func cmdServe(addr, certFile, keyFile string, diag io.Writer) error {
+	if (certFile == "") != (keyFile == "") {
+		return errors.New("HTTPS requires both -tls-cert and -tls-key.")
+	}
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           newCeremonyServer(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	if certFile == "" {
+		fmt.Fprintln(diag, "serving without -tls-cert and -tls-key, secrets and shares are sent unencrypted")
+		return srv.ListenAndServe()
+	}
+
+	return srv.ListenAndServeTLS(certFile, keyFile)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCeremonyServer(t *testing.T) {
+	srv := newCeremonyServer()
+
+	post := func(remote, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.RemoteAddr = remote
+
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	rec := post("192.0.2.1:1234", "/generate", `{"n":5,"k":3}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %q", rec.Code, rec.Body.String())
+	}
+
+	if rec.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("response may be cached: %q", rec.Header().Get("Cache-Control"))
+	}
+
+	var generated jsonShares
+
+	err := json.Unmarshal(rec.Body.Bytes(), &generated)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if generated.Secret == "" || generated.Threshold != 3 || len(generated.Shares) != 5 {
+		t.Fatalf("unexpected response %+v", generated)
+	}
+
+	// The client has to wait before its next request.
+	rec = post("192.0.2.1:1235", "/generate", `{"n":5,"k":3}`)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("want status %d, have %d", http.StatusTooManyRequests, rec.Code)
+	}
+
+	body, err := json.Marshal(generated.Shares[1:4])
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	rec = post("192.0.2.2:1234", "/recover", string(body))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %q", rec.Code, rec.Body.String())
+	}
+
+	var recovered jsonSecret
+
+	err = json.Unmarshal(rec.Body.Bytes(), &recovered)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if recovered.Secret != generated.Secret {
+		t.Errorf("unexpected recovered secret. want %q, have %q", generated.Secret, recovered.Secret)
+	}
+}
+
+func TestCeremonyServer_badRequests(t *testing.T) {
+	for _, tc := range []struct {
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{http.MethodGet, "/generate", "", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/bogus", "", http.StatusNotFound},
+		{http.MethodPost, "/generate", `{"n":3,"k":5}`, http.StatusBadRequest},
+		{http.MethodPost, "/generate", `{"n":1000,"k":5}`, http.StatusBadRequest},
+		{http.MethodPost, "/generate", `{"n":3,"k":2,"secret":"x"}`, http.StatusBadRequest},
+		{http.MethodPost, "/recover", `"1,2"`, http.StatusBadRequest},
+		{http.MethodPost, "/recover", `[]`, http.StatusBadRequest},
+	} {
+		t.Run(tc.method+" "+tc.path+" "+tc.body, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+
+			rec := httptest.NewRecorder()
+			newCeremonyServer().ServeHTTP(rec, req)
+
+			if rec.Code != tc.status {
+				t.Errorf("want status %d, have %d: %q", tc.status, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
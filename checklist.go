@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+)
+
+// checklist describes the procedural requirements of a key ceremony that are confirmed before generation. The
+// interfaces, readLine and readPassphrase fields allow tests to replace the system and the operators.
+type checklist struct {
+	// operators maps the badge IDs of the operators to the key derived from their passphrase, with the badge ID as
+	// salt. Two different operators have to confirm if it is set.
+	operators map[string]string
+	airGap    bool // Require the operator to confirm that the machine is offline.
+
+	interfaces     func() ([]net.Interface, error)
+	readLine       func(prompt string) (string, error)
+	readPassphrase func(prompt string) ([]byte, error)
+}
+
+func newChecklist() checklist {
+	stdin := bufio.NewReader(os.Stdin)
+
+	return checklist{
+		interfaces: net.Interfaces,
+		readLine: func(prompt string) (string, error) {
+			fmt.Fprint(os.Stderr, prompt)
+
+			line, err := stdin.ReadString('\n')
+
+			return strings.TrimSpace(line), err
+		},
+		readPassphrase: readPassphrase,
+	}
+}
+
+// readOperators reads the operators of a ceremony from the named file. Each line holds a badge ID and the base64
+// encoded key derived from the operator's passphrase as printed by operatorKey.
+func readOperators(name string) (map[string]string, error) {
+	fh, err := openInput(name)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+
+	operators := make(map[string]string)
+
+	scanner := bufio.NewScanner(fh)
+	for scanner.Scan() {
+		t := strings.TrimSpace(scanner.Text())
+		if t == "" || strings.HasPrefix(t, "#") {
+			continue
+		}
+
+		fields := strings.Fields(t)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s: invalid operator line %q", name, t)
+		}
+
+		operators[fields[0]] = fields[1]
+	}
+
+	return operators, scanner.Err()
+}
+
+// operatorKey derives the key of an operator from their badge ID and passphrase.
+func operatorKey(badge string, passphrase []byte) string {
+	key := derivePassphraseSecret(passphrase, []byte(badge)).FillBytes(make([]byte, passphraseKeyLen))
+
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// cmdOperatorKey writes the line for the operators file of the operator with the given badge ID and passphrase to out.
+func cmdOperatorKey(badge string, passphrase []byte, out io.Writer) error {
+	if badge == "" || strings.ContainsAny(badge, " \t") {
+		return fmt.Errorf("Invalid badge ID %q.", badge)
+	}
+
+	_, err := fmt.Fprintln(out, badge, operatorKey(badge, passphrase))
+
+	return err
+}
+
+// cmdChecklist runs through the checklist c and prints the confirmed steps to out. It returns an error on the first
+// requirement that is not met.
+func cmdChecklist(c checklist, out io.Writer) error {
+	if c.airGap {
+		err := c.checkAirGap()
+		if err != nil {
+			return fmt.Errorf("Air gap verification failed: %w", err)
+		}
+
+		fmt.Fprintln(out, "✓ machine is not connected to a network")
+	}
+
+	if c.operators != nil {
+		confirmed := make(map[string]bool)
+
+		for len(confirmed) < 2 {
+			badge, err := c.confirmOperator(len(confirmed) + 1)
+			if err != nil {
+				return fmt.Errorf("Operator confirmation failed: %w", err)
+			}
+
+			if confirmed[badge] {
+				return fmt.Errorf("Operator confirmation failed: operator %s already confirmed", badge)
+			}
+
+			confirmed[badge] = true
+			fmt.Fprintf(out, "✓ operator %s confirmed\n", badge)
+		}
+	}
+
+	return nil
+}
+
+func (c checklist) checkAirGap() error {
+	ifaces, err := c.interfaces()
+	if err != nil {
+		return err
+	}
+
+	var active []string
+
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp != 0 && iface.Flags&net.FlagLoopback == 0 {
+			active = append(active, iface.Name)
+		}
+	}
+
+	if len(active) > 0 {
+		return fmt.Errorf("active network interfaces: %s", strings.Join(active, ", "))
+	}
+
+	answer, err := c.readLine("Confirm that the machine is not connected to any network (yes/no): ")
+	if err != nil {
+		return err
+	}
+
+	if answer != "yes" {
+		return errors.New("not confirmed by the operator")
+	}
+
+	return nil
+}
+
+// confirmOperator asks the i-th operator for their badge ID and passphrase and returns the badge ID.
+func (c checklist) confirmOperator(i int) (string, error) {
+	badge, err := c.readLine(fmt.Sprintf("Badge ID of operator %d: ", i))
+	if err != nil {
+		return "", err
+	}
+
+	want, ok := c.operators[badge]
+	if !ok {
+		return "", fmt.Errorf("unknown badge %q", badge)
+	}
+
+	passphrase, err := c.readPassphrase(fmt.Sprintf("Passphrase of operator %s: ", badge))
+	if err != nil {
+		return "", err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(operatorKey(badge, passphrase)), []byte(want)) != 1 {
+		return "", fmt.Errorf("wrong passphrase for badge %s", badge)
+	}
+
+	return badge, nil
+}
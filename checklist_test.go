@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"testing"
+)
+
+// scriptedChecklist returns a checklist whose operator answers come from lines and passphrases.
+func scriptedChecklist(lines []string, passphrases []string) checklist {
+	return checklist{
+		interfaces: func() ([]net.Interface, error) {
+			return []net.Interface{{Name: "lo", Flags: net.FlagUp | net.FlagLoopback}, {Name: "eth0"}}, nil
+		},
+		readLine: func(prompt string) (string, error) {
+			if len(lines) == 0 {
+				return "", errors.New("no more input")
+			}
+
+			line := lines[0]
+			lines = lines[1:]
+
+			return line, nil
+		},
+		readPassphrase: func(prompt string) ([]byte, error) {
+			if len(passphrases) == 0 {
+				return nil, errors.New("no more input")
+			}
+
+			p := passphrases[0]
+			passphrases = passphrases[1:]
+
+			return []byte(p), nil
+		},
+	}
+}
+
+func TestChecklist(t *testing.T) {
+	operators := map[string]string{
+		"B-100": operatorKey("B-100", []byte("alpha")),
+		"B-200": operatorKey("B-200", []byte("bravo")),
+	}
+
+	for _, tc := range []struct {
+		name        string
+		airGap      bool
+		active      bool
+		lines       []string
+		passphrases []string
+		wantOut     string
+		wantErr     string
+	}{
+		{
+			name:        "two operators",
+			lines:       []string{"B-100", "B-200"},
+			passphrases: []string{"alpha", "bravo"},
+			wantOut:     "✓ operator B-100 confirmed\n✓ operator B-200 confirmed\n",
+		},
+		{
+			name:        "same operator twice",
+			lines:       []string{"B-100", "B-100"},
+			passphrases: []string{"alpha", "alpha"},
+			wantErr:     "Operator confirmation failed: operator B-100 already confirmed",
+		},
+		{
+			name:        "wrong passphrase",
+			lines:       []string{"B-100", "B-200"},
+			passphrases: []string{"alpha", "alpha"},
+			wantErr:     "Operator confirmation failed: wrong passphrase for badge B-200",
+		},
+		{
+			name:    "unknown badge",
+			lines:   []string{"B-300"},
+			wantErr: `Operator confirmation failed: unknown badge "B-300"`,
+		},
+		{
+			name:        "air gap",
+			airGap:      true,
+			lines:       []string{"yes", "B-200", "B-100"},
+			passphrases: []string{"bravo", "alpha"},
+			wantOut:     "✓ machine is not connected to a network\n✓ operator B-200 confirmed\n✓ operator B-100 confirmed\n",
+		},
+		{
+			name:    "air gap not confirmed",
+			airGap:  true,
+			lines:   []string{"no"},
+			wantErr: "Air gap verification failed: not confirmed by the operator",
+		},
+		{
+			name:    "active interface",
+			airGap:  true,
+			active:  true,
+			lines:   []string{"yes"},
+			wantErr: "Air gap verification failed: active network interfaces: eth0",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := scriptedChecklist(tc.lines, tc.passphrases)
+			c.airGap = tc.airGap
+			c.operators = operators
+
+			if tc.active {
+				c.interfaces = func() ([]net.Interface, error) {
+					return []net.Interface{{Name: "lo", Flags: net.FlagUp | net.FlagLoopback}, {Name: "eth0", Flags: net.FlagUp}}, nil
+				}
+			}
+
+			var out bytes.Buffer
+
+			err := cmdChecklist(c, &out)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Errorf("unexpected error. want %q, have %v", tc.wantErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if out.String() != tc.wantOut {
+				t.Errorf("unexpected output. want %q, have %q", tc.wantOut, out.String())
+			}
+		})
+	}
+}
+
+func TestCmdOperatorKey(t *testing.T) {
+	var outBuf bytes.Buffer
+
+	err := cmdOperatorKey("B-100", []byte("alpha"), &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := "B-100 " + operatorKey("B-100", []byte("alpha")) + "\n"
+	if outBuf.String() != want {
+		t.Errorf("unexpected output. want %q, have %q", want, outBuf.String())
+	}
+
+	err = cmdOperatorKey("B 100", []byte("alpha"), &outBuf)
+	if err == nil {
+		t.Error("expected an error for a badge ID with a space")
+	}
+}
@@ -0,0 +1,1629 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/google/uuid"
+)
+
+// cliFlags holds the command line flags.
+type cliFlags struct {
+	mode                   string
+	doRecover              bool
+	minShares              int
+	numShares              int
+	secrets                string
+	ceremonyID             string
+	toRedis                bool
+	fromRedis              bool
+	redisAddr              string
+	redisTTL               time.Duration
+	preflightCheck         bool
+	requireEnv             string
+	signingKey             string
+	oldSigningKey          string
+	newSigningKey          string
+	genDockerfile          bool
+	version                bool
+	sharesDir              string
+	addr                   string
+	socketPath             string
+	recoverCheck           string
+	logFile                string
+	timeout                time.Duration
+	progress               bool
+	tlsCert                string
+	tlsKey                 string
+	healthURL              string
+	quorum                 bool
+	signaturesDir          string
+	custodianKeysDir       string
+	authorizationMaxAge    time.Duration
+	noOversample           bool
+	fromHWRNG              bool
+	rngDevice              string
+	format                 string
+	curve25519Key          bool
+	keyFile                string
+	auditLog               string
+	auditHeartbeat         time.Duration
+	spreadsheet            bool
+	secretOut              string
+	expiresAfter           time.Duration
+	toConsul               bool
+	fromConsul             bool
+	consulAddr             string
+	consulPrefix           string
+	verifyBeforeDistribute bool
+	toEtcd                 bool
+	fromEtcd               bool
+	etcdEndpoints          string
+	etcdPrefix             string
+	etcdCert               string
+	etcdKey                string
+	etcdCA                 string
+	autoShred              bool
+	shredAfterDelay        time.Duration
+	shredOnExit            bool
+	toSecretsManager       bool
+	fromSecretsManager     bool
+	secretIDPrefix         string
+	toSSM                  bool
+	fromSSM                bool
+	ssmPrefix              string
+	ssmKMSKeyID            string
+	awsRegion              string
+	assumeRoleARN          string
+	splitForGroups         bool
+	groupConfig            string
+	toGCS                  bool
+	fromGCS                bool
+	gcsBucket              string
+	gcsPrefix              string
+	minShareAge            time.Duration
+	printInstructions      bool
+	custodianNames         string
+	adminContact           string
+	toAzure                bool
+	fromAzure              bool
+	vaultURL               string
+	secretPrefix           string
+	fromPassphrase         bool
+	deriveFromPassphrase   bool
+	wrap                   bool
+	publicKeyFile          string
+	privateKeyFile         string
+	toPrinter              bool
+	printerList            string
+	toSlack                bool
+	fromSlack              bool
+	slackTokenEnv          string
+	channelMap             string
+	slackCodeBlock         bool
+	tsList                 string
+	ceremonyChecklist      bool
+	twoOperators           bool
+	operatorsFile          string
+	printOperatorKey       string
+	airGap                 bool
+	splitWIF               bool
+	anonymized             bool
+	commitmentScheme       bool
+	decoySecret            string
+	fromSMS                bool
+	withBackups            bool
+	labels                 string
+	indices                string
+	padding                int
+	shareEncoding          string
+	outDir                 string
+	qr                     bool
+	dryRun                 bool
+	thresholdInShare       bool
+	count                  int
+	verbose                bool
+	silent                 bool
+	primaryCustodians      int
+	backupCustodians       int
+	threshold              int
+	toPasswordManager      bool
+	sealEnvelope           bool
+	envelopePasswordEnv    string
+	outputFile             string
+	fromEnvelope           string
+	toSmartcard            bool
+	fromSmartcard          bool
+	cardList               string
+	cardSlots              string
+	pkcs11Module           string
+	pinEnv                 string
+	splitPGP               bool
+	passphraseEnv          string
+	splitAge               bool
+	onQuorumExec           string
+	onQuorumPost           string
+	verifyKey              string
+	hmacKey                string
+	cronExpr               string
+	toWebhook              bool
+	webhookMap             string
+	webhookKeyEnv          string
+	webhookSkipVerify      bool
+	anchorOnChain          bool
+	toQRPDF                bool
+	fromOCR                bool
+	sanitizeOutput         bool
+	toAnsibleVault         bool
+	toBarcode              bool
+	toPass                 bool
+	drillDelay             time.Duration
+	drillReportFile        string
+	secretValue            string
+	secretEnv              string
+	secretFile             string
+	binary                 bool
+	splitTOTP              bool
+	toAgeRecipients        bool
+	recipientKeysDir       string
+	lockTimeWindow         bool
+	noiseShares            int
+	noiseSeed              int64
+	embedTimeWindow        bool
+	windowStart            string
+	windowEnd              string
+	fromPass               bool
+	passDir                string
+	passPrefix             string
+	fromBarcode            bool
+	inputImages            string
+	vaultPasswordEnv       string
+	imageDir               string
+	ethRPC                 string
+	ethKeyEnv              string
+}
+
+// newCLIFlags registers the command line flags in fs. The returned flags are filled in when fs is parsed.
+func newCLIFlags(fs *flag.FlagSet) *cliFlags {
+	c := new(cliFlags)
+
+	fs.StringVar(&c.mode, "mode", "generate", "Mode of operation: generate, recover, re-sign, reshare, reindex, authorize-recovery, daemon, scheduled-refresh, recovery-wizard, recovery-drill, serve, serve-shares, socket or health-check")
+	fs.StringVar(&c.logFile, "log-file", "", "File to append the diagnostics of recover mode to, in addition to stderr")
+	fs.StringVar(&c.recoverCheck, "recover-check", "", "Fail unless the recovered secret in base 62 starts with this prefix, to confirm the right secret was recovered")
+	fs.BoolVar(&c.verbose, "verbose", false, "Report the index of every share the secret is recovered from")
+	fs.BoolVar(&c.doRecover, "recover", false, "Recover shares instead of generating. Same as -mode recover.")
+	fs.IntVar(&c.minShares, "k", 3, "Minimum number of shares required. Must be <= n.")
+	fs.IntVar(&c.numShares, "n", 5, "How many shares to generate")
+	fs.IntVar(&c.count, "count", 1, "How many independent secrets to generate, each with its own set of shares")
+	fs.StringVar(&c.secrets, "secrets", "-", "File to read secrets from. Use - to read from stdin. Several files can be given as a comma separated list or a glob pattern.")
+	fs.StringVar(&c.ceremonyID, "ceremony-id", "", "Identifier of the key ceremony the shares belong to. A random UUID is generated for new shares if empty")
+	fs.BoolVar(&c.toRedis, "shares-to-redis", false, "Store each generated share in Redis")
+	fs.BoolVar(&c.fromRedis, "shares-from-redis", false, "Read shares from Redis instead of -secrets")
+	fs.StringVar(&c.redisAddr, "redis-addr", "localhost:6379", "Address of the Redis server")
+	fs.DurationVar(&c.redisTTL, "redis-ttl", 24*time.Hour, "Expiry of shares stored in Redis")
+	fs.BoolVar(&c.preflightCheck, "pre-flight-check", false, "Verify the system prerequisites for a key ceremony and exit")
+	fs.StringVar(&c.requireEnv, "require-env", "", "Comma-separated list of environment variables the pre-flight check requires")
+	fs.StringVar(&c.signingKey, "signing-key", "", "PEM file with an Ed25519 private key to sign the generated shares with")
+	fs.StringVar(&c.oldSigningKey, "old-signing-key", "", "PEM file with the Ed25519 key the shares are currently signed with")
+	fs.StringVar(&c.newSigningKey, "new-signing-key", "", "PEM file with the Ed25519 key to re-sign the shares with")
+	fs.BoolVar(&c.version, "version", false, "Print the version of secret and exit")
+	fs.BoolVar(&c.genDockerfile, "generate-dockerfile", false, "Write a Dockerfile for a container serving the shares in -shares-dir, relative to the source directory of secret as the build context, and exit")
+	fs.StringVar(&c.sharesDir, "shares-dir", "", "Directory containing share-<index>.txt files. Recover mode reads the shares from every *.txt file in it.")
+	fs.StringVar(&c.socketPath, "socket-path", "", "Path of the Unix domain socket to listen on in socket mode")
+	fs.StringVar(&c.addr, "addr", "", "Address to listen on in serve and serve-shares mode, :8080 for serve and :8443 for serve-shares if empty")
+	fs.StringVar(&c.tlsCert, "tls-cert", "", "TLS certificate file for serve and serve-shares mode")
+	fs.StringVar(&c.tlsKey, "tls-key", "", "TLS key file for serve and serve-shares mode")
+	fs.StringVar(&c.healthURL, "health-url", "https://localhost:8443/healthz", "URL probed in health-check mode")
+	fs.BoolVar(&c.quorum, "verify-quorum", false, "Require recovery authorizations from as many custodians as the threshold of the shares before recovering")
+	fs.StringVar(&c.signaturesDir, "signatures-dir", "", "Directory containing the <custodian>.sig recovery authorizations")
+	fs.StringVar(&c.custodianKeysDir, "custodian-keys-dir", "", "Directory containing the <custodian>.pub public keys")
+	fs.DurationVar(&c.authorizationMaxAge, "authorization-max-age", 24*time.Hour, "Maximum age of the recovery authorizations for -verify-quorum")
+	fs.BoolVar(&c.noOversample, "no-oversample", false, "Generate exactly n shares instead of selecting them from a larger pool. Faster, but reveals the number of shares.")
+	fs.BoolVar(&c.splitTOTP, "split-totp-seed", false, "Split the base32 TOTP seed read from -key-file, and recover it in base32 with its original padding")
+	fs.BoolVar(&c.binary, "binary", false, "Split raw bytes read from stdin instead of generating a secret, or write the recovered secret as raw bytes")
+	fs.StringVar(&c.secretValue, "secret", "", "Split this secret, like a password or a recovery phrase, instead of generating one. The secret is visible in the process list, prefer -secret-env or -secret-file.")
+	fs.StringVar(&c.secretEnv, "secret-env", "", "Environment variable holding the secret to split instead of generating one. This is the preferred way to provide a secret.")
+	fs.StringVar(&c.secretFile, "secret-file", "", "File whose first line is the secret to split instead of generating one")
+	fs.BoolVar(&c.fromHWRNG, "secret-from-hardware-rng", false, "Read the secret from a hardware random number generator instead of generating it")
+	fs.StringVar(&c.rngDevice, "rng-device", "/dev/hwrng", "Hardware random number generator device")
+	fs.StringVar(&c.format, "format", "text", "Encoding of the generated shares: text, ber-tlv, pem, csv, json, cbor (in builds with the cbor tag) or yaml, or 1password or bitwarden with -shares-to-password-manager-csv. Recovery writes the secret as json if it is json.")
+	fs.BoolVar(&c.curve25519Key, "split-curve25519-key", false, "Split the curve25519 private key in -key-file, or recover a curve25519 key")
+	fs.StringVar(&c.keyFile, "key-file", "-", "File to read the key to split from. Use - to read from stdin.")
+	fs.StringVar(&c.auditLog, "audit-log", "", "File to append the audit log of the daemon and the scheduled refresh to. Defaults to stderr.")
+	fs.DurationVar(&c.auditHeartbeat, "audit-heartbeat", 0, "Interval of heartbeat entries in the daemon audit log. Zero disables heartbeats.")
+	fs.BoolVar(&c.spreadsheet, "shares-output-as-csv-with-headers", false, "Write the shares as a CSV file with headers for spreadsheets. Requires -secret-out.")
+	fs.StringVar(&c.secretOut, "secret-out", "", "File to write the secret to instead of stdout")
+	fs.DurationVar(&c.expiresAfter, "expires-after", 0, "Lifetime of the shares noted in the CSV output. Zero means no expiry.")
+	fs.BoolVar(&c.toConsul, "shares-to-consul", false, "Store each generated share in the Consul KV store")
+	fs.BoolVar(&c.fromConsul, "shares-from-consul", false, "Read shares from the Consul KV store instead of -secrets")
+	fs.StringVar(&c.consulAddr, "consul-addr", "http://127.0.0.1:8500", "Address of the Consul agent. The ACL token is read from CONSUL_HTTP_TOKEN.")
+	fs.StringVar(&c.consulPrefix, "consul-prefix", "secret", "Key prefix of the shares in the Consul KV store")
+	fs.BoolVar(&c.verifyBeforeDistribute, "verify-before-distribute", false, "Test the recovery from a random subset of k shares before writing any share")
+	fs.BoolVar(&c.toEtcd, "shares-to-etcd", false, "Store each generated share in etcd")
+	fs.BoolVar(&c.fromEtcd, "shares-from-etcd", false, "Read shares from etcd instead of -secrets")
+	fs.StringVar(&c.etcdEndpoints, "etcd-endpoints", "http://127.0.0.1:2379", "Comma-separated list of etcd endpoints")
+	fs.StringVar(&c.etcdPrefix, "etcd-prefix", "/secret", "Key prefix of the shares in etcd")
+	fs.StringVar(&c.etcdCert, "etcd-cert", "", "TLS client certificate for etcd")
+	fs.StringVar(&c.etcdKey, "etcd-key", "", "TLS client key for etcd")
+	fs.StringVar(&c.etcdCA, "etcd-ca", "", "CA certificate to verify the etcd servers with")
+	fs.BoolVar(&c.autoShred, "auto-shred", false, "Shred the -secret-out file once -shred-after has passed")
+	fs.DurationVar(&c.shredAfterDelay, "shred-after", 10*time.Minute, "How long to keep the -secret-out file with -auto-shred")
+	fs.BoolVar(&c.shredOnExit, "shred-on-exit", false, "Shred the -secret-out file when the process exits or is interrupted")
+	fs.BoolVar(&c.toSecretsManager, "shares-to-aws-secrets-manager", false, "Store each generated share in AWS Secrets Manager")
+	fs.BoolVar(&c.fromSecretsManager, "shares-from-aws-secrets-manager", false, "Read shares from AWS Secrets Manager instead of -secrets")
+	fs.StringVar(&c.secretIDPrefix, "secret-id-prefix", "secret", "Name prefix of the shares in AWS Secrets Manager")
+	fs.BoolVar(&c.toSSM, "shares-to-ssm", false, "Store each generated share as a SecureString parameter <-ssm-prefix>/share/<index> in AWS Systems Manager Parameter Store")
+	fs.BoolVar(&c.fromSSM, "shares-from-ssm", false, "Read shares from AWS Systems Manager Parameter Store instead of -secrets")
+	fs.StringVar(&c.ssmPrefix, "ssm-prefix", "/secret", "Parameter path of the shares in Parameter Store, like /ceremonies/<id>")
+	fs.StringVar(&c.ssmKMSKeyID, "ssm-kms-key-id", "", "KMS key to encrypt the shares in Parameter Store with instead of the AWS managed key")
+	fs.StringVar(&c.awsRegion, "aws-region", "", "AWS region. Defaults to the configured region.")
+	fs.StringVar(&c.assumeRoleARN, "assume-role-arn", "", "ARN of an IAM role to assume for accessing AWS")
+	fs.BoolVar(&c.splitForGroups, "split-for-groups", false, "Split the secret for hierarchical groups of custodians given by -group-config")
+	fs.StringVar(&c.groupConfig, "group-config", "", `JSON list of groups, e.g. [{"name":"exec","k":2,"n":3},{"name":"board","k":3,"n":5}]`)
+	fs.BoolVar(&c.toGCS, "shares-to-gcs", false, "Upload each generated share to Google Cloud Storage")
+	fs.BoolVar(&c.fromGCS, "shares-from-gcs", false, "Read shares from Google Cloud Storage instead of -secrets")
+	fs.StringVar(&c.gcsBucket, "gcs-bucket", "", "Google Cloud Storage bucket for the shares")
+	fs.StringVar(&c.gcsPrefix, "gcs-prefix", "", "Object name prefix of the shares in Google Cloud Storage")
+	fs.DurationVar(&c.minShareAge, "minimum-share-age", 0, "Minimum time between the submissions of two shares in daemon mode")
+	fs.BoolVar(&c.printInstructions, "print-recovery-instructions", false, "Write plain-English recovery instructions for each custodian instead of the share lines. Requires -secret-out and -recovery-admin-contact.")
+	fs.StringVar(&c.custodianNames, "custodian-name", "", "Comma separated names of the custodians for the recovery instructions, in the order of the shares")
+	fs.StringVar(&c.adminContact, "recovery-admin-contact", "", "Contact of the ceremony administrator for the recovery instructions")
+	fs.BoolVar(&c.toAzure, "shares-to-azure-key-vault", false, "Store each generated share in Azure Key Vault")
+	fs.BoolVar(&c.fromAzure, "shares-from-azure-key-vault", false, "Read shares from Azure Key Vault instead of -secrets")
+	fs.StringVar(&c.vaultURL, "vault-url", "", "URL of the Azure Key Vault for the shares")
+	fs.StringVar(&c.secretPrefix, "secret-prefix", "secret", "Name prefix of the shares in Azure Key Vault")
+	fs.BoolVar(&c.fromPassphrase, "split-secret-interactive-passphrase", false, "Generate a secret and mask it with a key derived from a passphrase typed on the terminal, so that the shares alone do not reveal it")
+	fs.BoolVar(&c.deriveFromPassphrase, "derive-from-passphrase", false, "Read the passphrase the secret was masked with from the terminal for recovery")
+	fs.BoolVar(&c.wrap, "wrap-secret", false, "Encrypt a random secret to -public-key-file and split the encrypted secret")
+	fs.StringVar(&c.publicKeyFile, "public-key-file", "", "File with the X25519 public key of the ceremony administrator for -wrap-secret")
+	fs.StringVar(&c.privateKeyFile, "private-key-file", "", "File with the X25519 private key for recovering a wrapped secret")
+	fs.BoolVar(&c.toPrinter, "shares-to-physical-printer", false, "Print each generated share on a different printer with lp")
+	fs.StringVar(&c.printerList, "printer-list", "", `JSON list of printers for the shares, like [{"printer":"lab-1","custodian":"Alice"}]`)
+	fs.BoolVar(&c.toSlack, "shares-to-slack", false, "Post each generated share to its own Slack channel or DM")
+	fs.BoolVar(&c.fromSlack, "shares-from-slack", false, "Read shares from Slack messages given by -ts-list instead of -secrets")
+	fs.StringVar(&c.slackTokenEnv, "slack-token-env", "SLACK_TOKEN", "Environment variable holding the Slack bot token")
+	fs.StringVar(&c.channelMap, "channel-map", "{}", `JSON map of share positions to Slack channel IDs, like {"1": "C12345", "2": "D67890"}`)
+	fs.BoolVar(&c.slackCodeBlock, "slack-encrypt-in-transit", false, "Wrap the Slack messages in a code block. This only affects formatting, the messages are not encrypted.")
+	fs.StringVar(&c.tsList, "ts-list", "", "Comma separated timestamps of the Slack messages with the shares, either <ts> or <channel>:<ts>")
+	fs.BoolVar(&c.ceremonyChecklist, "key-ceremony-checklist", false, "Confirm the procedural requirements of the key ceremony before generation")
+	fs.BoolVar(&c.twoOperators, "require-two-operators", false, "Require two operators from -operators-file to confirm the checklist")
+	fs.StringVar(&c.operatorsFile, "operators-file", "", "File with one '<badge-id> <key>' line per operator. Print the key for a badge with -operator-key.")
+	fs.StringVar(&c.printOperatorKey, "operator-key", "", "Print the operator key for the given badge ID and a passphrase typed on the terminal, and exit")
+	fs.BoolVar(&c.airGap, "require-air-gap-verification", false, "Require the machine to be offline during the key ceremony checklist")
+	fs.BoolVar(&c.splitWIF, "split-bitcoin-wif", false, "Split the Bitcoin WIF private key from -key-file. Recovery restores the WIF encoding.")
+	fs.BoolVar(&c.anonymized, "generate-k-anonymized-shares", false, "Generate a second set of shares for -decoy-secret that custodians reveal under duress")
+	fs.BoolVar(&c.commitmentScheme, "split-secret-with-commitment-scheme", false, "Generate Pedersen verifiable shares along with commitments that every share can be checked against, or recover from them")
+	fs.StringVar(&c.decoySecret, "decoy-secret", "", "Base 62 encoded decoy secret for -generate-k-anonymized-shares")
+	fs.BoolVar(&c.fromSMS, "shares-from-sms", false, "Read shares from SMS messages in -secrets, ignoring gateway headers and autocorrect substitutions")
+	fs.BoolVar(&c.withBackups, "generate-for-m-custodians-with-backups", false, "Generate shares for -primary-custodians and -backup-custodians, -threshold of which recover the secret")
+	fs.BoolVar(&c.silent, "silent", false, "Write only the share lines, without headers. Requires -secret-out, which receives the secret.")
+	fs.BoolVar(&c.thresholdInShare, "threshold-in-share", false, "Append the number of shares needed for recovery to every share as index,value,k")
+	fs.StringVar(&c.outDir, "outdir", "", "Write each share to share-<index>.txt in this directory instead of stdout. The directory is created if it does not exist.")
+	fs.BoolVar(&c.progress, "progress", false, "Write a dot to stderr every second while the shares are generated")
+	fs.DurationVar(&c.timeout, "timeout", generateTimeout, "Time generating the shares may take before generate mode gives up")
+	fs.BoolVar(&c.dryRun, "dry-run", false, "Only check the parameters of generate mode and print them, without generating a secret or shares")
+	fs.BoolVar(&c.qr, "qr", false, "Also write each share as a QR code to share-<index>.png in -outdir, or in the current directory")
+	fs.StringVar(&c.shareEncoding, "encoding", decimalEncoding, "Encoding of the share values: decimal, base58, base64, hex or words for BIP-39 words. Recovery detects the encoding.")
+	fs.IntVar(&c.padding, "padding", 0, "Left-pad the share values with zeros to this width, so that all values are equally wide. Word shares are padded to this number of words.")
+	fs.StringVar(&c.indices, "indices", "", "Comma separated indices of the -n shares, for example 1,7 to give share 1 and share 7 to known custodians. The shares are random if empty.")
+	fs.StringVar(&c.labels, "labels", "", "Comma separated names of the custodians, one for each of the -n shares. The name is appended to the share as index,value,label.")
+	fs.IntVar(&c.primaryCustodians, "primary-custodians", 0, "Number of primary custodians")
+	fs.IntVar(&c.backupCustodians, "backup-custodians", 0, "Number of backup custodians whose shares are held in escrow")
+	fs.IntVar(&c.threshold, "threshold", 0, "Number of shares from primary or backup custodians required for recovery. Defaults to -k.")
+	fs.BoolVar(&c.toPasswordManager, "shares-to-password-manager-csv", false, "Write the shares as a CSV file for importing into the password manager given by -format. Requires -secret-out.")
+	fs.BoolVar(&c.sealEnvelope, "generate-tamper-evident-envelope", false, "Seal the shares and the secret in an encrypted ZIP file at -output and split its password")
+	fs.StringVar(&c.envelopePasswordEnv, "envelope-password-env", "ENVELOPE_PASSWORD", "Environment variable holding the password of the envelope")
+	fs.StringVar(&c.outputFile, "output", "", "File the envelope or the PDF of -shares-to-print-qr-pdf is written to. It must not exist.")
+	fs.StringVar(&c.fromEnvelope, "from-envelope", "", "Open the envelope at the given path with the passwords read from -secrets")
+	fs.BoolVar(&c.toSmartcard, "shares-to-smartcard", false, "Store each generated share on a different smart card given by -card-list")
+	fs.BoolVar(&c.fromSmartcard, "shares-from-smartcard", false, "Read shares from the smart cards in -card-slot instead of -secrets")
+	fs.StringVar(&c.cardList, "card-list", "", `JSON list of smart cards for the shares, like [{"slot":0,"custodian":"Alice"}]`)
+	fs.StringVar(&c.cardSlots, "card-slot", "0", "Comma separated PKCS#11 slots of the smart cards to read shares from")
+	fs.StringVar(&c.pkcs11Module, "pkcs11-module", "/usr/lib/opensc-pkcs11.so", "Path of the PKCS#11 module for smart cards")
+	fs.StringVar(&c.pinEnv, "pkcs11-pin-env", "PKCS11_PIN", "Environment variable holding the user PIN of the smart cards")
+	fs.BoolVar(&c.splitPGP, "split-pgp-symmetric", false, "Encrypt each share with PGP symmetric encryption using the passphrase from -passphrase-env, and decrypt them for recovery")
+	fs.StringVar(&c.passphraseEnv, "passphrase-env", "SECRET_PASSPHRASE", "Environment variable holding the passphrase for encrypting the shares. It is not taken from a flag to keep it out of the shell history.")
+	fs.BoolVar(&c.splitAge, "split-age-passphrase", false, "Encrypt each share with age using the passphrase from -passphrase-env, and decrypt them for recovery")
+	fs.StringVar(&c.onQuorumExec, "on-quorum-exec", "", "Script to run with the recovered secret on stdin once the daemon reached a quorum. A non-zero exit status keeps the daemon watching for more shares.")
+	fs.StringVar(&c.onQuorumPost, "on-quorum-http-post", "", "URL to post the recovered secret to once the daemon reached a quorum. A non-2xx response keeps the daemon watching for more shares.")
+	fs.BoolVar(&c.toWebhook, "shares-to-webhook", false, "POST each generated share as JSON to its own webhook URL")
+	fs.StringVar(&c.webhookMap, "webhook-map", "", `JSON map of share positions to webhook URLs, like {"1":"https://a.example/share"}`)
+	fs.StringVar(&c.webhookKeyEnv, "webhook-key-env", "SECRET_WEBHOOK_KEY", "Environment variable holding the HMAC-SHA256 key the webhook requests are signed with")
+	fs.BoolVar(&c.webhookSkipVerify, "webhook-skip-tls-verify", false, "Do not verify the TLS certificates of the webhooks. Only use this for development.")
+	fs.BoolVar(&c.toQRPDF, "shares-to-print-qr-pdf", false, "Write a PDF to -output with one page per share showing it as a QR code, instead of writing the shares to stdout")
+	fs.BoolVar(&c.toBarcode, "shares-to-barcode", false, "Write each share as a Code128 barcode to share-<index>.png in -shares-dir instead of stdout")
+	fs.BoolVar(&c.fromBarcode, "shares-from-barcode", false, "Read shares from the Code128 barcodes in the images matching -input-images instead of -secrets")
+	fs.StringVar(&c.inputImages, "input-images", "", "Glob pattern of the PNG or JPEG images for -shares-from-barcode, like 'scans/*.png'")
+	fs.IntVar(&c.noiseShares, "add-noise-shares", 0, "Mix this many fake shares that are not on the sharing polynomial in with the real shares")
+	fs.Int64Var(&c.noiseSeed, "noise-seed", 0, "Seed for -add-noise-shares, so that the same fake shares are generated. Zero means a random seed.")
+	fs.BoolVar(&c.lockTimeWindow, "lock-to-time-window", false, "Refuse to recover the secret outside of the time window from -window-start to -window-end")
+	fs.BoolVar(&c.embedTimeWindow, "embed-time-window", false, "Write the time window from -window-start to -window-end into the output, so that recovery is refused outside of it")
+	fs.StringVar(&c.windowStart, "window-start", "", "Start of the time window in RFC 3339 format, like 2024-01-01T10:00:00Z")
+	fs.StringVar(&c.windowEnd, "window-end", "", "End of the time window in RFC 3339 format, like 2024-01-01T12:00:00Z")
+	fs.BoolVar(&c.toAgeRecipients, "shares-to-age-file-per-recipient", false, "Encrypt each share to the age public key of its custodian and write it to <label>.age in -shares-dir instead of stdout")
+	fs.StringVar(&c.recipientKeysDir, "recipient-keys-dir", "", "Directory with the age public keys <label>.pub of the custodians for -shares-to-age-file-per-recipient")
+	fs.DurationVar(&c.drillDelay, "drill-read-delay", 0, "Time each simulated custodian takes to read their share in -mode recovery-drill")
+	fs.StringVar(&c.drillReportFile, "drill-report-file", "", "File to write the JSON report of -mode recovery-drill to")
+	fs.BoolVar(&c.toPass, "shares-to-pass", false, "Insert each share into the pass password manager as <-pass-prefix>/share-<index>")
+	fs.BoolVar(&c.fromPass, "shares-from-pass", false, "Read the shares below -pass-prefix from the pass password manager instead of -secrets")
+	fs.StringVar(&c.passDir, "pass-dir", "", "Password store of pass. Defaults to $PASSWORD_STORE_DIR or ~/.password-store")
+	fs.StringVar(&c.passPrefix, "pass-prefix", "", "Folder of the share entries in the password store, like ceremonies/2024")
+	fs.BoolVar(&c.toAnsibleVault, "generate-ansible-vault-compatible", false, "Write each share to an Ansible Vault encrypted YAML file share-<index>.yml in -shares-dir instead of stdout")
+	fs.StringVar(&c.vaultPasswordEnv, "vault-password-env", "ANSIBLE_VAULT_PASSWORD", "Environment variable holding the Ansible Vault password")
+	fs.BoolVar(&c.sanitizeOutput, "sanitize-output", false, "Escape the shell metacharacters $ ` ! ; & | < > and backslashes in stdout and stderr with a backslash")
+	fs.BoolVar(&c.fromOCR, "shares-from-ocr", false, "Read shares from scanned paper share documents in -image-dir with tesseract instead of -secrets")
+	fs.StringVar(&c.imageDir, "image-dir", "", "Directory with PNG or JPEG scans of share documents for -shares-from-ocr")
+	fs.BoolVar(&c.anchorOnChain, "audit-trail-blockchain", false, "Anchor the hash of the ceremony parameters and the shares in an Ethereum transaction after generation")
+	fs.StringVar(&c.ethRPC, "eth-rpc", "http://127.0.0.1:8545", "URL of the Ethereum JSON-RPC endpoint for -audit-trail-blockchain")
+	fs.StringVar(&c.ethKeyEnv, "eth-private-key-env", "ETH_PRIVATE_KEY", "Environment variable holding the hex encoded private key of the account that sends the anchoring transaction")
+	fs.StringVar(&c.cronExpr, "cron", "", "Cron expression for -mode scheduled-refresh, like \"0 3 * * 0\" or @weekly")
+	fs.StringVar(&c.hmacKey, "hmac-key", "", "Key of the HMAC tag appended to each generated share, or that the shares must carry for recovery. Defaults to $"+hmacKeyEnv+".")
+	fs.StringVar(&c.verifyKey, "verify-key", "", "PEM file with the Ed25519 public key the shares must be signed with for recovery. Shares without a valid signature are ignored.")
+
+	return c
+}
+
+// usageError is an error caused by invalid flags. The usage is printed along with it.
+type usageError struct {
+	error
+}
+
+func (e usageError) Unwrap() error {
+	return e.error
+}
+
+// exclusive returns an error if more than one of the named flags is set.
+func exclusive(flags map[string]bool) error {
+	var set []string
+
+	for name, ok := range flags {
+		if ok {
+			set = append(set, "-"+name)
+		}
+	}
+
+	if len(set) > 1 {
+		sort.Strings(set)
+		return usageError{fmt.Errorf("%s are mutually exclusive.", strings.Join(set, " and "))}
+	}
+
+	return nil
+}
+
+// unsupported returns an error naming the set flags if there are any. mode is the flag of the mode that does not
+// support them.
+func unsupported(mode string, flags map[string]bool) error {
+	var set []string
+
+	for name, ok := range flags {
+		if ok {
+			set = append(set, "-"+name)
+		}
+	}
+
+	if len(set) == 0 {
+		return nil
+	}
+
+	sort.Strings(set)
+
+	return usageError{fmt.Errorf("-%s does not support %s.", mode, strings.Join(set, ", "))}
+}
+
+// validate returns an error if conflicting flags are set.
+func (c *cliFlags) validate() error {
+	for _, flags := range []map[string]bool{
+		// Sources of the secret to split.
+		{
+			"split-curve25519-key":                c.curve25519Key && c.mode == "generate",
+			"split-bitcoin-wif":                   c.splitWIF,
+			"secret-from-hardware-rng":            c.fromHWRNG,
+			"split-secret-interactive-passphrase": c.fromPassphrase,
+			"wrap-secret":                         c.wrap,
+			"secret":                              c.secretValue != "",
+			"secret-env":                          c.secretEnv != "",
+			"secret-file":                         c.secretFile != "",
+			"split-totp-seed":                     c.splitTOTP,
+			"binary":                              c.binary && c.mode == "generate",
+		},
+		// Sources of the shares to recover from.
+		{
+			"shares-from-redis":               c.fromRedis,
+			"shares-from-consul":              c.fromConsul,
+			"shares-from-aws-secrets-manager": c.fromSecretsManager,
+			"shares-from-ssm":                 c.fromSSM,
+			"shares-from-gcs":                 c.fromGCS,
+			"shares-from-slack":               c.fromSlack,
+			"shares-from-azure-key-vault":     c.fromAzure,
+			"shares-from-etcd":                c.fromEtcd,
+			"shares-from-smartcard":           c.fromSmartcard,
+			"shares-from-sms":                 c.fromSMS,
+			"shares-from-ocr":                 c.fromOCR,
+			"shares-from-barcode":             c.fromBarcode,
+			"shares-dir":                      c.sharesDir != "" && c.mode == "recover",
+			"shares-from-pass":                c.fromPass,
+		},
+		// Encryptions of the shares.
+		{
+			"split-pgp-symmetric":  c.splitPGP,
+			"split-age-passphrase": c.splitAge,
+		},
+		// Writers of share-<index>.png files.
+		{
+			"qr":                c.qr,
+			"shares-to-barcode": c.toBarcode,
+		},
+		{
+			"on-quorum-exec":      c.onQuorumExec != "",
+			"on-quorum-http-post": c.onQuorumPost != "",
+		},
+	} {
+		err := exclusive(flags)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// outputDir returns the directory the outputs of the ceremony are written to: the directory of -secret-out, or
+// -shares-dir, or the current directory.
+func (c *cliFlags) outputDir() string {
+	switch {
+	case c.secretOut != "":
+		return filepath.Dir(c.secretOut)
+	case c.sharesDir != "":
+		return c.sharesDir
+	default:
+		return "."
+	}
+}
+
+// closers collects the resources opened for a command, to close them when the command is done.
+type closers []func()
+
+func (cs *closers) add(f func()) {
+	*cs = append(*cs, f)
+}
+
+// close closes all resources in the reverse order they were added.
+func (cs closers) close() {
+	for i := len(cs) - 1; i >= 0; i-- {
+		cs[i]()
+	}
+}
+
+// run runs the command selected by c.
+func run(c *cliFlags) error {
+	if c.version {
+		return cmdVersion(os.Stdout)
+	}
+
+	if c.doRecover {
+		c.mode = "recover"
+	}
+
+	if c.withBackups {
+		c.numShares = c.primaryCustodians + c.backupCustodians
+
+		if c.threshold > 0 {
+			c.minShares = c.threshold
+		}
+	}
+
+	err := c.validate()
+	if err != nil {
+		return err
+	}
+
+	if c.preflightCheck {
+		p := newPreflight(c.numShares, c.minShares, c.outputDir())
+		if c.requireEnv != "" {
+			p.env = strings.Split(c.requireEnv, ",")
+		}
+
+		return cmdPreflight(p, os.Stdout)
+	}
+
+	if c.printOperatorKey != "" {
+		passphrase, err := readNewPassphrase()
+		if err != nil {
+			return err
+		}
+
+		return cmdOperatorKey(c.printOperatorKey, passphrase, os.Stdout)
+	}
+
+	if c.genDockerfile {
+		return cmdGenerateDockerfile(c.sharesDir, os.Stdout)
+	}
+
+	var groups []shareGroup
+
+	if c.splitForGroups {
+		groups, err = parseGroupConfig(c.groupConfig)
+		if err != nil {
+			return usageError{err}
+		}
+	}
+
+	switch c.mode {
+	case "generate":
+		return runGenerate(c, groups)
+	case "recover":
+		return runRecover(c, groups)
+	case "re-sign":
+		return runResign(c)
+	case "reshare":
+		return runReshare(c)
+	case "reindex":
+		return runReindex(c)
+	case "authorize-recovery":
+		key, err := loadSigningKey(c.signingKey)
+		if err != nil {
+			return usageError{err}
+		}
+
+		err = cmdAuthorizeRecovery(key, c.ceremonyID, time.Now(), os.Stdout)
+		if err != nil {
+			return usageError{err}
+		}
+
+		return nil
+	case "daemon":
+		return runDaemon(c)
+	case "scheduled-refresh":
+		return runScheduledRefresh(c)
+	case "recovery-wizard":
+		// The escape sequences of the terminal UI contain semicolons.
+		err := unsupported("mode recovery-wizard", map[string]bool{"sanitize-output": c.sanitizeOutput})
+		if err != nil {
+			return err
+		}
+
+		return cmdRecoveryWizard(c.minShares)
+	case "recovery-drill":
+		if c.sharesDir == "" {
+			return usageError{errors.New("A recovery drill requires -shares-dir.")}
+		}
+
+		return cmdRecoveryDrill(newRecoveryDrill(c.sharesDir, c.minShares, c.drillDelay), c.drillReportFile, os.Stdout)
+	case "serve":
+		addr := c.addr
+		if addr == "" {
+			addr = ":8080"
+		}
+
+		return cmdServe(addr, c.tlsCert, c.tlsKey, os.Stderr)
+	case "serve-shares":
+		addr := c.addr
+		if addr == "" {
+			addr = ":8443"
+		}
+
+		return cmdServeShares(c.sharesDir, addr, c.tlsCert, c.tlsKey)
+	case "socket":
+		return cmdServeSocket(c.socketPath)
+	case "health-check":
+		return cmdHealthCheck(c.healthURL)
+	default:
+		return usageError{fmt.Errorf("Unknown mode %q.", c.mode)}
+	}
+}
+
+func runGenerate(c *cliFlags, groups []shareGroup) error {
+	if c.ceremonyID == "" {
+		c.ceremonyID = uuid.NewString()
+	}
+
+	if groups != nil {
+		err := unsupported("split-for-groups", map[string]bool{
+			"split-curve25519-key":                   c.curve25519Key,
+			"split-bitcoin-wif":                      c.splitWIF,
+			"secret-from-hardware-rng":               c.fromHWRNG,
+			"secret":                                 c.secretValue != "",
+			"secret-env":                             c.secretEnv != "",
+			"secret-file":                            c.secretFile != "",
+			"split-totp-seed":                        c.splitTOTP,
+			"binary":                                 c.binary,
+			"split-secret-interactive-passphrase":    c.fromPassphrase,
+			"wrap-secret":                            c.wrap,
+			"signing-key":                            c.signingKey != "",
+			"secret-out":                             c.secretOut != "",
+			"auto-shred":                             c.autoShred,
+			"shred-on-exit":                          c.shredOnExit,
+			"format":                                 c.format != "text",
+			"shares-output-as-csv-with-headers":      c.spreadsheet,
+			"shares-to-password-manager-csv":         c.toPasswordManager,
+			"print-recovery-instructions":            c.printInstructions,
+			"generate-for-m-custodians-with-backups": c.withBackups,
+			"verify-before-distribute":               c.verifyBeforeDistribute,
+			"split-pgp-symmetric":                    c.splitPGP,
+			"split-age-passphrase":                   c.splitAge,
+			"key-ceremony-checklist":                 c.ceremonyChecklist,
+			"generate-k-anonymized-shares":           c.anonymized,
+			"split-secret-with-commitment-scheme":    c.commitmentScheme,
+			"generate-tamper-evident-envelope":       c.sealEnvelope,
+			"shares-to-redis":                        c.toRedis,
+			"shares-to-consul":                       c.toConsul,
+			"shares-to-etcd":                         c.toEtcd,
+			"shares-to-aws-secrets-manager":          c.toSecretsManager,
+			"shares-to-ssm":                          c.toSSM,
+			"shares-to-gcs":                          c.toGCS,
+			"shares-to-azure-key-vault":              c.toAzure,
+			"shares-to-physical-printer":             c.toPrinter,
+			"shares-to-slack":                        c.toSlack,
+			"shares-to-webhook":                      c.toWebhook,
+			"audit-trail-blockchain":                 c.anchorOnChain,
+			"shares-to-print-qr-pdf":                 c.toQRPDF,
+			"generate-ansible-vault-compatible":      c.toAnsibleVault,
+			"shares-to-barcode":                      c.toBarcode,
+			"shares-to-pass":                         c.toPass,
+			"shares-to-age-file-per-recipient":       c.toAgeRecipients,
+			"embed-time-window":                      c.embedTimeWindow,
+			"add-noise-shares":                       c.noiseShares != 0,
+			"shares-to-smartcard":                    c.toSmartcard,
+			"labels":                                 c.labels != "",
+			"indices":                                c.indices != "",
+			"padding":                                c.padding != 0,
+			"hmac-key":                               c.hmacKey != "",
+			"encoding":                               c.shareEncoding != decimalEncoding,
+			"outdir":                                 c.outDir != "",
+			"qr":                                     c.qr,
+			"dry-run":                                c.dryRun,
+			"threshold-in-share":                     c.thresholdInShare,
+			"count":                                  c.count != 1,
+			"silent":                                 c.silent,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = cmdGenerateGroups(groups, c.ceremonyID, os.Stdout)
+		if err != nil {
+			return usageError{err}
+		}
+
+		return nil
+	}
+
+	if c.ceremonyChecklist {
+		cl := newChecklist()
+		cl.airGap = c.airGap
+
+		if c.twoOperators {
+			operators, err := readOperators(c.operatorsFile)
+			if err != nil {
+				return err
+			}
+
+			cl.operators = operators
+		}
+
+		err := cmdChecklist(cl, os.Stderr)
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.sealEnvelope {
+		return runSealEnvelope(c)
+	}
+
+	if c.anonymized {
+		decoy, ok := new(big.Int).SetString(c.decoySecret, 62)
+		if !ok {
+			return usageError{errors.New("Invalid decoy secret.")}
+		}
+
+		err := cmdGenerateDecoy(c.numShares, c.minShares, decoy, c.ceremonyID, os.Stdout)
+		if err != nil {
+			return usageError{err}
+		}
+
+		return nil
+	}
+
+	if c.commitmentScheme {
+		err := cmdGenerateCommitted(c.numShares, c.minShares, os.Stdout)
+		if err != nil {
+			return usageError{err}
+		}
+
+		return nil
+	}
+
+	opts := generateOptions{
+		ceremonyID:             c.ceremonyID,
+		format:                 c.format,
+		noOversample:           c.noOversample,
+		expiresAfter:           c.expiresAfter,
+		verifyBeforeDistribute: c.verifyBeforeDistribute,
+		noiseShares:            c.noiseShares,
+		noiseSeed:              c.noiseSeed,
+		encoding:               c.shareEncoding,
+		thresholdInShare:       c.thresholdInShare,
+		silent:                 c.silent,
+		dryRun:                 c.dryRun,
+		padding:                c.padding,
+		timeout:                c.timeout,
+	}
+
+	if c.progress {
+		opts.progress = os.Stderr
+	}
+
+	if c.spreadsheet {
+		opts.format = "spreadsheet"
+	}
+
+	if c.toPasswordManager {
+		if passwordManagerHeaders[opts.format] == nil {
+			return usageError{errors.New("Password manager output requires -format 1password or -format bitwarden.")}
+		}
+	} else if passwordManagerHeaders[opts.format] != nil {
+		return usageError{errors.New("Password manager formats require -shares-to-password-manager-csv.")}
+	}
+
+	if c.printInstructions {
+		opts.instructions = &recoveryInstructions{adminContact: c.adminContact}
+
+		if c.custodianNames != "" {
+			opts.instructions.custodians = strings.Split(c.custodianNames, ",")
+		}
+	}
+
+	if c.labels != "" {
+		opts.labels = strings.Split(c.labels, ",")
+	}
+
+	if c.indices != "" {
+		for _, v := range strings.Split(c.indices, ",") {
+			x, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return usageError{fmt.Errorf("Invalid share index %q.", v)}
+			}
+
+			opts.indices = append(opts.indices, x)
+		}
+	}
+
+	if (c.autoShred || c.shredOnExit) && c.secretOut == "" {
+		return usageError{errors.New("Shredding requires -secret-out.")}
+	}
+
+	if c.secretOut != "" && c.dryRun {
+		// The file is not created, since nothing would be written to it.
+		opts.secretOut = io.Discard
+	} else if c.secretOut != "" {
+		fh, err := os.OpenFile(c.secretOut, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			return err
+		}
+		defer fh.Close()
+
+		opts.secretOut = fh
+	}
+
+	err := c.readSecret(&opts)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case c.splitPGP:
+		passphrase := []byte(os.Getenv(c.passphraseEnv))
+		opts.encryptShare = func(line string) (string, error) {
+			return pgpEncryptShare(line, passphrase)
+		}
+	case c.splitAge:
+		passphrase := os.Getenv(c.passphraseEnv)
+		opts.encryptShare = func(line string) (string, error) {
+			return ageEncryptShare(line, passphrase)
+		}
+	}
+
+	var cs closers
+	defer cs.close()
+
+	opts.sinks, err = c.openSinks(&cs)
+	if err != nil {
+		return err
+	}
+
+	// These sinks give each custodian only their own share, so the shares are not written to stdout.
+	opts.withholdShares = c.toPrinter || c.toQRPDF || c.toAnsibleVault || c.toBarcode || c.toAgeRecipients || c.outDir != ""
+
+	if c.signingKey != "" {
+		key, err := loadSigningKey(c.signingKey)
+		if err != nil {
+			return err
+		}
+
+		opts.signingKey = key
+	}
+
+	opts.hmacKey = hmacKey(c.hmacKey)
+
+	if c.withBackups {
+		opts.backups = c.backupCustodians
+	}
+
+	if c.embedTimeWindow {
+		w, err := parseTimeWindow(c.windowStart, c.windowEnd)
+		if err != nil {
+			return usageError{err}
+		}
+
+		opts.header = append(opts.header, timeWindowHeader+": "+w.header())
+	}
+
+	err = cmdGenerateBatch(c.count, c.numShares, c.minShares, opts, os.Stdout)
+	if err != nil {
+		if (c.autoShred || c.shredOnExit) && !c.dryRun {
+			// The secret may already have been written.
+			shredErr := shredFile(c.secretOut)
+			if shredErr != nil {
+				return usageError{fmt.Errorf("%w (shredding %s: %s)", err, c.secretOut, shredErr)}
+			}
+		}
+
+		return usageError{err}
+	}
+
+	if (c.autoShred || c.shredOnExit) && !c.dryRun {
+		return waitAndShred(c.secretOut, c.autoShred, c.shredAfterDelay, c.shredOnExit)
+	}
+
+	return nil
+}
+
+// readSecret sets the secret to split in opts if a source of the secret is given.
+func (c *cliFlags) readSecret(opts *generateOptions) error {
+	switch {
+	case c.curve25519Key:
+		secret, err := readCurve25519Key(c.keyFile)
+		if err != nil {
+			return err
+		}
+
+		opts.secret = secret
+		opts.encodeSecret = encodeCurve25519Key
+	case c.splitWIF:
+		secret, params, err := readWIF(c.keyFile)
+		if err != nil {
+			return err
+		}
+
+		opts.secret = secret
+		opts.encodeSecret = params.encode
+		opts.header = append(opts.header, wifHeader+": "+params.header())
+	case c.fromHWRNG:
+		secret, err := readHardwareRNG(c.rngDevice)
+		if err != nil {
+			return err
+		}
+
+		opts.secret = secret
+	case c.fromPassphrase:
+		passphrase, err := readNewPassphrase()
+		if err != nil {
+			return err
+		}
+
+		secret, masked, header, err := newPassphraseSecret(passphrase)
+		if err != nil {
+			return err
+		}
+
+		opts.secret = masked
+		opts.printSecret = secret
+		opts.header = append(opts.header, header...)
+	case c.wrap:
+		recipient, err := readCurve25519File(c.publicKeyFile)
+		if err != nil {
+			return err
+		}
+
+		secret, wrapped, header, err := newWrappedSecret(recipient)
+		if err != nil {
+			return err
+		}
+
+		opts.secret = wrapped
+		opts.printSecret = secret
+		opts.header = append(opts.header, recipientHeader+": "+header)
+	case c.splitTOTP:
+		secret, params, err := readTOTPSeed(c.keyFile)
+		if err != nil {
+			return err
+		}
+
+		opts.secret = secret
+		opts.encodeSecret = params.encode
+		opts.header = append(opts.header, totpHeader+": "+params.header())
+	case c.binary:
+		secret, length, err := readBinarySecret(os.Stdin)
+		if err != nil {
+			return usageError{err}
+		}
+
+		opts.secret = secret
+		opts.encodeSecret = binarySecretHex(length)
+		opts.header = append(opts.header, encodingHeader+": "+binaryEncodingHeader(length))
+	case c.secretEnv != "":
+		value := os.Getenv(c.secretEnv)
+		if value == "" {
+			return usageError{fmt.Errorf("The secret is not set, set %s.", c.secretEnv)}
+		}
+
+		secret, err := parseTextSecret(value)
+		if err != nil {
+			return usageError{err}
+		}
+
+		opts.secret = secret
+		opts.encodeSecret = encodeTextSecret
+		opts.header = append(opts.header, encodingHeader+": "+textEncoding)
+	case c.secretFile != "":
+		value, err := readTextSecretFile(c.secretFile, os.Stderr)
+		if err != nil {
+			return err
+		}
+
+		secret, err := parseTextSecret(value)
+		if err != nil {
+			return usageError{err}
+		}
+
+		opts.secret = secret
+		opts.encodeSecret = encodeTextSecret
+		opts.header = append(opts.header, encodingHeader+": "+textEncoding)
+	case c.secretValue != "":
+		secret, err := parseTextSecret(c.secretValue)
+		if err != nil {
+			return usageError{err}
+		}
+
+		opts.secret = secret
+		opts.encodeSecret = encodeTextSecret
+		opts.header = append(opts.header, encodingHeader+": "+textEncoding)
+	}
+
+	return nil
+}
+
+// openSinks opens the external stores the generated shares are written to. Resources that need to be closed are
+// added to cs.
+func (c *cliFlags) openSinks(cs *closers) ([]shareSink, error) {
+	var sinks []shareSink
+
+	// The PDF is written first, since it fails if the file exists.
+	if c.toQRPDF {
+		if c.outputFile == "" {
+			return nil, usageError{errors.New("The QR code PDF requires -output.")}
+		}
+
+		sinks = append(sinks, &qrPDFSink{path: c.outputFile, k: c.minShares, ceremonyID: c.ceremonyID})
+	}
+
+	if c.toAnsibleVault {
+		if c.sharesDir == "" {
+			return nil, usageError{errors.New("Ansible Vault output requires -shares-dir.")}
+		}
+
+		password := os.Getenv(c.vaultPasswordEnv)
+		if password == "" {
+			return nil, usageError{fmt.Errorf("Vault password is not set, set %s.", c.vaultPasswordEnv)}
+		}
+
+		sinks = append(sinks, &ansibleVaultSink{dir: c.sharesDir, password: []byte(password)})
+	}
+
+	if c.outDir != "" {
+		sinks = append(sinks, &outDirSink{dir: c.outDir})
+	}
+
+	// The QR codes are written after the share files, which create -outdir.
+	if c.qr {
+		dir := c.outDir
+		if dir == "" {
+			dir = "."
+		}
+
+		sinks = append(sinks, &qrSink{dir: dir})
+	}
+
+	if c.toBarcode {
+		if c.sharesDir == "" {
+			return nil, usageError{errors.New("Barcode output requires -shares-dir.")}
+		}
+
+		sinks = append(sinks, &barcodeSink{dir: c.sharesDir})
+	}
+
+	if c.toAgeRecipients {
+		if c.sharesDir == "" || c.recipientKeysDir == "" {
+			return nil, usageError{errors.New("Age files per recipient require -shares-dir and -recipient-keys-dir.")}
+		}
+
+		sink, err := newAgeRecipientSink(c.recipientKeysDir, c.sharesDir, c.numShares)
+		if err != nil {
+			return nil, usageError{err}
+		}
+
+		sinks = append(sinks, sink)
+	}
+
+	if c.toPass {
+		sinks = append(sinks, newPassStore(c.passDir, c.passPrefix))
+	}
+
+	if c.toRedis {
+		store, err := newRedisStore(c.redisAddr, c.ceremonyID, c.redisTTL)
+		if err != nil {
+			return nil, usageError{err}
+		}
+		cs.add(func() { store.Close() })
+
+		sinks = append(sinks, store)
+	}
+
+	if c.toPrinter {
+		printers, err := parsePrinterList(c.printerList)
+		if err != nil {
+			return nil, err
+		}
+
+		sinks = append(sinks, newPrinterSink(printers, os.Stdin, os.Stderr))
+	}
+
+	if c.toSlack {
+		channels, err := parseChannelMap(c.channelMap)
+		if err != nil {
+			return nil, err
+		}
+
+		store := newSlackStore(os.Getenv(c.slackTokenEnv), channels, os.Stderr)
+		store.codeBlock = c.slackCodeBlock
+
+		sinks = append(sinks, store)
+	}
+
+	if c.toWebhook {
+		urls, err := parseWebhookMap(c.webhookMap)
+		if err != nil {
+			return nil, err
+		}
+
+		store, err := newWebhookStore(urls, os.Getenv(c.webhookKeyEnv), c.ceremonyID, c.webhookSkipVerify)
+		if err != nil {
+			return nil, usageError{err}
+		}
+
+		sinks = append(sinks, store)
+	}
+
+	if c.toSmartcard {
+		store, closeStore, err := newSmartcardSink(c.pkcs11Module, c.cardList, os.Getenv(c.pinEnv), c.ceremonyID)
+		if err != nil {
+			return nil, err
+		}
+		cs.add(closeStore)
+
+		sinks = append(sinks, store)
+	}
+
+	if c.toConsul {
+		sinks = append(sinks, newConsulStore(c.consulAddr, c.consulPrefix, os.Getenv("CONSUL_HTTP_TOKEN")))
+	}
+
+	if c.toSecretsManager {
+		cfg, err := loadAWSConfig(context.Background(), c.awsRegion, c.assumeRoleARN)
+		if err != nil {
+			return nil, err
+		}
+
+		sinks = append(sinks, newSecretsManagerStore(secretsmanager.NewFromConfig(cfg), c.secretIDPrefix, c.ceremonyID))
+	}
+
+	if c.toSSM {
+		cfg, err := loadAWSConfig(context.Background(), c.awsRegion, c.assumeRoleARN)
+		if err != nil {
+			return nil, err
+		}
+
+		sinks = append(sinks, newSSMStore(ssm.NewFromConfig(cfg), c.ssmPrefix, c.ssmKMSKeyID, c.ceremonyID))
+	}
+
+	if c.toGCS {
+		client, err := storage.NewClient(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		cs.add(func() { client.Close() })
+
+		sinks = append(sinks, newGCSStore(client, c.gcsBucket, c.gcsPrefix, c.ceremonyID, c.minShares))
+	}
+
+	if c.toAzure {
+		client, err := newAzureKeyVaultClient(c.vaultURL)
+		if err != nil {
+			return nil, err
+		}
+
+		sinks = append(sinks, newAzureKeyVaultStore(client, c.secretPrefix, c.ceremonyID))
+	}
+
+	if c.toEtcd {
+		store, err := newEtcdStore(strings.Split(c.etcdEndpoints, ","), c.etcdPrefix, c.etcdCert, c.etcdKey, c.etcdCA)
+		if err != nil {
+			return nil, err
+		}
+		cs.add(func() { store.Close() })
+
+		sinks = append(sinks, store)
+	}
+
+	// The ceremony is anchored last, once the shares have been handed out.
+	if c.anchorOnChain {
+		anchor, err := newEthAnchor(c.ethRPC, os.Getenv(c.ethKeyEnv), c.minShares, c.ceremonyID, os.Stderr)
+		if err != nil {
+			return nil, err
+		}
+		cs.add(anchor.Close)
+
+		sinks = append(sinks, anchor)
+	}
+
+	return sinks, nil
+}
+
+// runSealEnvelope writes a tamper evident envelope to the -output file.
+func runSealEnvelope(c *cliFlags) error {
+	if c.outputFile == "" {
+		return usageError{errors.New("Sealing an envelope requires -output.")}
+	}
+
+	fh, err := os.OpenFile(c.outputFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+
+	err = cmdGenerateEnvelope(c.numShares, c.minShares, os.Getenv(c.envelopePasswordEnv), c.ceremonyID, fh, os.Stdout)
+	if err != nil {
+		fh.Close()
+		os.Remove(c.outputFile)
+		return usageError{err}
+	}
+
+	return fh.Close()
+}
+
+// recoverOptions returns the options for recovering the secret from the shares set by the flags.
+func (c *cliFlags) recoverOptions() (recoverOptions, error) {
+	recoverOpts := recoverOptions{ceremonyID: c.ceremonyID}
+
+	if c.format == "json" {
+		recoverOpts.format = "json"
+	}
+
+	recoverOpts.binary = c.binary
+	recoverOpts.verbose = c.verbose
+	recoverOpts.checkPrefix = c.recoverCheck
+
+	if c.curve25519Key {
+		recoverOpts.encodeSecret = encodeCurve25519Key
+	}
+
+	if c.privateKeyFile != "" {
+		key, err := readCurve25519File(c.privateKeyFile)
+		if err != nil {
+			return recoverOptions{}, err
+		}
+
+		recoverOpts.unwrapKey = key
+	}
+
+	switch {
+	case c.splitPGP:
+		passphrase := []byte(os.Getenv(c.passphraseEnv))
+		recoverOpts.decryptShares = func(in io.Reader) (io.Reader, error) {
+			return decryptArmoredShares(in, pgpArmorType, func(block string) (string, error) {
+				return pgpDecryptShare(block, passphrase)
+			})
+		}
+	case c.splitAge:
+		passphrase := os.Getenv(c.passphraseEnv)
+		recoverOpts.decryptShares = func(in io.Reader) (io.Reader, error) {
+			return decryptArmoredShares(in, ageArmorType, func(file string) (string, error) {
+				return ageDecryptShare(file, passphrase)
+			})
+		}
+	}
+
+	if c.verifyKey != "" {
+		key, err := loadVerifyKey(c.verifyKey)
+		if err != nil {
+			return recoverOptions{}, err
+		}
+
+		recoverOpts.verifyKey = key
+	}
+
+	recoverOpts.hmacKey = hmacKey(c.hmacKey)
+
+	if c.deriveFromPassphrase {
+		recoverOpts.passphrase = func() ([]byte, error) {
+			return readPassphrase("Passphrase: ")
+		}
+	}
+
+	if c.lockTimeWindow {
+		w, err := parseTimeWindow(c.windowStart, c.windowEnd)
+		if err != nil {
+			return recoverOptions{}, usageError{err}
+		}
+
+		recoverOpts.timeWindow = &w
+	}
+
+	if c.quorum {
+		recoverOpts.authorize = func(ceremonyID string, k int) error {
+			return verifyQuorum(c.custodianKeysDir, c.signaturesDir, ceremonyID, k, time.Now(), c.authorizationMaxAge, os.Stderr)
+		}
+	}
+
+	return recoverOpts, nil
+}
+
+func runRecover(c *cliFlags, groups []shareGroup) error {
+	recoverOpts, err := c.recoverOptions()
+	if err != nil {
+		return err
+	}
+
+	var cs closers
+	defer cs.close()
+
+	// The log file is opened first, so that the shares are not read if it can not be written.
+	diag, err := openLogFile(c.logFile, os.Stderr, &cs)
+	if err != nil {
+		return err
+	}
+
+	in, err := c.openShares(&cs)
+	if err != nil {
+		return err
+	}
+
+	if c.commitmentScheme {
+		err := cmdRecoverCommitted(in, diag, os.Stdout)
+		if err != nil {
+			return usageError{err}
+		}
+
+		return nil
+	}
+
+	if c.fromEnvelope != "" {
+		envelope, err := os.Open(c.fromEnvelope)
+		if err != nil {
+			return err
+		}
+		defer envelope.Close()
+
+		info, err := envelope.Stat()
+		if err != nil {
+			return err
+		}
+
+		return cmdOpenEnvelope(envelope, info.Size(), in, c.ceremonyID, diag, os.Stdout)
+	}
+
+	if groups != nil {
+		return cmdRecoverGroups(in, groups, c.ceremonyID, diag, os.Stdout)
+	}
+
+	err = cmdRecover(in, recoverOpts, diag, os.Stdout)
+	if err != nil {
+		return usageError{err}
+	}
+
+	return nil
+}
+
+// openShares returns a reader for the shares to recover from, either from one of the external share sources or from
+// -secrets. Resources that need to be closed are added to cs.
+func (c *cliFlags) openShares(cs *closers) (io.Reader, error) {
+	var source shareSource
+
+	switch {
+	case c.fromRedis:
+		store, err := newRedisStore(c.redisAddr, c.ceremonyID, 0)
+		if err != nil {
+			return nil, usageError{err}
+		}
+		cs.add(func() { store.Close() })
+
+		source = store
+	case c.fromConsul:
+		source = newConsulStore(c.consulAddr, c.consulPrefix, os.Getenv("CONSUL_HTTP_TOKEN"))
+	case c.fromSecretsManager:
+		cfg, err := loadAWSConfig(context.Background(), c.awsRegion, c.assumeRoleARN)
+		if err != nil {
+			return nil, err
+		}
+
+		source = newSecretsManagerStore(secretsmanager.NewFromConfig(cfg), c.secretIDPrefix, c.ceremonyID)
+	case c.fromSSM:
+		cfg, err := loadAWSConfig(context.Background(), c.awsRegion, c.assumeRoleARN)
+		if err != nil {
+			return nil, err
+		}
+
+		source = newSSMStore(ssm.NewFromConfig(cfg), c.ssmPrefix, "", c.ceremonyID)
+	case c.fromGCS:
+		client, err := storage.NewClient(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		cs.add(func() { client.Close() })
+
+		source = newGCSStore(client, c.gcsBucket, c.gcsPrefix, c.ceremonyID, c.minShares)
+	case c.fromSlack:
+		channels, err := parseChannelMap(c.channelMap)
+		if err != nil {
+			return nil, err
+		}
+
+		store := newSlackStore(os.Getenv(c.slackTokenEnv), channels, os.Stderr)
+		store.timestamps = strings.Split(c.tsList, ",")
+
+		source = store
+	case c.fromAzure:
+		client, err := newAzureKeyVaultClient(c.vaultURL)
+		if err != nil {
+			return nil, err
+		}
+
+		source = newAzureKeyVaultStore(client, c.secretPrefix, c.ceremonyID)
+	case c.fromEtcd:
+		store, err := newEtcdStore(strings.Split(c.etcdEndpoints, ","), c.etcdPrefix, c.etcdCert, c.etcdKey, c.etcdCA)
+		if err != nil {
+			return nil, err
+		}
+		cs.add(func() { store.Close() })
+
+		source = store
+	case c.fromSmartcard:
+		var slots []uint
+
+		for _, slot := range strings.Split(c.cardSlots, ",") {
+			n, err := strconv.ParseUint(slot, 10, 32)
+			if err != nil {
+				return nil, usageError{fmt.Errorf("Invalid card slot %q.", slot)}
+			}
+
+			slots = append(slots, uint(n))
+		}
+
+		store, closeStore, err := newSmartcardSource(c.pkcs11Module, slots, os.Getenv(c.pinEnv), c.ceremonyID)
+		if err != nil {
+			return nil, err
+		}
+		cs.add(closeStore)
+
+		source = store
+	case c.fromSMS:
+		f, err := openInput(c.secrets)
+		if err != nil {
+			return nil, err
+		}
+		cs.add(func() { f.Close() })
+
+		source = smsSource{f}
+	case c.fromOCR:
+		source = newOCRSource(c.imageDir, os.Stderr)
+	case c.fromBarcode:
+		source = barcodeSource{c.inputImages}
+	case c.fromPass:
+		source = newPassStore(c.passDir, c.passPrefix)
+	case c.sharesDir != "":
+		source = shareDirSource{c.sharesDir}
+	}
+
+	if source != nil {
+		return readSource(source)
+	}
+
+	f, err := openInput(c.secrets)
+	if err != nil {
+		return nil, err
+	}
+	cs.add(func() { f.Close() })
+
+	return f, nil
+}
+
+func runResign(c *cliFlags) error {
+	if c.oldSigningKey == "" || c.newSigningKey == "" {
+		return usageError{errors.New("Re-signing requires -old-signing-key and -new-signing-key.")}
+	}
+
+	oldKey, err := loadSigningKey(c.oldSigningKey)
+	if err != nil {
+		return err
+	}
+
+	newKey, err := loadSigningKey(c.newSigningKey)
+	if err != nil {
+		return err
+	}
+
+	fh, err := openInput(c.secrets)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	return cmdResign(fh, oldKey, newKey, os.Stdout)
+}
+
+func runReshare(c *cliFlags) error {
+	recoverOpts, err := c.recoverOptions()
+	if err != nil {
+		return err
+	}
+
+	var cs closers
+	defer cs.close()
+
+	in, err := c.openShares(&cs)
+	if err != nil {
+		return err
+	}
+
+	opts := generateOptions{
+		ceremonyID: uuid.NewString(),
+		hmacKey:    hmacKey(c.hmacKey),
+	}
+
+	if c.signingKey != "" {
+		key, err := loadSigningKey(c.signingKey)
+		if err != nil {
+			return err
+		}
+
+		opts.signingKey = key
+	}
+
+	err = cmdReshare(in, c.numShares, c.minShares, recoverOpts, opts, os.Stderr, os.Stdout)
+	if err != nil {
+		return usageError{err}
+	}
+
+	return nil
+}
+
+func runReindex(c *cliFlags) error {
+	recoverOpts, err := c.recoverOptions()
+	if err != nil {
+		return err
+	}
+
+	var cs closers
+	defer cs.close()
+
+	in, err := c.openShares(&cs)
+	if err != nil {
+		return err
+	}
+
+	err = cmdReindex(in, recoverOpts, os.Stderr, os.Stdout)
+	if err != nil {
+		return usageError{err}
+	}
+
+	return nil
+}
+
+func runDaemon(c *cliFlags) error {
+	if c.sharesDir == "" {
+		return usageError{errors.New("The daemon requires -shares-dir.")}
+	}
+
+	info, err := os.Stat(c.sharesDir)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory.", c.sharesDir)
+	}
+
+	audit := io.Writer(os.Stderr)
+
+	if c.auditLog != "" {
+		fh, err := os.OpenFile(c.auditLog, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+		if err != nil {
+			return err
+		}
+		defer fh.Close()
+
+		audit = fh
+	}
+
+	d := newDaemon(c.sharesDir, c.minShares, audit)
+	d.heartbeat = c.auditHeartbeat
+	d.minShareAge = c.minShareAge
+	d.ceremonyID = c.ceremonyID
+
+	if c.verifyKey != "" {
+		key, err := loadVerifyKey(c.verifyKey)
+		if err != nil {
+			return err
+		}
+
+		d.verifyKey = key
+	}
+
+	switch {
+	case c.onQuorumExec != "":
+		d.onQuorum = quorumExec(c.onQuorumExec, os.Stderr)
+	case c.onQuorumPost != "":
+		d.onQuorum = quorumHTTPPost(&http.Client{Timeout: time.Minute}, c.onQuorumPost, os.Stderr)
+	}
+
+	return d.run(context.Background(), os.Stderr, os.Stdout)
+}
+
+func runScheduledRefresh(c *cliFlags) error {
+	schedule, err := parseCronSchedule(c.cronExpr)
+	if err != nil {
+		return usageError{err}
+	}
+
+	if c.sharesDir == "" {
+		return usageError{errors.New("Scheduled refresh requires -shares-dir.")}
+	}
+
+	audit := io.Writer(os.Stderr)
+
+	if c.auditLog != "" {
+		fh, err := os.OpenFile(c.auditLog, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+		if err != nil {
+			return err
+		}
+		defer fh.Close()
+
+		audit = fh
+	}
+
+	r := newRefresher(c.sharesDir, c.numShares, c.minShares, audit)
+
+	var cs closers
+	defer cs.close()
+
+	r.sinks, err = c.openSinks(&cs)
+	if err != nil {
+		return err
+	}
+
+	return r.run(context.Background(), schedule, os.Stderr)
+}
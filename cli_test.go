@@ -0,0 +1,211 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCLIFlags_conflicts(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"no conflict", []string{"-split-bitcoin-wif"}, ""},
+		{"secret sources", []string{"-split-bitcoin-wif", "-secret-from-hardware-rng"}, "-secret-from-hardware-rng and -split-bitcoin-wif are mutually exclusive."},
+		{"curve25519 encoding on recovery", []string{"-recover", "-split-curve25519-key", "-shares-from-redis"}, ""},
+		{"share sources", []string{"-recover", "-shares-from-redis", "-shares-from-consul"}, "-shares-from-consul and -shares-from-redis are mutually exclusive."},
+		{"share encryptions", []string{"-split-pgp-symmetric", "-split-age-passphrase"}, "-split-age-passphrase and -split-pgp-symmetric are mutually exclusive."},
+		{"caller provided secret", []string{"-secret", "hunter2", "-secret-from-hardware-rng"}, "-secret and -secret-from-hardware-rng are mutually exclusive."},
+		{"secret from environment", []string{"-secret", "hunter2", "-secret-env", "SECRET"}, "-secret and -secret-env are mutually exclusive."},
+		{"secret from file", []string{"-secret", "hunter2", "-secret-file", "/nonexistent"}, "-secret and -secret-file are mutually exclusive."},
+		{"share directory", []string{"-recover", "-shares-dir", "shares", "-shares-from-redis"}, "-shares-dir and -shares-from-redis are mutually exclusive."},
+		{"share images", []string{"-qr", "-shares-to-barcode"}, "-qr and -shares-to-barcode are mutually exclusive."},
+		{"quorum handlers", []string{"-mode", "daemon", "-on-quorum-exec", "x", "-on-quorum-http-post", "y"}, "-on-quorum-exec and -on-quorum-http-post are mutually exclusive."},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("secret", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+
+			c := newCLIFlags(fs)
+
+			err := fs.Parse(tc.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if c.doRecover {
+				c.mode = "recover"
+			}
+
+			err = c.validate()
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %s", err)
+				}
+
+				return
+			}
+
+			if err == nil || err.Error() != tc.wantErr {
+				t.Fatalf("unexpected error. want %q, have %v", tc.wantErr, err)
+			}
+
+			var usage usageError
+			if !errors.As(err, &usage) {
+				t.Errorf("conflicting flags are not a usage error: %v", err)
+			}
+		})
+	}
+}
+
+func TestRun_unknownMode(t *testing.T) {
+	fs := flag.NewFlagSet("secret", flag.ContinueOnError)
+	c := newCLIFlags(fs)
+
+	err := fs.Parse([]string{"-mode", "bogus"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err = run(c)
+
+	var usage usageError
+	if !errors.As(err, &usage) {
+		t.Errorf("want a usage error, have %v", err)
+	}
+}
+
+func TestRun_shredOnError(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "secret.txt")
+
+	fs := flag.NewFlagSet("secret", flag.ContinueOnError)
+	c := newCLIFlags(fs)
+
+	err := fs.Parse([]string{"-n", "2", "-k", "3", "-secret-out", name, "-shred-on-exit"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err = run(c)
+	if err == nil {
+		t.Fatal("expected an error for k > n")
+	}
+
+	_, err = os.Stat(name)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("secret file not shredded: %v", err)
+	}
+}
+
+func TestRun_daemonSharesDir(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		args  []string
+		usage bool
+	}{
+		{"missing flag", []string{"-mode", "daemon"}, true},
+		{"missing directory", []string{"-mode", "daemon", "-shares-dir", filepath.Join(t.TempDir(), "missing")}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("secret", flag.ContinueOnError)
+			c := newCLIFlags(fs)
+
+			err := fs.Parse(tc.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			err = run(c)
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+
+			var usage usageError
+			if errors.As(err, &usage) != tc.usage {
+				t.Errorf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func TestRun_groupsUnsupportedFlags(t *testing.T) {
+	fs := flag.NewFlagSet("secret", flag.ContinueOnError)
+	c := newCLIFlags(fs)
+
+	err := fs.Parse([]string{
+		"-split-for-groups", "-group-config", `[{"name":"exec","k":2,"n":3},{"name":"board","k":3,"n":5}]`,
+		"-signing-key", "key.pem", "-shares-to-redis",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err = run(c)
+
+	want := "-split-for-groups does not support -shares-to-redis, -signing-key."
+	if err == nil || err.Error() != want {
+		t.Fatalf("unexpected error. want %q, have %v", want, err)
+	}
+}
+
+func TestCLIFlags_outputDir(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"default", nil, "."},
+		{"secret out", []string{"-secret-out", "/ceremony/secret.txt", "-shares-dir", "/shares"}, "/ceremony"},
+		{"shares dir", []string{"-shares-dir", "/shares"}, "/shares"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("secret", flag.ContinueOnError)
+			c := newCLIFlags(fs)
+
+			err := fs.Parse(tc.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if have := c.outputDir(); have != tc.want {
+				t.Errorf("unexpected output directory. want %q, have %q", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestCLIFlags_secretEnv(t *testing.T) {
+	fs := flag.NewFlagSet("secret", flag.ContinueOnError)
+	c := newCLIFlags(fs)
+
+	err := fs.Parse([]string{"-secret-env", "TEST_SECRET"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	t.Setenv("TEST_SECRET", "")
+
+	var opts generateOptions
+
+	err = c.readSecret(&opts)
+	if err == nil {
+		t.Fatal("expected error for an empty secret, got nil")
+	}
+
+	t.Setenv("TEST_SECRET", "hunter2")
+
+	err = c.readSecret(&opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	have, _ := encodeTextSecret(opts.secret)
+	if have != "hunter2" {
+		t.Errorf("want secret %q, have %q", "hunter2", have)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/farhaven/secret/pedersen"
+	"github.com/farhaven/secret/shamir"
+)
+
+// commitmentsHeader is the name of the header line with the Pedersen commitments of a verifiable sharing.
+const commitmentsHeader = "commitments"
+
+// cmdGenerateCommitted generates n Pedersen verifiable shares of a random secret, k of which are required to recover
+// it, and writes the secret, the commitments to the sharing polynomial and the shares to out.
+func cmdGenerateCommitted(n, k int, out io.Writer) error {
+	if k > n {
+		return errors.New("There will not be enough shares to recover the secret.")
+	}
+
+	if n < 1 || k < 1 {
+		return errors.New("Number of shares must be larger than 1.")
+	}
+
+	secret, err := rand.Int(rand.Reader, shamir.Prime)
+	if err != nil {
+		return err
+	}
+
+	shares, commitments, err := pedersen.Commit(pedersen.DefaultGroup, secret, n, k, rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	encoded := make([]string, len(commitments))
+	for i, c := range commitments {
+		encoded[i] = c.Text(16)
+	}
+
+	fmt.Fprintln(out, "secret:", secret.Text(62))
+	fmt.Fprintf(out, "%s: %s\n", commitmentsHeader, strings.Join(encoded, ","))
+
+	fmt.Fprintf(out, "shares (need at least %d of these for recovery):\n", k)
+	for _, share := range shares {
+		fmt.Fprintln(out, share)
+	}
+
+	return nil
+}
+
+// parseCommitments parses the comma separated hexadecimal commitments of a commitments header.
+func parseCommitments(value string) ([]*big.Int, error) {
+	var commitments []*big.Int
+
+	for _, part := range strings.Split(value, ",") {
+		c, ok := new(big.Int).SetString(part, 16)
+		if !ok {
+			return nil, fmt.Errorf("Invalid commitment %q.", part)
+		}
+
+		commitments = append(commitments, c)
+	}
+
+	return commitments, nil
+}
+
+// cmdRecoverCommitted reads Pedersen verifiable shares and the commitments header from in, checks every share against
+// the commitments and recovers the secret from the valid ones. Shares that do not match the commitments are reported
+// to diag and skipped. The threshold is the number of commitments.
+func cmdRecoverCommitted(in io.Reader, diag io.Writer, out io.Writer) error {
+	var (
+		commitments []*big.Int
+		shares      []pedersen.Share
+	)
+
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		t := strings.TrimSpace(scanner.Text())
+
+		if t == "" || strings.HasPrefix(t, "secret: ") || strings.HasPrefix(t, "shares") {
+			continue
+		}
+
+		if value, ok := strings.CutPrefix(t, commitmentsHeader+": "); ok {
+			var err error
+
+			commitments, err = parseCommitments(value)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		share, err := pedersen.ParseShare(t)
+		if err != nil {
+			fmt.Fprintf(diag, "reading share %q: %s\n", t, err)
+			continue
+		}
+
+		shares = append(shares, share)
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return err
+	}
+
+	if commitments == nil {
+		return errors.New("The shares can not be verified without the commitments header.")
+	}
+
+	var valid []pedersen.Share
+
+	for _, share := range shares {
+		if !pedersen.VerifyShare(pedersen.DefaultGroup, share, commitments) {
+			fmt.Fprintf(diag, "reading share %q: does not match the commitments\n", share.X.Text(16))
+			continue
+		}
+
+		valid = append(valid, share)
+	}
+
+	k := len(commitments)
+	if len(valid) < k {
+		return fmt.Errorf("Need at least %d valid shares, have %d.", k, len(valid))
+	}
+
+	secret, err := pedersen.RecoverSecret(pedersen.DefaultGroup, valid[:k])
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out, secret.Text(62))
+
+	return nil
+}
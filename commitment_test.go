@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCommitted_roundtrip(t *testing.T) {
+	var genBuf bytes.Buffer
+
+	err := cmdGenerateCommitted(5, 3, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(genBuf.String()), "\n")
+	if len(lines) != 8 {
+		t.Fatalf("want 8 lines, have %d: %q", len(lines), lines)
+	}
+
+	secret := strings.TrimPrefix(lines[0], "secret: ")
+
+	// A tampered share is reported and skipped, the remaining three recover the secret.
+	tampered := lines[3][:len(lines[3])-1] + "0"
+	if tampered == lines[3] {
+		tampered = lines[3][:len(lines[3])-1] + "1"
+	}
+
+	input := strings.Join([]string{lines[1], tampered, lines[4], lines[5], lines[6]}, "\n")
+
+	var (
+		diag   bytes.Buffer
+		outBuf bytes.Buffer
+	)
+
+	err = cmdRecoverCommitted(strings.NewReader(input), &diag, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if outBuf.String() != secret+"\n" {
+		t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
+	}
+
+	if !strings.Contains(diag.String(), "does not match the commitments") {
+		t.Errorf("tampered share not reported: %q", diag.String())
+	}
+}
+
+func TestCommitted_recoverErrors(t *testing.T) {
+	var genBuf bytes.Buffer
+
+	err := cmdGenerateCommitted(3, 2, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(genBuf.String()), "\n")
+
+	for _, tc := range []struct {
+		name  string
+		lines []string
+	}{
+		{"missing commitments", lines[3:]},
+		{"too few shares", []string{lines[1], lines[3]}},
+		{"invalid commitments", append([]string{"commitments: xyz"}, lines[3:]...)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := cmdRecoverCommitted(strings.NewReader(strings.Join(tc.lines, "\n")), &bytes.Buffer{}, &bytes.Buffer{})
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestCmdGenerateCommitted_invalidThreshold(t *testing.T) {
+	err := cmdGenerateCommitted(2, 3, &bytes.Buffer{})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// consulStore stores shares in the Consul KV store at <prefix>/share-<index>.
+type consulStore struct {
+	addr   string // Base URL of the Consul agent, e.g. http://127.0.0.1:8500.
+	prefix string
+	token  string // ACL token, usually taken from CONSUL_HTTP_TOKEN.
+	client *http.Client
+}
+
+func newConsulStore(addr, prefix, token string) *consulStore {
+	return &consulStore{
+		addr:   strings.TrimSuffix(addr, "/"),
+		prefix: strings.Trim(prefix, "/"),
+		token:  token,
+		client: http.DefaultClient,
+	}
+}
+
+func (c *consulStore) do(method, key string, query url.Values, body []byte) (*http.Response, error) {
+	u := c.addr + "/v1/kv/" + key
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	if c.token != "" {
+		req.Header.Set("X-Consul-Token", c.token)
+	}
+
+	return c.client.Do(req)
+}
+
+func (c *consulStore) StoreShares(shares []storedShare) error {
+	for _, share := range shares {
+		key := c.prefix + "/share-" + share.index
+
+		resp, err := c.do(http.MethodPut, key, nil, []byte(share.line))
+		if err != nil {
+			return fmt.Errorf("storing share in Consul: %w", err)
+		}
+
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("storing share in Consul: %s", resp.Status)
+		}
+	}
+
+	return nil
+}
+
+func (c *consulStore) LoadShares() ([]string, error) {
+	resp, err := c.do(http.MethodGet, c.prefix+"/share-", url.Values{"recurse": {"true"}}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("reading shares from Consul: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("reading shares from Consul: %s", resp.Status)
+	}
+
+	var entries []struct {
+		Key   string
+		Value []byte // Consul returns values base64 encoded, which encoding/json decodes for []byte.
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&entries)
+	if err != nil {
+		return nil, fmt.Errorf("reading shares from Consul: %w", err)
+	}
+
+	lines := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		lines = append(lines, string(entry.Value))
+	}
+
+	return lines, nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeConsul implements the parts of the Consul KV API used by consulStore.
+type fakeConsul struct {
+	mu    sync.Mutex
+	token string
+	kv    map[string][]byte
+}
+
+func (f *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Consul-Token") != f.token {
+		http.Error(w, "ACL not found", http.StatusForbidden)
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		buf, _ := io.ReadAll(r.Body)
+		f.kv[key] = buf
+		io.WriteString(w, "true")
+	case http.MethodGet:
+		type entry struct {
+			Key   string
+			Value []byte
+		}
+
+		var entries []entry
+
+		for k, v := range f.kv {
+			if k == key || (r.URL.Query().Get("recurse") != "" && strings.HasPrefix(k, key)) {
+				entries = append(entries, entry{k, v})
+			}
+		}
+
+		if len(entries) == 0 {
+			http.NotFound(w, r)
+			return
+		}
+
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+		json.NewEncoder(w).Encode(entries)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func TestConsul_roundtrip(t *testing.T) {
+	consul := &fakeConsul{token: "s3cr3t", kv: make(map[string][]byte)}
+
+	srv := httptest.NewServer(consul)
+	defer srv.Close()
+
+	store := newConsulStore(srv.URL, "/ceremonies/test/", "s3cr3t")
+
+	var genBuf bytes.Buffer
+
+	err := cmdGenerate(5, 3, generateOptions{sinks: []shareSink{store}}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(consul.kv) != 5 {
+		t.Fatalf("want 5 keys, have %d", len(consul.kv))
+	}
+
+	for key, value := range consul.kv {
+		index := strings.SplitN(string(value), ",", 2)[0]
+		if key != "ceremonies/test/share-"+index {
+			t.Errorf("unexpected key %q for share %q", key, value)
+		}
+	}
+
+	in, err := readSource(store)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var (
+		errBuf bytes.Buffer
+		outBuf bytes.Buffer
+	)
+
+	err = cmdRecover(in, recoverOptions{}, &errBuf, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	secret := strings.TrimPrefix(strings.SplitN(genBuf.String(), "\n", 2)[0], "secret: ")
+	if outBuf.String() != secret+"\n" {
+		t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
+	}
+}
+
+func TestConsul_wrongToken(t *testing.T) {
+	srv := httptest.NewServer(&fakeConsul{token: "s3cr3t", kv: make(map[string][]byte)})
+	defer srv.Close()
+
+	store := newConsulStore(srv.URL, "test", "wrong")
+
+	err := cmdGenerate(5, 3, generateOptions{sinks: []shareSink{store}}, &bytes.Buffer{})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	_, err = store.LoadShares()
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/farhaven/secret/shamir"
+	"github.com/posener/sharedsecret"
+)
+
+// csvSharesHeader is the header row of the csv format.
+var csvSharesHeader = []string{"index", "value", "threshold"}
+
+// writeCSVShares writes shares as CSV rows "index,value,threshold" after a header row. All fields are quoted, even
+// though decimal indices and values never need it.
+func writeCSVShares(out io.Writer, shares []sharedsecret.Share, k int) error {
+	var buf bytes.Buffer
+
+	writeCSVRow(&buf, csvSharesHeader)
+
+	for _, share := range shares {
+		x, y := shamir.ShareXY(share)
+		writeCSVRow(&buf, []string{x.String(), y.String(), strconv.Itoa(k)})
+	}
+
+	_, err := buf.WriteTo(out)
+
+	return err
+}
+
+// writeCSVRow writes fields as a CSV row with every field quoted.
+func writeCSVRow(buf *bytes.Buffer, fields []string) {
+	for i, field := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		buf.WriteString(`"` + strings.ReplaceAll(field, `"`, `""`) + `"`)
+	}
+
+	buf.WriteString("\r\n")
+}
+
+// readCSVShares converts the input to share lines "index,value,k" if it is the csv output of cmdGenerate, which is
+// detected by its header row. Other input is returned unchanged.
+func readCSVShares(in io.Reader) (io.Reader, error) {
+	buf, err := io.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+
+	first, _, _ := strings.Cut(strings.TrimSpace(string(buf)), "\n")
+
+	header, err := csv.NewReader(strings.NewReader(first)).Read()
+	if err != nil || !slices.Equal(header, csvSharesHeader) {
+		return bytes.NewReader(buf), nil
+	}
+
+	r := csv.NewReader(bytes.NewReader(bytes.TrimSpace(buf)))
+	r.FieldsPerRecord = len(csvSharesHeader)
+
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV shares: %w", err)
+	}
+
+	var text strings.Builder
+
+	for i, row := range rows[1:] {
+		if !isDecimal(row[2]) {
+			return nil, fmt.Errorf("Invalid threshold %q in CSV row %d.", row[2], i+2)
+		}
+
+		if strings.Contains(row[0], ",") || strings.Contains(row[1], ",") {
+			return nil, errors.New("CSV share fields must not contain commas.")
+		}
+
+		text.WriteString(strings.Join(row, ",") + "\n")
+	}
+
+	return strings.NewReader(text.String()), nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+func TestCSV_roundtrip(t *testing.T) {
+	var (
+		genBuf    bytes.Buffer
+		secretBuf bytes.Buffer
+	)
+
+	err := cmdGenerate(5, 3, generateOptions{format: "csv", secretOut: &secretBuf, ceremonyID: "2026-10"}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(genBuf.String()), "\r\n")
+	if len(lines) != 6 {
+		t.Fatalf("unexpected output: %q", genBuf.String())
+	}
+
+	if lines[0] != `"index","value","threshold"` {
+		t.Errorf("unexpected header row: %q", lines[0])
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(genBuf.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, row := range rows[1:] {
+		if len(row) != 3 || row[2] != "3" {
+			t.Errorf("unexpected row: %q", row)
+		}
+	}
+
+	input := strings.Join(append(lines[:1], lines[2:5]...), "\r\n")
+
+	var outBuf bytes.Buffer
+
+	err = cmdRecover(strings.NewReader(input), recoverOptions{}, &bytes.Buffer{}, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if "secret: "+outBuf.String() != secretBuf.String() {
+		t.Errorf("unexpected recovered secret. want %q, have %q", secretBuf.String(), outBuf.String())
+	}
+}
+
+func TestCSV_errors(t *testing.T) {
+	for _, opts := range []generateOptions{
+		{format: "csv"},
+		{format: "csv", secretOut: &bytes.Buffer{}, labels: []string{"a", "b", "c"}},
+		{format: "csv", secretOut: &bytes.Buffer{}, encoding: base58Encoding},
+		{format: "csv", secretOut: &bytes.Buffer{}, header: []string{encodingHeader + ": " + textEncoding}},
+	} {
+		var buf bytes.Buffer
+
+		err := cmdGenerate(3, 2, opts, &buf)
+		if err == nil {
+			t.Errorf("expected error for %+v, got nil", opts)
+		}
+
+		if buf.Len() != 0 {
+			t.Errorf("unexpected output: %q", buf.String())
+		}
+	}
+
+	for _, input := range []string{
+		"index,value,threshold\n1,2\n",
+		"index,value,threshold\n1,2,x\n",
+	} {
+		_, err := readCSVShares(strings.NewReader(input))
+		if err == nil {
+			t.Errorf("expected error for %q, got nil", input)
+		}
+	}
+}
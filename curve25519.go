@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// curve25519KeySize is the size of a curve25519 private key in bytes.
+const curve25519KeySize = 32
+
+// clampCurve25519 applies the curve25519 clamping to key: the three lowest bits are cleared, the highest bit is
+// cleared and the second highest bit is set.
+func clampCurve25519(key []byte) {
+	key[0] &= 248
+	key[31] &= 127
+	key[31] |= 64
+}
+
+// readCurve25519Key reads a curve25519 private key from the named file and returns it clamped as a secret.
+func readCurve25519Key(name string) (*big.Int, error) {
+	key, err := readCurve25519File(name)
+	if err != nil {
+		return nil, err
+	}
+
+	clampCurve25519(key)
+
+	return curve25519Secret(key), nil
+}
+
+// readCurve25519File reads a curve25519 key from the named file. The file holds either the raw 32 bytes of the key or
+// its base64 encoding, like WireGuard keys.
+func readCurve25519File(name string) ([]byte, error) {
+	fh, err := openInput(name)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+
+	buf, err := io.ReadAll(fh)
+	if err != nil {
+		return nil, err
+	}
+
+	key := buf
+	if len(key) != curve25519KeySize {
+		key, err = base64.StdEncoding.DecodeString(string(bytes.TrimSpace(buf)))
+		if err != nil {
+			return nil, fmt.Errorf("%s: neither a raw nor a base64 encoded key: %w", name, err)
+		}
+	}
+
+	if len(key) != curve25519KeySize {
+		return nil, fmt.Errorf("%s: want a %d byte key, have %d bytes", name, curve25519KeySize, len(key))
+	}
+
+	return key, nil
+}
+
+// curve25519Secret converts a clamped key to a secret. Keys are little endian numbers, and because of the clamping the
+// secret always has exactly 255 bits.
+func curve25519Secret(key []byte) *big.Int {
+	be := make([]byte, len(key))
+	for i, b := range key {
+		be[len(key)-1-i] = b
+	}
+
+	return new(big.Int).SetBytes(be)
+}
+
+// curve25519Key converts a recovered secret back to a key. It returns an error if the secret is not a clamped key,
+// which happens if the secret was recovered from the wrong shares.
+func curve25519Key(secret *big.Int) ([]byte, error) {
+	if secret.Sign() < 0 || secret.BitLen() > curve25519KeySize*8 {
+		return nil, errors.New("recovered secret is not a curve25519 key")
+	}
+
+	be := secret.FillBytes(make([]byte, curve25519KeySize))
+
+	key := make([]byte, curve25519KeySize)
+	for i, b := range be {
+		key[curve25519KeySize-1-i] = b
+	}
+
+	clamped := append([]byte(nil), key...)
+	clampCurve25519(clamped)
+
+	if !bytes.Equal(key, clamped) {
+		return nil, errors.New("recovered secret is not a clamped curve25519 key")
+	}
+
+	return key, nil
+}
+
+// encodeCurve25519Key formats a secret as a base64 encoded curve25519 private key.
+func encodeCurve25519Key(secret *big.Int) (string, error) {
+	key, err := curve25519Key(secret)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(key), nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"encoding/base64"
+	"encoding/hex"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCurve25519_roundtrip(t *testing.T) {
+	// Alice's private key from RFC 7748, section 6.1.
+	raw, _ := hex.DecodeString("77076d0a7318a57d3c16c17251b26645df4c2f87ebc0992ab177fba51db92c2a")
+	wantPublic := "8520f0098930a754748b7ddcb43ef75a0dbf3a0d26381af4eba4a98eaa9b4e6a"
+
+	clamped, _ := hex.DecodeString("70076d0a7318a57d3c16c17251b26645df4c2f87ebc0992ab177fba51db92c6a")
+
+	for desc, content := range map[string][]byte{
+		"raw":    raw,
+		"base64": []byte(base64.StdEncoding.EncodeToString(raw) + "\n"),
+	} {
+		t.Run(desc, func(t *testing.T) {
+			keyFile := filepath.Join(t.TempDir(), "key")
+
+			err := os.WriteFile(keyFile, content, 0600)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			secret, err := readCurve25519Key(keyFile)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			var (
+				buf    bytes.Buffer
+				errBuf bytes.Buffer
+				outBuf bytes.Buffer
+			)
+
+			err = cmdGenerate(5, 3, generateOptions{secret: secret, encodeSecret: encodeCurve25519Key}, &buf)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			wantKey := base64.StdEncoding.EncodeToString(clamped)
+			if !strings.HasPrefix(buf.String(), "secret: "+wantKey+"\n") {
+				t.Errorf("unexpected secret line: %q", buf.String())
+			}
+
+			err = cmdRecover(&buf, recoverOptions{encodeSecret: encodeCurve25519Key}, &errBuf, &outBuf)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if outBuf.String() != wantKey+"\n" {
+				t.Fatalf("unexpected recovered key: want %q, have %q", wantKey, outBuf.String())
+			}
+
+			recovered, _ := base64.StdEncoding.DecodeString(strings.TrimSpace(outBuf.String()))
+			if !bytes.Equal(recovered, clamped) {
+				t.Errorf("unexpected clamped key: want %x, have %x", clamped, recovered)
+			}
+
+			priv, err := ecdh.X25519().NewPrivateKey(recovered)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if have := hex.EncodeToString(priv.PublicKey().Bytes()); have != wantPublic {
+				t.Errorf("unexpected public key: want %s, have %s", wantPublic, have)
+			}
+		})
+	}
+}
+
+func TestCurve25519_notAKey(t *testing.T) {
+	_, err := encodeCurve25519Key(big.NewInt(12345))
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestCurve25519_invalidFile(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "key")
+
+	err := os.WriteFile(keyFile, []byte("too short"), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, err = readCurve25519Key(keyFile)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/farhaven/secret/shamir"
+	"github.com/fsnotify/fsnotify"
+	"github.com/posener/sharedsecret"
+)
+
+// daemon waits for share files to arrive in a directory and recovers the secret once k shares are present. The
+// directory is watched for changes, and additionally scanned periodically in case watching is not possible.
+type daemon struct {
+	dir       string
+	k         int
+	poll      time.Duration // How often dir is scanned for new shares.
+	heartbeat time.Duration // How often a heartbeat is written to the audit log. Zero disables heartbeats.
+	settle    time.Duration // Share files modified more recently than this may still be written and are read later.
+	audit     io.Writer
+
+	// minShareAge is the minimum time between the submissions of two accepted shares. Shares submitted too quickly
+	// after the previous one are rejected, so that a single actor can not submit k shares in rapid succession. The
+	// submission time of a share is the time the daemon first saw its file, since file times are set by the submitter.
+	minShareAge time.Duration
+
+	now func() time.Time // Clock used for submission times.
+
+	// onQuorum, if set, is called with the recovered secret instead of writing it out. If it returns false, the daemon
+	// keeps watching and calls it again once more shares arrive.
+	onQuorum quorumHandler
+
+	// verifyKey is the public key the shares must be signed with if set.
+	verifyKey ed25519.PublicKey
+
+	// ceremonyID is the ceremony the shares must belong to. If it is empty, the shares of the ceremony most shares
+	// belong to are used.
+	ceremonyID string
+
+	shares        map[string]ceremonyShare
+	seen          map[string]sighting  // When the share files were first seen, by index.
+	rejected      map[string]time.Time // Modification times of rejected or unreadable share files, to only report them once.
+	lastShare     time.Time            // Arrival time of the last accepted share.
+	lastSubmitted time.Time            // Submission time of the last accepted share.
+	handled       int                  // Number of shares at the last call of onQuorum.
+	scanErr       string               // Last error reading the directory, to only report it once.
+}
+
+func newDaemon(dir string, k int, audit io.Writer) *daemon {
+	return &daemon{
+		dir:      dir,
+		k:        k,
+		poll:     time.Second,
+		settle:   100 * time.Millisecond,
+		audit:    audit,
+		now:      time.Now,
+		shares:   make(map[string]ceremonyShare),
+		seen:     make(map[string]sighting),
+		rejected: make(map[string]time.Time),
+	}
+}
+
+// sighting records when the daemon first saw a version of a share file.
+type sighting struct {
+	modified time.Time // Modification time of the file when it was seen.
+	at       time.Time // Time the daemon first saw the file with this modification time.
+}
+
+// auditf writes a timestamped entry to the audit log.
+func (d *daemon) auditf(format string, args ...interface{}) {
+	fmt.Fprintf(d.audit, "%s %s\n", time.Now().UTC().Format(time.RFC3339Nano), fmt.Sprintf(format, args...))
+}
+
+// run waits until k shares are present and writes the recovered secret to out. It returns the error of ctx if ctx is
+// done before that.
+func (d *daemon) run(ctx context.Context, diag, out io.Writer) error {
+	d.lastShare = time.Now()
+	d.auditf("started dir=%s k=%d", d.dir, d.k)
+
+	poll := time.NewTicker(d.poll)
+	defer poll.Stop()
+
+	var (
+		events      <-chan fsnotify.Event
+		watchErrors <-chan error
+		settled     <-chan time.Time
+	)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err == nil {
+		defer watcher.Close()
+
+		err = watcher.Add(d.dir)
+		if err == nil {
+			events = watcher.Events
+			watchErrors = watcher.Errors
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintf(diag, "watching %s: %s, scanning every %s instead\n", d.dir, err, d.poll)
+	}
+
+	var heartbeat <-chan time.Time
+
+	if d.heartbeat > 0 {
+		t := time.NewTicker(d.heartbeat)
+		defer t.Stop()
+
+		heartbeat = t.C
+	}
+
+	for {
+		d.scan(diag)
+
+		if len(d.ceremonyShares(io.Discard)) >= d.k && len(d.shares) > d.handled {
+			done, err := d.recover(diag, out)
+			if done || err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			d.auditf("stopped shares=%d", len(d.shares))
+			return ctx.Err()
+		case <-heartbeat:
+			d.auditf("heartbeat shares=%d since_last_share=%s load=%s", len(d.shares), time.Since(d.lastShare).Round(time.Millisecond), systemLoad())
+		case <-events:
+			// Read the share once its file is completely written.
+			settled = time.After(d.settle)
+		case err := <-watchErrors:
+			fmt.Fprintf(diag, "watching %s: %s\n", d.dir, err)
+		case <-settled:
+		case <-poll.C:
+		}
+	}
+}
+
+// scan reads new shares from the share files in the directory. A share is submitted when the daemon first sees its file.
+func (d *daemon) scan(diag io.Writer) {
+	files, err := readShareDir(d.dir)
+	if err != nil {
+		// Without share files no shares have arrived yet. Errors reading the directory or the files are reported.
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) && err.Error() != d.scanErr {
+			fmt.Fprintf(diag, "scanning shares: %s\n", err)
+		}
+
+		d.scanErr = err.Error()
+
+		return
+	}
+
+	d.scanErr = ""
+
+	type submission struct {
+		index     string
+		modified  time.Time
+		submitted time.Time
+	}
+
+	var pending []submission
+
+	for index := range files {
+		if _, ok := d.shares[index]; ok {
+			continue
+		}
+
+		info, err := os.Stat(filepath.Join(d.dir, "share-"+index+".txt"))
+		if err != nil {
+			continue
+		}
+
+		if rejected, ok := d.rejected[index]; ok && rejected.Equal(info.ModTime()) {
+			continue
+		}
+
+		if time.Since(info.ModTime()) < d.settle {
+			continue
+		}
+
+		seen, ok := d.seen[index]
+		if !ok || !seen.modified.Equal(info.ModTime()) {
+			seen = sighting{modified: info.ModTime(), at: d.now()}
+			d.seen[index] = seen
+		}
+
+		pending = append(pending, submission{index, info.ModTime(), seen.at})
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		if !pending[i].submitted.Equal(pending[j].submitted) {
+			return pending[i].submitted.Before(pending[j].submitted)
+		}
+
+		return pending[i].index < pending[j].index
+	})
+
+	for _, p := range pending {
+		_, line := splitShareLabel(strings.TrimSpace(string(files[p.index])))
+
+		unsigned, err := stripSignature(d.verifyKey, line)
+		if err != nil {
+			d.rejected[p.index] = p.modified
+			fmt.Fprintf(diag, "reading share %q: %s\n", line, err)
+			continue
+		}
+
+		id, line := splitCeremonyID(unsigned)
+
+		share, err := parseShare(line)
+		if err != nil {
+			d.rejected[p.index] = p.modified
+			fmt.Fprintf(diag, "reading share %q: %s\n", line, err)
+			continue
+		}
+
+		if err := shamir.CheckShare(share); err != nil {
+			d.rejected[p.index] = p.modified
+			fmt.Fprintf(diag, "reading share %q: %s\n", line, err)
+			continue
+		}
+
+		if d.ceremonyID != "" && id != d.ceremonyID {
+			d.rejected[p.index] = p.modified
+			fmt.Fprintf(diag, "rejecting share index=%s: ceremony ID %q does not match %q\n", p.index, id, d.ceremonyID)
+			d.auditf("share rejected index=%s ceremony_id=%q", p.index, id)
+			continue
+		}
+
+		if d.minShareAge > 0 && !d.lastSubmitted.IsZero() {
+			if age := p.submitted.Sub(d.lastSubmitted); age < d.minShareAge {
+				d.rejected[p.index] = p.modified
+				fmt.Fprintf(diag, "rejecting share index=%s: submitted %s after the previous share, need at least %s\n", p.index, age, d.minShareAge)
+				d.auditf("share rejected index=%s since_previous=%s", p.index, age)
+				continue
+			}
+		}
+
+		d.shares[p.index] = ceremonyShare{line: unsigned, id: id, share: share}
+		d.lastShare = time.Now()
+		d.lastSubmitted = p.submitted
+		d.auditf("share received index=%s shares=%d", shareIndex(share), len(d.shares))
+	}
+}
+
+// ceremonyShares returns the received shares of the ceremony of the daemon, without duplicates. Share files are named
+// by the submitter, so two files can hold the same share. Shares of other ceremonies and duplicate shares are reported
+// to diag.
+func (d *daemon) ceremonyShares(diag io.Writer) []sharedsecret.Share {
+	indices := make([]string, 0, len(d.shares))
+	for index := range d.shares {
+		indices = append(indices, index)
+	}
+
+	sort.Strings(indices)
+
+	received := make([]ceremonyShare, len(indices))
+	for i, index := range indices {
+		received[i] = d.shares[index]
+	}
+
+	_, shares, err := selectCeremony(d.ceremonyID, received, diag)
+	if err != nil {
+		// Wait for more shares to tell which ceremony the majority belongs to.
+		return nil
+	}
+
+	shares, err = shamir.Dedupe(shares, diag)
+	if err != nil {
+		fmt.Fprintln(diag, err)
+		return nil
+	}
+
+	return shares
+}
+
+// recover recovers the secret from the received shares and hands it to onQuorum, or writes it to out if there is no
+// handler. It returns whether the daemon is done.
+func (d *daemon) recover(diag, out io.Writer) (bool, error) {
+	shares := d.ceremonyShares(diag)
+
+	secret, err := shamir.Combine(shares)
+	if err != nil {
+		// Wait for more shares instead of trying the same shares again.
+		d.handled = len(d.shares)
+
+		fmt.Fprintf(diag, "recovering from %d shares: %s, waiting for more shares\n", len(shares), err)
+		d.auditf("quorum failed shares=%d", len(shares))
+
+		return false, nil
+	}
+
+	d.auditf("quorum reached shares=%d", len(shares))
+
+	encoded, err := encodeSecret(nil, secret)
+	if err != nil {
+		return false, err
+	}
+
+	if d.onQuorum == nil {
+		fmt.Fprintln(out, encoded)
+		return true, nil
+	}
+
+	d.handled = len(shares)
+
+	done, err := d.onQuorum(encoded)
+	if err != nil {
+		d.auditf("quorum handler failed shares=%d", len(shares))
+		return false, err
+	}
+
+	if !done {
+		fmt.Fprintf(diag, "quorum handler did not accept the secret recovered from %d shares, waiting for more shares\n", len(shares))
+		d.auditf("quorum handler declined shares=%d", len(shares))
+		return false, nil
+	}
+
+	d.auditf("quorum handler accepted shares=%d", len(shares))
+
+	return true, nil
+}
+
+// systemLoad returns the load averages of the system, or "unknown" where they are not available.
+func systemLoad() string {
+	buf, err := os.ReadFile("/proc/loadavg")
+	if err != nil {
+		return "unknown"
+	}
+
+	fields := strings.Fields(string(buf))
+	if len(fields) < 3 {
+		return "unknown"
+	}
+
+	return strings.Join(fields[:3], ",")
+}
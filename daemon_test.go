@@ -0,0 +1,337 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDaemon_heartbeat(t *testing.T) {
+	var audit bytes.Buffer
+
+	d := newDaemon(t.TempDir(), 3, &audit)
+	d.poll = 10 * time.Millisecond
+	d.heartbeat = 50 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	err := d.run(ctx, &bytes.Buffer{}, &bytes.Buffer{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if n := strings.Count(audit.String(), " heartbeat shares=0 "); n < 3 {
+		t.Errorf("want at least 3 heartbeats, have %d: %q", n, audit.String())
+	}
+}
+
+func TestDaemon_quorum(t *testing.T) {
+	dir := t.TempDir()
+	writeShareFiles(t, dir,
+		"1,19943338053965968504353533017903769217",
+		"2,161872477868088873785792630750634181303",
+	)
+
+	var (
+		audit  bytes.Buffer
+		outBuf bytes.Buffer
+	)
+
+	d := newDaemon(dir, 3, &audit)
+	d.poll = 10 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+
+		err := os.WriteFile(filepath.Join(dir, "share-5.txt"), []byte("5,160274174127002500413544256698187925606\n"), 0600)
+		if err != nil {
+			t.Error(err)
+		}
+	}()
+
+	err := d.run(ctx, &bytes.Buffer{}, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	wantSecret := "7uPIBqGKMPpProBYFFR3S\n"
+	if outBuf.String() != wantSecret {
+		t.Errorf("unexpected secret. want %q, have %q", wantSecret, outBuf.String())
+	}
+
+	if n := strings.Count(audit.String(), " share received "); n != 3 {
+		t.Errorf("want 3 received shares in audit log, have %d: %q", n, audit.String())
+	}
+}
+
+// writeShareAt writes a share file with the given submission time.
+func writeShareAt(t *testing.T, dir, index, share string, submitted time.Time) {
+	t.Helper()
+
+	name := filepath.Join(dir, "share-"+index+".txt")
+
+	err := os.WriteFile(name, []byte(share+"\n"), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err = os.Chtimes(name, submitted, submitted)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestDaemon_minimumShareAge(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		spread   time.Duration
+		received int
+	}{
+		{"too quickly", time.Minute, 2},
+		{"correct interval", 10 * time.Minute, 3},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				audit  bytes.Buffer
+				errBuf bytes.Buffer
+			)
+
+			dir := t.TempDir()
+			now := time.Now()
+
+			d := newDaemon(dir, 3, &audit)
+			d.minShareAge = 5 * time.Minute
+			d.now = func() time.Time { return now }
+
+			// File times are set by the submitter and must not matter.
+			past := now.Add(-time.Hour)
+
+			writeShareAt(t, dir, "1", "1,19943338053965968504353533017903769217", past)
+			d.scan(&errBuf)
+
+			now = now.Add(tc.spread)
+			writeShareAt(t, dir, "2", "2,161872477868088873785792630750634181303", past.Add(30*time.Minute))
+			d.scan(&errBuf)
+
+			now = now.Add(20 * time.Minute)
+			writeShareAt(t, dir, "5", "5,160274174127002500413544256698187925606", past.Add(-time.Hour))
+			d.scan(&errBuf)
+
+			if len(d.shares) != tc.received {
+				t.Fatalf("want %d received shares, have %d: %q", tc.received, len(d.shares), audit.String())
+			}
+
+			if tc.received == 3 {
+				if errBuf.Len() != 0 {
+					t.Errorf("unexpected diagnostic: %q", errBuf.String())
+				}
+
+				return
+			}
+
+			wantDiag := "rejecting share index=2: submitted 1m0s after the previous share, need at least 5m0s\n"
+			if errBuf.String() != wantDiag {
+				t.Errorf("unexpected diagnostic. want %q, have %q", wantDiag, errBuf.String())
+			}
+
+			// A rejected share is only reported once.
+			d.scan(&errBuf)
+
+			if errBuf.String() != wantDiag {
+				t.Errorf("unexpected diagnostic after second scan: %q", errBuf.String())
+			}
+		})
+	}
+}
+
+func TestDaemon_minimumShareAgeBackdated(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now().Add(-time.Hour)
+
+	// Shares that appear at the same time are rejected even if their file times are far apart.
+	writeShareAt(t, dir, "1", "1,19943338053965968504353533017903769217", start)
+	writeShareAt(t, dir, "2", "2,161872477868088873785792630750634181303", start.Add(20*time.Minute))
+
+	var errBuf bytes.Buffer
+
+	d := newDaemon(dir, 2, io.Discard)
+	d.minShareAge = 5 * time.Minute
+	d.scan(&errBuf)
+
+	if len(d.shares) != 1 {
+		t.Fatalf("want 1 received share, have %d: %q", len(d.shares), errBuf.String())
+	}
+}
+
+func TestDaemon_verifyKey(t *testing.T) {
+	key := newSigningKey(t)
+
+	dir := t.TempDir()
+	writeShareFiles(t, dir,
+		signShare(key, "1,19943338053965968504353533017903769217"),
+		signShare(newSigningKey(t), "2,161872477868088873785792630750634181303"),
+	)
+
+	var errBuf bytes.Buffer
+
+	d := newDaemon(dir, 3, &bytes.Buffer{})
+	d.settle = 0
+	d.verifyKey = key.Public().(ed25519.PublicKey)
+	d.scan(&errBuf)
+
+	if len(d.shares) != 1 {
+		t.Errorf("want 1 received share, have %d", len(d.shares))
+	}
+
+	if !strings.Contains(errBuf.String(), "invalid signature") {
+		t.Errorf("unexpected diagnostic: %q", errBuf.String())
+	}
+}
+
+func TestDaemon_ceremonyID(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		ceremonyID string
+		received   int
+		quorum     int
+	}{
+		{"majority", "", 4, 3},
+		{"selected", "2026-10", 3, 3},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			submitted := time.Now().Add(-time.Minute)
+			writeShareAt(t, dir, "3", "id:2025-04:3,42", submitted)
+			writeShareAt(t, dir, "1", "id:2026-10:1,19943338053965968504353533017903769217", submitted)
+			writeShareAt(t, dir, "2", "id:2026-10:2,161872477868088873785792630750634181303", submitted)
+			writeShareAt(t, dir, "5", "id:2026-10:5,160274174127002500413544256698187925606", submitted)
+
+			var (
+				errBuf bytes.Buffer
+				outBuf bytes.Buffer
+			)
+
+			d := newDaemon(dir, 3, &bytes.Buffer{})
+			d.settle = 0
+			d.ceremonyID = tc.ceremonyID
+			d.scan(&errBuf)
+
+			if len(d.shares) != tc.received {
+				t.Errorf("want %d received shares, have %d", tc.received, len(d.shares))
+			}
+
+			if n := len(d.ceremonyShares(io.Discard)); n != tc.quorum {
+				t.Errorf("want %d shares of the ceremony, have %d", tc.quorum, n)
+			}
+
+			_, err := d.recover(&errBuf, &outBuf)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if outBuf.String() != "7uPIBqGKMPpProBYFFR3S\n" {
+				t.Errorf("unexpected secret: %q", outBuf.String())
+			}
+
+			if !strings.Contains(errBuf.String(), `"2025-04" does not match "2026-10"`) {
+				t.Errorf("unexpected diagnostic: %q", errBuf.String())
+			}
+		})
+	}
+}
+
+func TestDaemon_invalidShareReportedOnce(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now().Add(-time.Hour)
+
+	writeShareAt(t, dir, "1", "1,foo", start)
+
+	var errBuf bytes.Buffer
+
+	d := newDaemon(dir, 2, io.Discard)
+	d.scan(&errBuf)
+	d.scan(&errBuf)
+
+	if strings.Count(errBuf.String(), "reading share") != 1 {
+		t.Fatalf("want one diagnostic, have %q", errBuf.String())
+	}
+
+	// A rewritten share file is read again.
+	writeShareAt(t, dir, "1", "1,19943338053965968504353533017903769217", start.Add(time.Minute))
+	d.scan(&errBuf)
+
+	if len(d.shares) != 1 {
+		t.Fatalf("want 1 received share, have %d: %q", len(d.shares), errBuf.String())
+	}
+}
+
+func TestDaemon_duplicateShareFiles(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now().Add(-time.Hour)
+
+	writeShareAt(t, dir, "1", "1,5", start)
+	writeShareAt(t, dir, "01", "1,5", start)
+	writeShareAt(t, dir, "001", "170141183460469231731687303715884105728,6", start)
+
+	var (
+		audit  bytes.Buffer
+		errBuf bytes.Buffer
+		outBuf bytes.Buffer
+	)
+
+	d := newDaemon(dir, 2, &audit)
+	d.poll = 10 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	err := d.run(ctx, &errBuf, &outBuf)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if outBuf.Len() != 0 {
+		t.Errorf("unexpected output: %q", outBuf.String())
+	}
+
+	if strings.Contains(audit.String(), "quorum reached") {
+		t.Errorf("unexpected quorum: %q", audit.String())
+	}
+
+	if !strings.Contains(errBuf.String(), "invalid index 170141183460469231731687303715884105728") {
+		t.Errorf("unexpected diagnostic: %q", errBuf.String())
+	}
+}
+
+func TestDaemon_unreadableDirReportedOnce(t *testing.T) {
+	var errBuf bytes.Buffer
+
+	d := newDaemon(filepath.Join(t.TempDir(), "missing"), 2, io.Discard)
+	d.scan(&errBuf)
+	d.scan(&errBuf)
+
+	if strings.Count(errBuf.String(), "scanning shares: ") != 1 {
+		t.Fatalf("want one diagnostic, have %q", errBuf.String())
+	}
+
+	// An empty directory is not an error, no shares have arrived yet.
+	errBuf.Reset()
+
+	d = newDaemon(t.TempDir(), 2, io.Discard)
+	d.scan(&errBuf)
+
+	if errBuf.Len() != 0 {
+		t.Errorf("unexpected diagnostic: %q", errBuf.String())
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/farhaven/secret/shamir"
+	"github.com/posener/sharedsecret"
+)
+
+// generateDecoyShares shares secret and decoy with the same k and selects n shares of both at the same indices, so
+// that the i-th real share and the i-th decoy share are indistinguishable. A random secret is generated if secret is
+// nil.
+func generateDecoyShares(n, k int, secret, decoy *big.Int, pool int64) (real, decoys []sharedsecret.Share, _ *big.Int, err error) {
+	if secret == nil {
+		secret, err = rand.Int(rand.Reader, shamir.Prime)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	allReal, err := shamir.Distribute(secret, pool, int64(k))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	allDecoys, err := shamir.Distribute(decoy, pool, int64(k))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	indices, err := shamir.Perm(int(pool))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	for _, i := range indices[:n] {
+		real = append(real, allReal[i])
+		decoys = append(decoys, allDecoys[i])
+	}
+
+	return real, decoys, secret, nil
+}
+
+// cmdGenerateDecoy generates n shares of a random secret and n decoy shares of decoy at the same indices. Custodian i
+// holds the i-th share of both sets and reveals the decoy share under duress: k decoy shares recover the decoy
+// secret. Both sets carry the ceremony ID if it is set.
+func cmdGenerateDecoy(n, k int, decoy *big.Int, ceremonyID string, out io.Writer) error {
+	if k > n {
+		return errors.New("There will not be enough shares to recover the secret.")
+	}
+
+	if n < 1 || k < 1 {
+		return errors.New("Number of shares must be larger than 1.")
+	}
+
+	err := validateCeremonyID(ceremonyID)
+	if err != nil {
+		return err
+	}
+
+	real, decoys, secret, err := generateDecoyShares(n, k, nil, decoy, shamir.PoolSize(n))
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out, "secret:", secret.Text(62))
+
+	fmt.Fprintf(out, "shares (need at least %d of these for recovery):\n", k)
+	for _, share := range real {
+		fmt.Fprintln(out, withCeremonyID(ceremonyID, share.String()))
+	}
+
+	fmt.Fprintf(out, "shares to reveal under duress (need at least %d of these to recover the decoy):\n", k)
+	for _, share := range decoys {
+		fmt.Fprintln(out, withCeremonyID(ceremonyID, share.String()))
+	}
+
+	return nil
+}
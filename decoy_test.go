@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestGenerateDecoy(t *testing.T) {
+	decoy, _ := new(big.Int).SetString("decoy", 62)
+
+	var buf bytes.Buffer
+
+	err := cmdGenerateDecoy(5, 3, decoy, "2026-10", &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 13 {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+
+	secret := strings.TrimPrefix(lines[0], "secret: ")
+	real, decoys := lines[2:7], lines[8:13]
+
+	for i := range real {
+		if !strings.HasPrefix(real[i], "id:2026-10:") || !strings.HasPrefix(decoys[i], "id:2026-10:") {
+			t.Errorf("share %d does not carry the ceremony ID: %q, %q", i, real[i], decoys[i])
+		}
+
+		realIndex, _, _ := strings.Cut(real[i], ",")
+		decoyIndex, _, _ := strings.Cut(decoys[i], ",")
+
+		if realIndex != decoyIndex {
+			t.Errorf("share %d has index %s, its decoy has index %s", i, realIndex, decoyIndex)
+		}
+	}
+
+	for _, tc := range []struct {
+		name   string
+		shares []string
+		want   string
+	}{
+		{"real shares", real[1:4], secret},
+		{"decoy shares", decoys[:3], "decoy"},
+		{"other decoy shares", decoys[2:], "decoy"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				errBuf bytes.Buffer
+				outBuf bytes.Buffer
+			)
+
+			err := cmdRecover(strings.NewReader(strings.Join(tc.shares, "\n")), recoverOptions{}, &errBuf, &outBuf)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if errBuf.Len() != 0 {
+				t.Errorf("unexpected diagnostic: %q", errBuf.String())
+			}
+
+			if outBuf.String() != tc.want+"\n" {
+				t.Errorf("unexpected recovered secret. want %q, have %q", tc.want, outBuf.String())
+			}
+		})
+	}
+}
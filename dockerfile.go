@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// dockerfileTemplate builds this program from its source and packages it with the shares into a scratch image that
+// serves each share once over TLS. The TLS certificate and key are mounted at runtime, so that the private key is not
+// stored in a layer of the image.
+var dockerfileTemplate = template.Must(template.New("Dockerfile").Parse(`# Share distribution container for {{ .Count }} shares. Build with the source directory of secret, which contains
+# {{ .SharesDir }}/, as the build context. Mount the directory holding cert.pem and key.pem at /tls when running it:
+#   docker run -v "$PWD/tls:/tls:ro" -p 8443:8443 <image>
+FROM golang:1.26 AS build
+WORKDIR /src
+COPY . .
+RUN CGO_ENABLED=0 go build -o /secret .
+
+FROM scratch
+COPY --from=build /secret /secret
+COPY {{ .SharesDir }}/ /shares/
+VOLUME /tls
+EXPOSE 8443
+HEALTHCHECK --interval=30s --timeout=5s --retries=3 CMD ["/secret", "-mode", "health-check", "-health-url", "https://localhost:8443/healthz"]
+CMD ["/secret", "-mode", "serve-shares", "-shares-dir", "/shares", "-addr", ":8443", "-tls-cert", "/tls/cert.pem", "-tls-key", "/tls/key.pem"]
+`))
+
+// cmdGenerateDockerfile writes a Dockerfile for distributing the shares in sharesDir to out. sharesDir must be relative
+// to the build context, which is the current directory.
+func cmdGenerateDockerfile(sharesDir string, out io.Writer) error {
+	dir := filepath.Clean(sharesDir)
+	if filepath.IsAbs(dir) || dir == ".." || strings.HasPrefix(dir, ".."+string(filepath.Separator)) {
+		return errors.New("The shares directory must be inside the build context, give it relative to the current directory.")
+	}
+
+	shares, err := readShareDir(dir)
+	if err != nil {
+		return err
+	}
+
+	return dockerfileTemplate.Execute(out, struct {
+		SharesDir string
+		Count     int
+	}{filepath.ToSlash(dir), len(shares)})
+}
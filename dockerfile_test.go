@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeShareFiles(t *testing.T, dir string, shares ...string) {
+	t.Helper()
+
+	for _, share := range shares {
+		index := strings.SplitN(share, ",", 2)[0]
+
+		err := os.WriteFile(filepath.Join(dir, "share-"+index+".txt"), []byte(share+"\n"), 0600)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+}
+
+func TestGenerateDockerfile(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	err := os.Mkdir("shares", 0700)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	writeShareFiles(t, "shares",
+		"1,19943338053965968504353533017903769217",
+		"2,161872477868088873785792630750634181303",
+		"5,160274174127002500413544256698187925606",
+	)
+
+	var buf bytes.Buffer
+
+	err = cmdGenerateDockerfile("./shares/", &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := []string{
+		"RUN CGO_ENABLED=0 go build -o /secret .\n",
+		"FROM scratch\n",
+		"COPY shares/ /shares/\n",
+		"VOLUME /tls\n",
+		"HEALTHCHECK ",
+		`CMD ["/secret", "-mode", "serve-shares", "-shares-dir", "/shares", `,
+	}
+
+	for _, w := range want {
+		if !strings.Contains(buf.String(), w) {
+			t.Errorf("expected Dockerfile to contain %q, have:\n%s", w, buf.String())
+		}
+	}
+
+	for _, w := range []string{"@latest", "COPY tls/"} {
+		if strings.Contains(buf.String(), w) {
+			t.Errorf("expected Dockerfile not to contain %q, have:\n%s", w, buf.String())
+		}
+	}
+}
+
+func TestGenerateDockerfile_outsideContext(t *testing.T) {
+	dir := t.TempDir()
+	writeShareFiles(t, dir, "1,19943338053965968504353533017903769217")
+
+	for _, sharesDir := range []string{dir, "..", "../shares"} {
+		err := cmdGenerateDockerfile(sharesDir, &bytes.Buffer{})
+		if err == nil {
+			t.Errorf("expected an error for %q", sharesDir)
+		}
+	}
+}
+
+func TestGenerateDockerfile_noShares(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	err := cmdGenerateDockerfile(".", &bytes.Buffer{})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/farhaven/secret/shamir"
+)
+
+// drillReport is the result of a recovery drill. It never contains the secret.
+type drillReport struct {
+	Shares         []string `json:"shares"` // Indices of the shares the simulated custodians brought.
+	SelectSeconds  float64  `json:"select_seconds"`
+	RecoverSeconds float64  `json:"recover_seconds"`
+	Succeeded      bool     `json:"succeeded"`
+	Error          string   `json:"error,omitempty"`
+}
+
+// recoveryDrill tests the recovery procedure without a live ceremony. Simulated custodians bring k randomly selected
+// share files from dir, and the secret is recovered from them like cmdRecover does. The recovery succeeds if the
+// secret matches the secret recovered from all share files in dir.
+type recoveryDrill struct {
+	dir   string
+	k     int
+	delay time.Duration // The time each simulated custodian takes to read their share.
+	sleep func(time.Duration)
+}
+
+func newRecoveryDrill(dir string, k int, delay time.Duration) *recoveryDrill {
+	return &recoveryDrill{dir: dir, k: k, delay: delay, sleep: time.Sleep}
+}
+
+// run runs the drill. Problems with the recovery are reported in the drill report, the error is only set if the drill
+// could not be run at all.
+func (d *recoveryDrill) run() (drillReport, error) {
+	var report drillReport
+
+	files, err := readShareDir(d.dir)
+	if err != nil {
+		return report, err
+	}
+
+	if d.k < 1 || d.k > len(files) {
+		return report, fmt.Errorf("Can not select %d of the %d share files in %s.", d.k, len(files), d.dir)
+	}
+
+	indices := make([]string, 0, len(files))
+	for index := range files {
+		indices = append(indices, index)
+	}
+
+	sort.Strings(indices)
+
+	start := time.Now()
+
+	order, err := shamir.Perm(len(indices))
+	if err != nil {
+		return report, err
+	}
+
+	var selected bytes.Buffer
+
+	for _, i := range order[:d.k] {
+		d.sleep(d.delay)
+
+		report.Shares = append(report.Shares, indices[i])
+		selected.Write(files[indices[i]])
+		selected.WriteString("\n")
+	}
+
+	sort.Strings(report.Shares)
+
+	report.SelectSeconds = time.Since(start).Seconds()
+
+	start = time.Now()
+
+	var have bytes.Buffer
+
+	err = cmdRecover(&selected, recoverOptions{}, io.Discard, &have)
+
+	report.RecoverSeconds = time.Since(start).Seconds()
+
+	if err != nil {
+		report.Error = err.Error()
+		return report, nil
+	}
+
+	var all bytes.Buffer
+	for _, index := range indices {
+		all.Write(files[index])
+		all.WriteString("\n")
+	}
+
+	var want bytes.Buffer
+
+	err = cmdRecover(&all, recoverOptions{}, io.Discard, &want)
+	if err != nil {
+		report.Error = fmt.Sprintf("recovering from all shares: %s", err)
+		return report, nil
+	}
+
+	report.Succeeded = have.String() == want.String()
+	if !report.Succeeded {
+		report.Error = "the selected shares recover a different secret than all shares"
+	}
+
+	return report, nil
+}
+
+// cmdRecoveryDrill runs a recovery drill, writes a summary to out and the JSON report to reportFile if it is set.
+func cmdRecoveryDrill(d *recoveryDrill, reportFile string, out io.Writer) error {
+	report, err := d.run()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "selected shares %s in %.3fs\n", strings.Join(report.Shares, ", "), report.SelectSeconds)
+	fmt.Fprintf(out, "recovered in %.3fs\n", report.RecoverSeconds)
+
+	if report.Succeeded {
+		fmt.Fprintln(out, "recovery succeeded")
+	} else {
+		fmt.Fprintf(out, "recovery failed: %s\n", report.Error)
+	}
+
+	if reportFile != "" {
+		buf, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(reportFile, append(buf, '\n'), 0600)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !report.Succeeded {
+		return errors.New("Recovery drill failed.")
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeGeneratedShares generates n shares with threshold k into share files in a new directory.
+func writeGeneratedShares(t *testing.T, n, k int) string {
+	t.Helper()
+
+	var genBuf bytes.Buffer
+
+	err := cmdGenerate(n, k, generateOptions{}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(genBuf.String()), "\n")
+
+	dir := t.TempDir()
+	writeShareFiles(t, dir, lines[2:]...)
+
+	return dir
+}
+
+func TestRecoveryDrill(t *testing.T) {
+	dir := writeGeneratedShares(t, 5, 3)
+	report := filepath.Join(t.TempDir(), "report.json")
+
+	var slept []time.Duration
+
+	d := newRecoveryDrill(dir, 3, time.Second)
+	d.sleep = func(d time.Duration) { slept = append(slept, d) }
+
+	var outBuf bytes.Buffer
+
+	err := cmdRecoveryDrill(d, report, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(slept) != 3 || slept[0] != time.Second {
+		t.Errorf("unexpected simulated reading delays %v", slept)
+	}
+
+	if !strings.Contains(outBuf.String(), "recovery succeeded") {
+		t.Errorf("unexpected output %q", outBuf.String())
+	}
+
+	buf, err := os.ReadFile(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var have drillReport
+
+	err = json.Unmarshal(buf, &have)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !have.Succeeded || len(have.Shares) != 3 || have.Error != "" {
+		t.Errorf("unexpected report %+v", have)
+	}
+
+	// The shares are only read, never changed.
+	files, err := readShareDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(files) != 5 {
+		t.Errorf("want 5 share files, have %d", len(files))
+	}
+}
+
+func TestRecoveryDrill_tooFewShares(t *testing.T) {
+	// Two shares of a ceremony with a threshold of three recover a wrong secret.
+	dir := writeGeneratedShares(t, 3, 3)
+	report := filepath.Join(t.TempDir(), "report.json")
+
+	var outBuf bytes.Buffer
+
+	err := cmdRecoveryDrill(newRecoveryDrill(dir, 2, 0), report, &outBuf)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	if !strings.Contains(outBuf.String(), "recovery failed") {
+		t.Errorf("unexpected output %q", outBuf.String())
+	}
+
+	buf, err := os.ReadFile(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !strings.Contains(string(buf), `"succeeded": false`) {
+		t.Errorf("unexpected report %s", buf)
+	}
+}
+
+func TestRecoveryDrill_invalidK(t *testing.T) {
+	dir := writeGeneratedShares(t, 3, 2)
+
+	for _, k := range []int{0, 4} {
+		_, err := newRecoveryDrill(dir, k, 0).run()
+		if err == nil {
+			t.Errorf("expected error for k=%d, got nil", k)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+// recipientHeader is the output header that carries the public key a wrapped secret is encrypted to.
+const recipientHeader = "recipient"
+
+const (
+	envelopeSecretSize = 32
+	envelopeNonceSize  = 12
+	envelopeTagSize    = 16
+
+	// envelopeSize is the size of a wrapped secret: the ephemeral public key, the nonce and the sealed secret.
+	envelopeSize = curve25519.PointSize + envelopeNonceSize + envelopeSecretSize + envelopeTagSize
+)
+
+// envelopeKey derives the AES-256 key for an envelope from the X25519 shared secret.
+func envelopeKey(shared, ephemeral, recipient []byte) ([]byte, error) {
+	salt := append(append([]byte(nil), ephemeral...), recipient...)
+
+	key := make([]byte, 32)
+
+	_, err := io.ReadFull(hkdf.New(sha256.New, shared, salt, []byte("github.com/farhaven/secret envelope")), key)
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// wrapSecret encrypts secret to the X25519 public key recipient with an ephemeral key and AES-256-GCM.
+func wrapSecret(secret, recipient []byte) ([]byte, error) {
+	ephemeralKey := make([]byte, curve25519.ScalarSize)
+
+	_, err := rand.Read(ephemeralKey)
+	if err != nil {
+		return nil, err
+	}
+
+	ephemeral, err := curve25519.X25519(ephemeralKey, curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+
+	shared, err := curve25519.X25519(ephemeralKey, recipient)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recipient key: %w", err)
+	}
+
+	key, err := envelopeKey(shared, ephemeral, recipient)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	envelope := append(make([]byte, 0, envelopeSize), ephemeral...)
+	envelope = envelope[:curve25519.PointSize+envelopeNonceSize]
+
+	_, err = rand.Read(envelope[curve25519.PointSize:])
+	if err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(envelope, envelope[curve25519.PointSize:], secret, ephemeral), nil
+}
+
+// unwrapSecret decrypts an envelope created by wrapSecret with the X25519 private key of the recipient.
+func unwrapSecret(envelope, privateKey []byte) ([]byte, error) {
+	if len(envelope) != envelopeSize {
+		return nil, errors.New("Recovered secret is not a wrapped secret.")
+	}
+
+	recipient, err := curve25519.X25519(privateKey, curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+
+	ephemeral := envelope[:curve25519.PointSize]
+
+	shared, err := curve25519.X25519(privateKey, ephemeral)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := envelopeKey(shared, ephemeral, recipient)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := envelope[curve25519.PointSize : curve25519.PointSize+envelopeNonceSize]
+
+	secret, err := aead.Open(nil, nonce, envelope[curve25519.PointSize+envelopeNonceSize:], ephemeral)
+	if err != nil {
+		return nil, errors.New("Decrypting the wrapped secret failed.")
+	}
+
+	return secret, nil
+}
+
+// newWrappedSecret generates a random secret and wraps it for recipient. It returns the secret, the wrapped secret,
+// which is split instead of the secret, and the recipient header value.
+func newWrappedSecret(recipient []byte) (secret, wrapped *big.Int, header string, err error) {
+	raw := make([]byte, envelopeSecretSize)
+
+	_, err = rand.Read(raw)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	envelope, err := wrapSecret(raw, recipient)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	return new(big.Int).SetBytes(raw), new(big.Int).SetBytes(envelope), base64.StdEncoding.EncodeToString(recipient), nil
+}
+
+// unwrapRecoveredSecret decrypts a recovered wrapped secret with privateKey. It fails if privateKey does not belong
+// to the recipient from the header.
+func unwrapRecoveredSecret(wrapped *big.Int, privateKey []byte, recipient string) (*big.Int, error) {
+	want, err := base64.StdEncoding.DecodeString(recipient)
+	if err != nil {
+		return nil, fmt.Errorf("decoding recipient: %w", err)
+	}
+
+	have, err := curve25519.X25519(privateKey, curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare(want, have) != 1 {
+		return nil, errors.New("Private key does not belong to the recipient of the wrapped secret.")
+	}
+
+	if wrapped.Sign() < 0 || wrapped.BitLen() > envelopeSize*8 {
+		return nil, errors.New("Recovered secret is not a wrapped secret.")
+	}
+
+	secret, err := unwrapSecret(wrapped.FillBytes(make([]byte, envelopeSize)), privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(secret), nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/curve25519"
+)
+
+func newEnvelopeKey(t *testing.T) (private, public []byte) {
+	t.Helper()
+
+	private = make([]byte, curve25519.ScalarSize)
+
+	_, err := rand.Read(private)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	public, err = curve25519.X25519(private, curve25519.Basepoint)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	return private, public
+}
+
+func TestEnvelope_roundtrip(t *testing.T) {
+	secret := bytes.Repeat([]byte{0x42}, envelopeSecretSize)
+	private, public := newEnvelopeKey(t)
+
+	envelope, err := wrapSecret(secret, public)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(envelope) != envelopeSize {
+		t.Fatalf("want a %d byte envelope, have %d bytes", envelopeSize, len(envelope))
+	}
+
+	unwrapped, err := unwrapSecret(envelope, private)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !bytes.Equal(unwrapped, secret) {
+		t.Errorf("unexpected secret. want %x, have %x", secret, unwrapped)
+	}
+
+	other, _ := newEnvelopeKey(t)
+
+	_, err = unwrapSecret(envelope, other)
+	if err == nil {
+		t.Error("expected an error for the wrong private key")
+	}
+}
+
+func TestWrapSecret_recover(t *testing.T) {
+	private, public := newEnvelopeKey(t)
+	other, _ := newEnvelopeKey(t)
+
+	want, wrapped, recipient, err := newWrappedSecret(public)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var genBuf bytes.Buffer
+
+	opts := generateOptions{secret: wrapped, printSecret: want, header: []string{recipientHeader + ": " + recipient}}
+
+	err = cmdGenerate(5, 3, opts, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// The secret line shows the secret that recovery prints, not the wrapped secret.
+	if !strings.HasPrefix(genBuf.String(), "secret: "+want.Text(62)+"\n") {
+		t.Fatalf("output does not start with the secret: %q", genBuf.String())
+	}
+
+	if !strings.Contains(genBuf.String(), "\nrecipient: "+recipient+"\n") {
+		t.Fatalf("output does not contain the recipient header: %q", genBuf.String())
+	}
+
+	for _, tc := range []struct {
+		name    string
+		key     []byte
+		wantErr bool
+	}{
+		{"private key", private, false},
+		{"no private key", nil, true},
+		{"wrong private key", other, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var outBuf bytes.Buffer
+
+			err := cmdRecover(strings.NewReader(genBuf.String()), recoverOptions{unwrapKey: tc.key}, &bytes.Buffer{}, &outBuf)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, recovered %q", outBuf.String())
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if outBuf.String() != want.Text(62)+"\n" {
+				t.Errorf("unexpected recovered secret. want %q, have %q", want.Text(62), outBuf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.etcd.io/etcd/client/pkg/v3/transport"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// etcdStore stores shares in etcd at <prefix>/share/<index>.
+type etcdStore struct {
+	kv     clientv3.KV
+	prefix string
+	closer func() error
+}
+
+// newEtcdStore connects to the etcd endpoints. A TLS client certificate is used if certFile and keyFile are given,
+// caFile optionally replaces the system roots.
+func newEtcdStore(endpoints []string, prefix, certFile, keyFile, caFile string) (*etcdStore, error) {
+	var tlsConfig *tls.Config
+
+	if certFile != "" || caFile != "" {
+		info := transport.TLSInfo{CertFile: certFile, KeyFile: keyFile, TrustedCAFile: caFile}
+
+		var err error
+
+		tlsConfig, err = info.ClientConfig()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   endpoints,
+		DialTimeout: 5 * time.Second,
+		TLS:         tlsConfig,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	store := newEtcdKVStore(client, prefix)
+	store.closer = client.Close
+
+	return store, nil
+}
+
+// newEtcdKVStore returns a store that uses kv for accessing etcd.
+func newEtcdKVStore(kv clientv3.KV, prefix string) *etcdStore {
+	return &etcdStore{kv: kv, prefix: strings.TrimSuffix(prefix, "/")}
+}
+
+func (e *etcdStore) StoreShares(shares []storedShare) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for _, share := range shares {
+		_, err := e.kv.Put(ctx, e.prefix+"/share/"+share.index, share.line)
+		if err != nil {
+			return fmt.Errorf("storing share in etcd: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (e *etcdStore) LoadShares() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	resp, err := e.kv.Get(ctx, e.prefix+"/share/", clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend))
+	if err != nil {
+		return nil, fmt.Errorf("reading shares from etcd: %w", err)
+	}
+
+	lines := make([]string, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		lines = append(lines, string(kv.Value))
+	}
+
+	return lines, nil
+}
+
+func (e *etcdStore) Close() error {
+	if e.closer == nil {
+		return nil
+	}
+
+	return e.closer()
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// fakeKV implements the parts of the etcd KV interface used by etcdStore. Calling any other method panics.
+type fakeKV struct {
+	clientv3.KV
+
+	data map[string]string
+}
+
+func (f *fakeKV) Put(ctx context.Context, key, val string, opts ...clientv3.OpOption) (*clientv3.PutResponse, error) {
+	f.data[key] = val
+
+	return &clientv3.PutResponse{}, nil
+}
+
+func (f *fakeKV) Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	// etcdStore always reads with a prefix.
+	var keys []string
+
+	for k := range f.data {
+		if strings.HasPrefix(k, key) {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	resp := &clientv3.GetResponse{}
+	for _, k := range keys {
+		resp.Kvs = append(resp.Kvs, &mvccpb.KeyValue{Key: []byte(k), Value: []byte(f.data[k])})
+	}
+
+	return resp, nil
+}
+
+func TestEtcd_roundtrip(t *testing.T) {
+	kv := &fakeKV{data: make(map[string]string)}
+	store := newEtcdKVStore(kv, "/ceremonies/test/")
+
+	var genBuf bytes.Buffer
+
+	err := cmdGenerate(5, 3, generateOptions{sinks: []shareSink{store}}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(kv.data) != 5 {
+		t.Fatalf("want 5 keys, have %d", len(kv.data))
+	}
+
+	for key, value := range kv.data {
+		index := strings.SplitN(value, ",", 2)[0]
+		if key != "/ceremonies/test/share/"+index {
+			t.Errorf("unexpected key %q for share %q", key, value)
+		}
+	}
+
+	in, err := readSource(store)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var (
+		errBuf bytes.Buffer
+		outBuf bytes.Buffer
+	)
+
+	err = cmdRecover(in, recoverOptions{}, &errBuf, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	secret := strings.TrimPrefix(strings.SplitN(genBuf.String(), "\n", 2)[0], "secret: ")
+	if outBuf.String() != secret+"\n" {
+		t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
+	}
+}
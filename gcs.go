@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// gcsStore stores shares as objects <prefix>/share-<index> in a Google Cloud Storage bucket. Each object carries the
+// ceremony ID, the threshold and the total number of shares as custom metadata.
+type gcsStore struct {
+	client     *storage.Client
+	bucket     string
+	prefix     string
+	ceremonyID string
+	k          int
+}
+
+// newGCSStore returns a store using client. Clients created with storage.NewClient use the application default
+// credentials, which include service account keys given by GOOGLE_APPLICATION_CREDENTIALS.
+func newGCSStore(client *storage.Client, bucket, prefix, ceremonyID string, k int) *gcsStore {
+	return &gcsStore{client: client, bucket: bucket, prefix: strings.Trim(prefix, "/"), ceremonyID: ceremonyID, k: k}
+}
+
+func (g *gcsStore) objectName(index string) string {
+	if g.prefix == "" {
+		return "share-" + index
+	}
+
+	return g.prefix + "/share-" + index
+}
+
+func (g *gcsStore) StoreShares(shares []storedShare) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	for _, share := range shares {
+		w := g.client.Bucket(g.bucket).Object(g.objectName(share.index)).NewWriter(ctx)
+		w.ContentType = "text/plain"
+		w.Metadata = map[string]string{
+			"ceremony-id":  g.ceremonyID,
+			"threshold":    strconv.Itoa(g.k),
+			"total-shares": strconv.Itoa(len(shares)),
+		}
+
+		_, err := io.WriteString(w, share.line)
+		if err != nil {
+			w.Close()
+			return fmt.Errorf("storing share in GCS: %w", err)
+		}
+
+		err = w.Close()
+		if err != nil {
+			return fmt.Errorf("storing share in GCS: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (g *gcsStore) LoadShares() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	bucket := g.client.Bucket(g.bucket)
+
+	var lines []string
+
+	it := bucket.Objects(ctx, &storage.Query{Prefix: g.objectName("")})
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("listing shares in GCS: %w", err)
+		}
+
+		r, err := bucket.Object(attrs.Name).NewReader(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("reading share %s from GCS: %w", attrs.Name, err)
+		}
+
+		buf, err := io.ReadAll(r)
+		r.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("reading share %s from GCS: %w", attrs.Name, err)
+		}
+
+		lines = append(lines, string(buf))
+	}
+
+	return lines, nil
+}
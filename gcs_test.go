@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fsouza/fake-gcs-server/fakestorage"
+)
+
+func TestGCS_roundtrip(t *testing.T) {
+	srv := fakestorage.NewServer([]fakestorage.Object{
+		{ObjectAttrs: fakestorage.ObjectAttrs{BucketName: "ceremonies", Name: "other/share-1"}, Content: []byte("1,1")},
+	})
+	defer srv.Stop()
+
+	store := newGCSStore(srv.Client(), "ceremonies", "2026-10/", "2026-10", 3)
+
+	var genBuf bytes.Buffer
+
+	err := cmdGenerate(5, 3, generateOptions{sinks: []shareSink{store}}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	objects, _, err := srv.ListObjectsWithOptions("ceremonies", fakestorage.ListOptions{Prefix: "2026-10/"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(objects) != 5 {
+		t.Fatalf("want 5 objects, have %d", len(objects))
+	}
+
+	for _, attrs := range objects {
+		obj, err := srv.GetObject("ceremonies", attrs.Name)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		index := strings.SplitN(string(obj.Content), ",", 2)[0]
+		if obj.Name != "2026-10/share-"+index {
+			t.Errorf("unexpected name %q for share %q", obj.Name, obj.Content)
+		}
+
+		md := obj.Metadata
+		if md["ceremony-id"] != "2026-10" || md["threshold"] != "3" || md["total-shares"] != "5" {
+			t.Errorf("unexpected metadata for %q: %v", obj.Name, md)
+		}
+	}
+
+	in, err := readSource(store)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var (
+		errBuf bytes.Buffer
+		outBuf bytes.Buffer
+	)
+
+	err = cmdRecover(in, recoverOptions{}, &errBuf, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if errBuf.Len() != 0 {
+		t.Errorf("unexpected diagnostic: %q", errBuf.String())
+	}
+
+	secret := strings.TrimPrefix(strings.SplitN(genBuf.String(), "\n", 2)[0], "secret: ")
+	if outBuf.String() != secret+"\n" {
+		t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
+	}
+}
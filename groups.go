@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/farhaven/secret/shamir"
+)
+
+// shareGroup is a group of custodians in hierarchical secret sharing. Recovering the secret requires k of the n shares
+// of every group.
+type shareGroup struct {
+	Name string `json:"name"`
+	K    int    `json:"k"`
+	N    int    `json:"n"`
+}
+
+// parseGroupConfig parses a JSON group configuration like [{"name":"exec","k":2,"n":3},{"name":"board","k":3,"n":5}].
+func parseGroupConfig(config string) ([]shareGroup, error) {
+	var groups []shareGroup
+
+	err := json.Unmarshal([]byte(config), &groups)
+	if err != nil {
+		return nil, fmt.Errorf("parsing group config: %w", err)
+	}
+
+	if len(groups) == 0 {
+		return nil, errors.New("Group config contains no groups.")
+	}
+
+	seen := make(map[string]bool)
+
+	for _, g := range groups {
+		if g.Name == "" || strings.ContainsAny(g.Name, ": \t") {
+			return nil, fmt.Errorf("Invalid group name %q.", g.Name)
+		}
+
+		if seen[g.Name] {
+			return nil, fmt.Errorf("Duplicate group %q.", g.Name)
+		}
+
+		seen[g.Name] = true
+
+		if g.K > g.N {
+			return nil, fmt.Errorf("Group %q: there will not be enough shares to recover the secret.", g.Name)
+		}
+
+		if g.N < 1 || g.K < 1 {
+			return nil, fmt.Errorf("Group %q: number of shares must be larger than 1.", g.Name)
+		}
+	}
+
+	return groups, nil
+}
+
+// cmdGenerateGroups generates a secret that requires shares from all groups for recovery. Every group shares its own
+// sub-secret, and the secret is the XOR of all sub-secrets. Share lines are prefixed with the group name:
+// "<group>:<index>,<value>", followed by the ceremony ID if it is set: "<group>:id:<ceremony-id>:<index>,<value>".
+func cmdGenerateGroups(groups []shareGroup, ceremonyID string, out io.Writer) error {
+	err := validateCeremonyID(ceremonyID)
+	if err != nil {
+		return err
+	}
+
+	secret, err := rand.Int(rand.Reader, shamir.Prime)
+	if err != nil {
+		return err
+	}
+
+	sub := make([]*big.Int, len(groups))
+	last := new(big.Int).Set(secret)
+
+	for i := range groups[1:] {
+		sub[i], err = rand.Int(rand.Reader, shamir.Prime)
+		if err != nil {
+			return err
+		}
+
+		last.Xor(last, sub[i])
+	}
+
+	sub[len(groups)-1] = last
+
+	fmt.Fprintln(out, "secret:", secret.Text(62))
+
+	for i, g := range groups {
+		shares, _, err := shamir.GenerateShares(g.N, g.K, sub[i], shamir.PoolSize(g.N))
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "shares of group %s (need at least %d of these for recovery):\n", g.Name, g.K)
+		for _, share := range shares {
+			fmt.Fprintf(out, "%s:%s\n", g.Name, withCeremonyID(ceremonyID, share.String()))
+		}
+	}
+
+	return nil
+}
+
+// cmdRecoverGroups recovers a secret generated by cmdGenerateGroups. Only shares of the ceremony ceremonyID are used,
+// or of the ceremony most shares belong to if it is empty. It returns an error if any group does not have enough
+// shares.
+func cmdRecoverGroups(in io.Reader, groups []shareGroup, ceremonyID string, diag io.Writer, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+
+	read := make(map[string][]ceremonyShare)
+	for _, g := range groups {
+		read[g.Name] = nil
+	}
+
+	var ids []string
+
+	for scanner.Scan() {
+		t := strings.TrimSpace(scanner.Text())
+
+		if t == "" || strings.HasPrefix(t, "secret: ") || strings.HasPrefix(t, "shares") {
+			continue
+		}
+
+		name, share, ok := strings.Cut(t, ":")
+		if !ok {
+			fmt.Fprintf(diag, "reading share %q: no group\n", t)
+			continue
+		}
+
+		if _, ok := read[name]; !ok {
+			fmt.Fprintf(diag, "reading share %q: unknown group %q\n", t, name)
+			continue
+		}
+
+		id, share := splitCeremonyID(share)
+
+		s, err := parseShare(share)
+		if err != nil {
+			fmt.Fprintf(diag, "reading share %q: %s\n", t, err)
+			continue
+		}
+
+		ids = append(ids, id)
+		read[name] = append(read[name], ceremonyShare{line: t, id: id, share: s})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if ceremonyID == "" {
+		var err error
+
+		ceremonyID, err = majorityCeremonyID(ids)
+		if err != nil {
+			return err
+		}
+	}
+
+	secret := new(big.Int)
+
+	for _, g := range groups {
+		_, shares, err := selectCeremony(ceremonyID, read[g.Name], diag)
+		if err != nil {
+			return err
+		}
+
+		if len(shares) < g.K {
+			return fmt.Errorf("Group %s has %d shares, need at least %d.", g.Name, len(shares), g.K)
+		}
+
+		part, err := shamir.Combine(shares)
+		if err != nil {
+			return fmt.Errorf("Group %s: %w", g.Name, err)
+		}
+
+		secret.Xor(secret, part)
+	}
+
+	fmt.Fprintln(out, secret.Text(62))
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const testGroupConfig = `[{"name":"exec","k":2,"n":3},{"name":"board","k":3,"n":5}]`
+
+func TestGroups(t *testing.T) {
+	groups, err := parseGroupConfig(testGroupConfig)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var buf bytes.Buffer
+
+	err = cmdGenerateGroups(groups, "2026-10", &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 11 {
+		t.Fatalf("want 11 lines, have %d: %q", len(lines), buf.String())
+	}
+
+	secret := strings.TrimPrefix(lines[0], "secret: ")
+	exec := lines[2:5]
+	board := lines[6:11]
+
+	for _, line := range append(append([]string{}, exec...), board...) {
+		if _, share, _ := strings.Cut(line, ":"); !strings.HasPrefix(share, "id:2026-10:") {
+			t.Errorf("share line %q does not carry the ceremony ID", line)
+		}
+	}
+
+	testCases := map[string]struct {
+		shares    []string
+		expectErr string
+	}{
+		"all shares":         {shares: append(append([]string{}, exec...), board...)},
+		"threshold of both":  {shares: []string{exec[0], exec[2], board[1], board[3], board[4]}},
+		"exec insufficient":  {shares: []string{exec[1], board[0], board[1], board[2]}, expectErr: "Group exec has 1 shares"},
+		"board insufficient": {shares: []string{exec[0], exec[1], board[0], board[1]}, expectErr: "Group board has 2 shares"},
+		"only exec":          {shares: exec, expectErr: "Group board has 0 shares"},
+		"other ceremony":     {shares: append([]string{"exec:id:2025-04:1,1"}, exec[:2]...), expectErr: "Group board has 0 shares"},
+	}
+
+	for desc, tc := range testCases {
+		t.Run(desc, func(t *testing.T) {
+			var (
+				errBuf bytes.Buffer
+				outBuf bytes.Buffer
+			)
+
+			err := cmdRecoverGroups(strings.NewReader(strings.Join(tc.shares, "\n")), groups, "", &errBuf, &outBuf)
+
+			if tc.expectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErr) {
+					t.Fatalf("expected error to contain %q, have %v", tc.expectErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if outBuf.String() != secret+"\n" {
+				t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
+			}
+
+			if errBuf.Len() != 0 {
+				t.Errorf("unexpected diagnostic: %q", errBuf.String())
+			}
+		})
+	}
+}
+
+func TestParseGroupConfig_invalid(t *testing.T) {
+	testCases := map[string]string{
+		"not json":      `exec:2:3`,
+		"no groups":     `[]`,
+		"unrecoverable": `[{"name":"exec","k":4,"n":3}]`,
+		"zero k":        `[{"name":"exec","k":0,"n":3}]`,
+		"duplicate":     `[{"name":"exec","k":2,"n":3},{"name":"exec","k":2,"n":3}]`,
+		"invalid name":  `[{"name":"ex:ec","k":2,"n":3}]`,
+		"no group name": `[{"k":2,"n":3}]`,
+	}
+
+	for desc, config := range testCases {
+		t.Run(desc, func(t *testing.T) {
+			_, err := parseGroupConfig(config)
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+
+	"github.com/posener/sharedsecret"
+)
+
+// hmacTagPrefix starts the HMAC tag field of a share.
+const hmacTagPrefix = "mac="
+
+// hmacTagLen is the length in bytes the HMAC-SHA256 of a share is truncated to.
+const hmacTagLen = 16
+
+// hmacKeyEnv is the environment variable the HMAC key is read from if -hmac-key is not given.
+const hmacKeyEnv = "SECRET_HMAC_KEY"
+
+// shareTag returns the truncated HMAC-SHA256 of the "index,value" text of share under key.
+func shareTag(key []byte, share sharedsecret.Share) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(share.String()))
+
+	return hex.EncodeToString(mac.Sum(nil)[:hmacTagLen])
+}
+
+// verifyShareTag checks the HMAC tag of share under key.
+func verifyShareTag(key []byte, share labeledShare) error {
+	if share.tag == "" {
+		return errors.New("missing HMAC tag")
+	}
+
+	tag, err := hex.DecodeString(share.tag)
+	if err != nil {
+		return errors.New("malformed HMAC tag")
+	}
+
+	want, _ := hex.DecodeString(shareTag(key, share.share))
+	if !hmac.Equal(tag, want) {
+		return errors.New("HMAC tag does not match")
+	}
+
+	return nil
+}
+
+// hmacKey returns the HMAC key given by the -hmac-key flag, or by the environment if the flag is empty. It is nil if
+// neither is set.
+func hmacKey(flag string) []byte {
+	if flag == "" {
+		flag = os.Getenv(hmacKeyEnv)
+	}
+
+	if flag == "" {
+		return nil
+	}
+
+	return []byte(flag)
+}
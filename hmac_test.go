@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/farhaven/secret/shamir"
+)
+
+func TestHMAC_roundTrip(t *testing.T) {
+	key := []byte("hmac key")
+
+	var genBuf bytes.Buffer
+
+	err := cmdGenerate(3, 2, generateOptions{hmacKey: key, labels: []string{"alice", "bob", "carol"}}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(genBuf.String()), "\n")
+	secret := strings.TrimPrefix(lines[0], "secret: ")
+	shares := lines[3:]
+
+	for _, share := range shares {
+		s, err := parseLabeledShare(share)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if s.tag == "" || s.label == "" {
+			t.Errorf("share %q lacks a tag or a label", share)
+		}
+	}
+
+	// The value of the first share is changed, which keeps the line well formed.
+	s, _ := parseLabeledShare(shares[0])
+	x, y := shamir.ShareXY(s.share)
+	tampered := strings.Replace(shares[0], s.share.String(), shamir.NewShare(x, y.Add(y, big.NewInt(1))).String(), 1)
+
+	for _, tc := range []struct {
+		name     string
+		input    []string
+		key      []byte
+		wantDiag string
+		wantErr  bool
+	}{
+		{"valid", shares, key, "", false},
+		{"tampered", []string{tampered, shares[1], shares[2]}, key, "HMAC tag does not match", false},
+		{"wrong key", shares, []byte("other key"), "HMAC tag does not match", true},
+		{"untagged", []string{"1,19943338053965968504353533017903769217", shares[1], shares[2]}, key, "missing HMAC tag", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				errBuf bytes.Buffer
+				outBuf bytes.Buffer
+			)
+
+			err := cmdRecover(strings.NewReader(strings.Join(tc.input, "\n")), recoverOptions{hmacKey: tc.key}, &errBuf, &outBuf)
+
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				if outBuf.String() != secret+"\n" {
+					t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
+				}
+			}
+
+			if tc.wantDiag != "" && !strings.Contains(errBuf.String(), tc.wantDiag) {
+				t.Errorf("diagnostic %q does not contain %q", errBuf.String(), tc.wantDiag)
+			}
+		})
+	}
+}
+
+func TestHMAC_berTLV(t *testing.T) {
+	err := cmdGenerate(3, 2, generateOptions{hmacKey: []byte("hmac key"), format: "ber-tlv"}, &bytes.Buffer{})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
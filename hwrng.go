@@ -0,0 +1,32 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+)
+
+// hwrngSecretSize is the number of bytes read from a hardware random number generator for the secret.
+const hwrngSecretSize = 32
+
+// readHardwareRNG reads a secret of hwrngSecretSize bytes from the random number generator device.
+func readHardwareRNG(device string) (*big.Int, error) {
+	fh, err := os.Open(device)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+
+	buf := make([]byte, hwrngSecretSize)
+
+	n, err := io.ReadFull(fh, buf)
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("short read from %s: got %d of %d bytes", device, n, hwrngSecretSize)
+	} else if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(buf), nil
+}
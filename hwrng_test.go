@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadHardwareRNG(t *testing.T) {
+	want := []byte{
+		0x8f, 0x1c, 0x47, 0xe0, 0x02, 0x9b, 0xd4, 0x6a, 0x31, 0xf5, 0x0c, 0x77, 0xa8, 0x5e, 0x13, 0xc9,
+		0x66, 0xb2, 0x09, 0xdd, 0x4f, 0x80, 0x3a, 0xe7, 0x15, 0x9c, 0x72, 0x28, 0xfb, 0x41, 0x06, 0xbe,
+	}
+
+	device := filepath.Join(t.TempDir(), "hwrng")
+
+	err := os.WriteFile(device, append(want, 0xff, 0xff), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	secret, err := readHardwareRNG(device)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !bytes.Equal(secret.Bytes(), want) {
+		t.Errorf("unexpected secret: want %x, have %x", want, secret.Bytes())
+	}
+
+	var (
+		buf    bytes.Buffer
+		errBuf bytes.Buffer
+		outBuf bytes.Buffer
+	)
+
+	err = cmdGenerate(5, 3, generateOptions{secret: secret}, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	wantSecret := "secret: " + secret.Text(62) + "\n"
+	if !strings.HasPrefix(buf.String(), wantSecret) {
+		t.Errorf("expected output to start with %q, have %q", wantSecret, buf.String())
+	}
+
+	err = cmdRecover(&buf, recoverOptions{}, &errBuf, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if outBuf.String() != secret.Text(62)+"\n" {
+		t.Errorf("unexpected recovered secret. want %q, have %q", secret.Text(62), outBuf.String())
+	}
+}
+
+func TestReadHardwareRNG_shortRead(t *testing.T) {
+	device := filepath.Join(t.TempDir(), "hwrng")
+
+	err := os.WriteFile(device, make([]byte, 16), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, err = readHardwareRNG(device)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	if !strings.Contains(err.Error(), "got 16 of 32 bytes") {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestReadHardwareRNG_missingDevice(t *testing.T) {
+	_, err := readHardwareRNG(filepath.Join(t.TempDir(), "hwrng"))
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
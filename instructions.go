@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// recoveryInstructions configures the plain-English recovery instructions that are written for each custodian instead
+// of bare share lines.
+type recoveryInstructions struct {
+	custodians   []string // Names of the custodians, in the order of the shares. Missing names are left out.
+	adminContact string   // How to reach the ceremony administrator.
+}
+
+func (r *recoveryInstructions) validate() error {
+	if r.adminContact == "" {
+		return errors.New("Recovery instructions require a contact for the ceremony administrator.")
+	}
+
+	return nil
+}
+
+// write writes the instructions for the share line of the i-th of n custodians. k custodians are required for
+// recovery.
+func (r *recoveryInstructions) write(out io.Writer, i, n, k int, ceremonyID, line string) {
+	if i > 0 {
+		fmt.Fprint(out, "\n----\n\n")
+	}
+
+	if i < len(r.custodians) && r.custodians[i] != "" {
+		fmt.Fprintf(out, "Recovery instructions for %s\n", r.custodians[i])
+	} else {
+		fmt.Fprintln(out, "Recovery instructions")
+	}
+
+	if ceremonyID != "" {
+		fmt.Fprintf(out, "Ceremony: %s\n", ceremonyID)
+	}
+
+	fmt.Fprintf(out, "\nYou are holding share #%d of a secret split among %d holders. ", i+1, n)
+	fmt.Fprintf(out, "At least %d of these holders must provide their share to recover the secret. ", k)
+	fmt.Fprintf(out, "Contact the ceremony administrator at %s.\n\n", r.adminContact)
+	fmt.Fprintf(out, "Your share is:\n\n    %s\n\n", line)
+	fmt.Fprintln(out, "Keep this document in a safe place. Only give your share to the ceremony administrator, and only when they ask for it.")
+}
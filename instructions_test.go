@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGenerate_recoveryInstructions(t *testing.T) {
+	var (
+		secretBuf bytes.Buffer
+		outBuf    bytes.Buffer
+	)
+
+	opts := generateOptions{
+		secretOut:  &secretBuf,
+		ceremonyID: "2026-10",
+		instructions: &recoveryInstructions{
+			custodians:   []string{"Alice", "Bob"},
+			adminContact: "admin@example.com",
+		},
+		noOversample: true,
+	}
+
+	err := cmdGenerate(5, 3, opts, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	docs := strings.Split(outBuf.String(), "\n----\n\n")
+	if len(docs) != 5 {
+		t.Fatalf("want 5 documents, have %d: %q", len(docs), outBuf.String())
+	}
+
+	for i, doc := range docs {
+		want := []string{
+			"Ceremony: 2026-10\n",
+			"You are holding share #" + string(rune('1'+i)) + " of a secret split among 5 holders.",
+			"At least 3 of these holders must provide their share to recover the secret.",
+			"Contact the ceremony administrator at admin@example.com.",
+			"Your share is:\n\n    id:2026-10:",
+		}
+
+		switch i {
+		case 0:
+			want = append(want, "Recovery instructions for Alice\n")
+		case 1:
+			want = append(want, "Recovery instructions for Bob\n")
+		default:
+			want = append(want, "Recovery instructions\n")
+		}
+
+		for _, w := range want {
+			if !strings.Contains(doc, w) {
+				t.Errorf("document %d does not contain %q: %q", i, w, doc)
+			}
+		}
+	}
+
+	if strings.Contains(outBuf.String(), "secret:") {
+		t.Errorf("instructions contain the secret: %q", outBuf.String())
+	}
+
+	if !strings.HasPrefix(secretBuf.String(), "secret: ") {
+		t.Errorf("unexpected secret output: %q", secretBuf.String())
+	}
+}
+
+func TestGenerate_recoveryInstructionsWithoutContact(t *testing.T) {
+	opts := generateOptions{
+		secretOut:    &bytes.Buffer{},
+		instructions: &recoveryInstructions{},
+	}
+
+	err := cmdGenerate(5, 3, opts, &bytes.Buffer{})
+	if err == nil {
+		t.Fatal("expected an error without an administrator contact")
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// jsonShares is the output of cmdGenerate in the json format. The secret is left out if it is written to a separate
+// output.
+type jsonShares struct {
+	Secret    string   `json:"secret,omitempty"`
+	Threshold int      `json:"threshold"`
+	Shares    []string `json:"shares"`
+}
+
+// jsonSecret is the output of cmdRecover in the json format.
+type jsonSecret struct {
+	Secret string `json:"secret"`
+}
+
+// writeJSONShares writes the secret and the share lines as a single JSON object.
+func writeJSONShares(out io.Writer, secret string, k int, shares []storedShare) error {
+	v := jsonShares{Secret: secret, Threshold: k, Shares: make([]string, len(shares))}
+	for i, share := range shares {
+		v.Shares[i] = share.line
+	}
+
+	return json.NewEncoder(out).Encode(v)
+}
+
+// readJSONShares converts the input to the text format if it is the JSON output of cmdGenerate. Other input is
+// returned unchanged.
+func readJSONShares(in io.Reader) (io.Reader, error) {
+	buf, err := io.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.HasPrefix(bytes.TrimSpace(buf), []byte("{")) {
+		return bytes.NewReader(buf), nil
+	}
+
+	var v jsonShares
+
+	err = json.Unmarshal(buf, &v)
+	if err != nil {
+		return nil, fmt.Errorf("reading JSON shares: %w", err)
+	}
+
+	var text strings.Builder
+
+	if v.Threshold > 0 {
+		fmt.Fprintf(&text, "shares (need at least %d of these for recovery):\n", v.Threshold)
+	}
+
+	for _, share := range v.Shares {
+		text.WriteString(share + "\n")
+	}
+
+	return strings.NewReader(text.String()), nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestJSON_roundtrip(t *testing.T) {
+	var genBuf bytes.Buffer
+
+	err := cmdGenerate(5, 3, generateOptions{format: "json"}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var generated jsonShares
+
+	err = json.Unmarshal(genBuf.Bytes(), &generated)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if generated.Secret == "" || generated.Threshold != 3 || len(generated.Shares) != 5 {
+		t.Fatalf("unexpected output %+v", generated)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		input  string
+		format string
+		want   string
+	}{
+		{"JSON input, text output", genBuf.String(), "text", generated.Secret + "\n"},
+		{"JSON input, JSON output", genBuf.String(), "json", `{"secret":"` + generated.Secret + `"}` + "\n"},
+		{"text input, text output", strings.Join(generated.Shares[1:4], "\n"), "", generated.Secret + "\n"},
+		{"text input, JSON output", strings.Join(generated.Shares[:3], "\n"), "json", `{"secret":"` + generated.Secret + `"}` + "\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var outBuf bytes.Buffer
+
+			err := cmdRecover(strings.NewReader(tc.input), recoverOptions{format: tc.format}, &bytes.Buffer{}, &outBuf)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if outBuf.String() != tc.want {
+				t.Errorf("unexpected output. want %q, have %q", tc.want, outBuf.String())
+			}
+		})
+	}
+}
+
+func TestJSON_secretOut(t *testing.T) {
+	var (
+		genBuf    bytes.Buffer
+		secretBuf bytes.Buffer
+	)
+
+	err := cmdGenerate(3, 2, generateOptions{format: "json", secretOut: &secretBuf}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if strings.Contains(genBuf.String(), `"secret"`) {
+		t.Errorf("secret written to the shares output: %s", genBuf.String())
+	}
+
+	if !strings.HasPrefix(secretBuf.String(), "secret: ") {
+		t.Errorf("unexpected secret output %q", secretBuf.String())
+	}
+}
+
+func TestJSON_errors(t *testing.T) {
+	for _, opts := range []generateOptions{
+		{format: "json", header: []string{"salt: abc"}},
+		{format: "json", withholdShares: true},
+	} {
+		err := cmdGenerate(3, 2, opts, &bytes.Buffer{})
+		if err == nil {
+			t.Errorf("expected error for %+v, got nil", opts)
+		}
+	}
+
+	err := cmdRecover(strings.NewReader(`{"shares": [1, 2]}`), recoverOptions{}, &bytes.Buffer{}, &bytes.Buffer{})
+	if err == nil {
+		t.Error("expected error for invalid JSON shares, got nil")
+	}
+
+	err = cmdRecover(strings.NewReader("1,2\n"), recoverOptions{format: "yaml"}, &bytes.Buffer{}, &bytes.Buffer{})
+	if err == nil {
+		t.Error("expected error for an unknown format, got nil")
+	}
+}
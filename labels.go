@@ -0,0 +1,184 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/posener/sharedsecret"
+)
+
+// A labeledShare is a share along with the name of the custodian it is meant for, its HMAC tag and the threshold of
+// its share set. Labeled shares are written as "index,value,label", tagged shares as "index,value,mac=<tag>" and
+// shares with a threshold as "index,value,k". Shares with several of them are written as
+// "index,value,mac=<tag>,k,label".
+type labeledShare struct {
+	share     sharedsecret.Share
+	label     string // Empty for shares without a label.
+	tag       string // Empty for shares without an HMAC tag.
+	threshold int    // Zero for shares without a threshold.
+	padding   int    // Width the share value is left-padded to, zero for no padding.
+
+	// encoding is the encoding of the share value, one of the encodings accepted by validateShareEncoding.
+	encoding string
+}
+
+func (l labeledShare) String() string {
+	line := formatShare(l.share, l.encoding, l.padding)
+
+	if l.tag != "" {
+		line += "," + hmacTagPrefix + l.tag
+	}
+
+	if l.threshold != 0 {
+		line += "," + strconv.Itoa(l.threshold)
+	}
+
+	if l.label != "" {
+		line += "," + l.label
+	}
+
+	return line
+}
+
+// validateLabels checks that there is one usable label for each of n shares.
+func validateLabels(labels []string, n int) error {
+	if len(labels) != n {
+		return fmt.Errorf("There are %d labels for %d shares.", len(labels), n)
+	}
+
+	for _, label := range labels {
+		if label == "" {
+			return errors.New("Labels must not be empty.")
+		}
+
+		if strings.ContainsAny(label, ",= \t\r\n") {
+			return fmt.Errorf("Label %q must not contain commas, equals signs or whitespace.", label)
+		}
+
+		if isDecimal(label) {
+			return fmt.Errorf("Label %q must not be a number, since it would be read as the threshold.", label)
+		}
+	}
+
+	return nil
+}
+
+// parseLabeledShare parses a share with an optional HMAC tag, an optional threshold and an optional label. The
+// encoding of the share value is detected: decimal values are preferred over hex values and hex values over base64
+// values, since all of them are written after a comma. BER-TLV shares never carry any of them.
+func parseLabeledShare(line string) (labeledShare, error) {
+	if isBERShare(line) {
+		s, err := unmarshalBERShare(line)
+		return labeledShare{share: s}, err
+	}
+
+	var (
+		l     labeledShare
+		extra []string
+	)
+
+	fields := strings.Split(line, ",")
+
+	if isWordsShare(fields[0]) {
+		s, err := parseWordsShare(fields[0])
+		if err != nil {
+			return labeledShare{}, err
+		}
+
+		l.share, l.encoding = s, wordsEncoding
+		extra = fields[1:]
+	} else if index, value, ok := strings.Cut(fields[0], "_"); ok {
+		s, err := parseBase58Share(index, value)
+		if err != nil {
+			return labeledShare{}, err
+		}
+
+		l.share, l.encoding = s, base58Encoding
+		extra = fields[1:]
+	} else if len(fields) > 1 && !isDecimal(fields[1]) && isHexValue(fields[1]) {
+		s, err := parseHexShare(fields[0], fields[1])
+		if err != nil {
+			return labeledShare{}, err
+		}
+
+		l.share, l.encoding = s, hexEncoding
+		extra = fields[2:]
+	} else {
+		if len(fields) > 2 {
+			line = strings.Join(fields[:2], ",")
+			extra = fields[2:]
+		}
+
+		if len(fields) > 1 && isDecimal(fields[1]) {
+			// The value is parsed with its base detected from a prefix, so the zeros of padded values are stripped
+			// to keep them from being read as octal.
+			line = fields[0] + "," + padLeft(strings.TrimLeft(fields[1], "0"), "0", 1)
+		}
+
+		err := l.share.UnmarshalText([]byte(line))
+		if err != nil {
+			if len(fields) < 2 {
+				return labeledShare{}, err
+			}
+
+			s, ok := parseBase64Share(fields[0], fields[1])
+			if !ok {
+				return labeledShare{}, err
+			}
+
+			l.share, l.encoding = s, base64Encoding
+		}
+	}
+
+	if len(extra) > 0 {
+		if tag, ok := strings.CutPrefix(extra[0], hmacTagPrefix); ok {
+			if tag == "" {
+				return labeledShare{}, errors.New("empty HMAC tag")
+			}
+
+			l.tag = tag
+			extra = extra[1:]
+		}
+	}
+
+	if len(extra) > 0 && isDecimal(extra[0]) {
+		k, err := strconv.Atoi(extra[0])
+		if err != nil || k < 1 {
+			return labeledShare{}, errors.New("invalid threshold")
+		}
+
+		l.threshold = k
+		extra = extra[1:]
+	}
+
+	switch len(extra) {
+	case 0:
+	case 1:
+		if extra[0] == "" {
+			return labeledShare{}, errors.New("empty label")
+		}
+
+		l.label = extra[0]
+	default:
+		return labeledShare{}, errors.New("too many fields")
+	}
+
+	return l, nil
+}
+
+// isDecimal reports whether s is a non-empty string of decimal digits.
+func isDecimal(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
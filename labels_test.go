@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGenerate_labels(t *testing.T) {
+	var buf bytes.Buffer
+
+	labels := []string{"alice", "bob", "carol"}
+
+	err := cmdGenerate(3, 2, generateOptions{labels: labels, ceremonyID: "2026-10"}, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+
+	shares := lines[3:]
+
+	for i, want := range labels {
+		_, share := splitCeremonyID(shares[i])
+
+		s, err := parseLabeledShare(share)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if s.label != want {
+			t.Errorf("unexpected label for share %d. want %q, have %q", i, want, s.label)
+		}
+	}
+
+	var (
+		errBuf bytes.Buffer
+		outBuf bytes.Buffer
+	)
+
+	err = cmdRecover(strings.NewReader(strings.Join(shares[1:], "\n")), recoverOptions{}, &errBuf, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if outBuf.String() != strings.TrimPrefix(lines[0], "secret: ")+"\n" {
+		t.Errorf("unexpected recovered secret: %q", outBuf.String())
+	}
+
+	for _, want := range []string{`is labeled "bob"`, `is labeled "carol"`} {
+		if !strings.Contains(errBuf.String(), want) {
+			t.Errorf("diagnostic %q does not contain %q", errBuf.String(), want)
+		}
+	}
+}
+
+func TestGenerate_labelsErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts generateOptions
+	}{
+		{"too few", generateOptions{labels: []string{"alice", "bob"}}},
+		{"too many", generateOptions{labels: []string{"alice", "bob", "carol", "dave"}}},
+		{"empty", generateOptions{labels: []string{"alice", "", "carol"}}},
+		{"whitespace", generateOptions{labels: []string{"alice", "bob smith", "carol"}}},
+		{"number", generateOptions{labels: []string{"alice", "2", "carol"}}},
+		{"BER-TLV", generateOptions{labels: []string{"alice", "bob", "carol"}, format: "ber-tlv"}},
+		{"noise", generateOptions{labels: []string{"alice", "bob", "carol"}, noiseShares: 2}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			err := cmdGenerate(3, 2, tc.opts, &buf)
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+
+			if buf.Len() != 0 {
+				t.Errorf("unexpected output: %q", buf.String())
+			}
+		})
+	}
+}
+
+func TestParseLabeledShare(t *testing.T) {
+	for _, tc := range []struct {
+		line    string
+		label   string
+		wantErr bool
+	}{
+		{"1,19943338053965968504353533017903769217", "", false},
+		{"1,19943338053965968504353533017903769217,alice", "alice", false},
+		{"1,19943338053965968504353533017903769217,", "", true},
+		{"1,x,alice", "", true},
+		{"1,19943338053965968504353533017903769217,mac=00ff,alice", "alice", false},
+		{"1,19943338053965968504353533017903769217,mac=00ff", "", false},
+		{"1,19943338053965968504353533017903769217,mac=,alice", "", true},
+		{"1,19943338053965968504353533017903769217,alice,bob", "", true},
+		{"1,19943338053965968504353533017903769217,mac=00ff,3,alice", "alice", false},
+		{"1,19943338053965968504353533017903769217,0", "", true},
+	} {
+		t.Run(tc.line, func(t *testing.T) {
+			s, err := parseLabeledShare(tc.line)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if s.label != tc.label {
+				t.Errorf("want label %q, have %q", tc.label, s.label)
+			}
+		})
+	}
+}
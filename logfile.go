@@ -0,0 +1,22 @@
+package main
+
+import (
+	"io"
+	"os"
+)
+
+// openLogFile returns a writer for diagnostics that writes to diag and appends to the file name, for pipelines that
+// mix stderr with stdout. It returns diag if name is empty. The file is closed by cs.
+func openLogFile(name string, diag io.Writer, cs *closers) (io.Writer, error) {
+	if name == "" {
+		return diag, nil
+	}
+
+	fh, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	cs.add(func() { fh.Close() })
+
+	return io.MultiWriter(diag, fh), nil
+}
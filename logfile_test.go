@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOpenLogFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "secret.log")
+
+	err := os.WriteFile(name, []byte("earlier run\n"), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var (
+		cs      closers
+		diagBuf bytes.Buffer
+	)
+
+	diag, err := openLogFile(name, &diagBuf, &cs)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	input := "foo\n1,19943338053965968504353533017903769217\n2,161872477868088873785792630750634181303\n5,160274174127002500413544256698187925606\n"
+
+	err = cmdRecover(strings.NewReader(input), recoverOptions{}, diag, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	cs.close()
+
+	const want = "reading share \"foo\": expected two parts\n"
+
+	if diagBuf.String() != want {
+		t.Errorf("unexpected diagnostic. want %q, have %q", want, diagBuf.String())
+	}
+
+	logged, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if string(logged) != "earlier run\n"+want {
+		t.Errorf("unexpected log file. want %q, have %q", "earlier run\n"+want, logged)
+	}
+}
+
+func TestOpenLogFile_errors(t *testing.T) {
+	var cs closers
+	defer cs.close()
+
+	_, err := openLogFile(filepath.Join(t.TempDir(), "missing", "secret.log"), &bytes.Buffer{}, &cs)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
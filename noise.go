@@ -0,0 +1,65 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	mrand "math/rand"
+
+	"github.com/farhaven/secret/shamir"
+	"github.com/posener/sharedsecret"
+)
+
+// addNoiseShares returns shares with m fake shares inserted at random positions. The fake shares have unused random
+// indices up to pool+m and random values, so they look like real shares but are not on the sharing polynomial. A
+// recovery that includes one of them yields a wrong secret. The same non-zero seed inserts fake shares with the same
+// values at the same positions, a zero seed is replaced by a random one.
+func addNoiseShares(shares []sharedsecret.Share, m int, pool int64, seed int64) ([]sharedsecret.Share, error) {
+	if m < 0 {
+		return nil, fmt.Errorf("Invalid number of noise shares %d.", m)
+	}
+
+	if seed == 0 {
+		var buf [8]byte
+
+		_, err := rand.Read(buf[:])
+		if err != nil {
+			return nil, err
+		}
+
+		seed = int64(binary.BigEndian.Uint64(buf[:]))
+	}
+
+	rng := mrand.New(mrand.NewSource(seed))
+
+	used := make(map[string]bool, len(shares)+m)
+	for _, share := range shares {
+		used[shareIndex(share)] = true
+	}
+
+	out := append([]sharedsecret.Share{}, shares...)
+
+	for i := 0; i < m; i++ {
+		// Taken indices are skipped over instead of drawing another one, so that the real shares do not change which
+		// random numbers the remaining fake shares get.
+		index := big.NewInt(rng.Int63n(pool+int64(m)) + 1)
+		for used[index.String()] {
+			index.SetInt64(index.Int64()%(pool+int64(m)) + 1)
+		}
+
+		used[index.String()] = true
+
+		var fake sharedsecret.Share
+
+		err := fake.UnmarshalText([]byte(index.String() + "," + new(big.Int).Rand(rng, shamir.Prime).String()))
+		if err != nil {
+			return nil, err
+		}
+
+		pos := rng.Intn(len(out) + 1)
+		out = append(out[:pos], append([]sharedsecret.Share{fake}, out[pos:]...)...)
+	}
+
+	return out, nil
+}
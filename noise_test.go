@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// generateNoisy generates n real and m noise shares and returns the secret and the share lines.
+func generateNoisy(t *testing.T, n, k, m int, seed int64) (string, []string) {
+	t.Helper()
+
+	var genBuf bytes.Buffer
+
+	err := cmdGenerate(n, k, generateOptions{noiseShares: m, noiseSeed: seed}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(genBuf.String()), "\n")
+
+	return strings.TrimPrefix(lines[0], "secret: "), lines[3:]
+}
+
+func TestNoiseShares(t *testing.T) {
+	secret, lines := generateNoisy(t, 5, 3, 4, 42)
+	if len(lines) != 9 {
+		t.Fatalf("want 9 shares, have %d", len(lines))
+	}
+
+	indices := make(map[string]bool)
+	for _, line := range lines {
+		indices[shareLineIndex(line)] = true
+	}
+
+	if len(indices) != len(lines) {
+		t.Errorf("duplicate share indices: %q", lines)
+	}
+
+	// The same seed generates noise shares with the same values at the same positions, while the real shares differ
+	// between runs.
+	_, again := generateNoisy(t, 5, 3, 4, 42)
+
+	var real, noise []string
+
+	for i, line := range lines {
+		if strings.SplitN(line, ",", 2)[1] == strings.SplitN(again[i], ",", 2)[1] {
+			noise = append(noise, line)
+		} else {
+			real = append(real, line)
+		}
+	}
+
+	if len(noise) != 4 {
+		t.Fatalf("want 4 reproduced noise shares, have %q", noise)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		shares []string
+		match  bool
+	}{
+		{"real shares", real[:3], true},
+		{"with a noise share", []string{real[0], real[1], noise[0]}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var outBuf bytes.Buffer
+
+			err := cmdRecover(strings.NewReader(strings.Join(tc.shares, "\n")), recoverOptions{}, &bytes.Buffer{}, &outBuf)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if (outBuf.String() == secret+"\n") != tc.match {
+				t.Errorf("unexpected recovered secret %q, secret is %q", outBuf.String(), secret)
+			}
+		})
+	}
+}
+
+func TestNoiseShares_errors(t *testing.T) {
+	for _, opts := range []generateOptions{
+		{noiseShares: -1},
+		{noiseShares: 1, backups: 1},
+	} {
+		err := cmdGenerate(3, 2, opts, &bytes.Buffer{})
+		if err == nil {
+			t.Errorf("expected error for %+v, got nil", opts)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	// Decoders for the scanned images.
+	_ "image/jpeg"
+)
+
+// ocrSource reads shares from scanned paper share documents with the tesseract OCR engine. Every image is recognized
+// in several passes with different preprocessing, and the share recognized with the highest confidence is used.
+type ocrSource struct {
+	dir       string
+	tesseract string // Path of the tesseract binary.
+	diag      io.Writer
+}
+
+func newOCRSource(dir string, diag io.Writer) *ocrSource {
+	return &ocrSource{dir: dir, tesseract: "tesseract", diag: diag}
+}
+
+// ocrPass is a preprocessing step applied to an image before it is recognized.
+type ocrPass struct {
+	name    string
+	prepare func(image.Image) image.Image
+}
+
+var ocrPasses = []ocrPass{
+	{"original", func(img image.Image) image.Image { return img }},
+	{"binarized", binarizeImage},
+	{"upscaled", func(img image.Image) image.Image { return binarizeImage(scaleImage(img, 2)) }},
+}
+
+// ocrConfusions replaces characters that OCR commonly recognizes instead of digits.
+var ocrConfusions = strings.NewReplacer("O", "0", "o", "0", "I", "1", "l", "1", "|", "1")
+
+func (o *ocrSource) LoadShares() ([]string, error) {
+	entries, err := os.ReadDir(o.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".png", ".jpg", ".jpeg":
+		default:
+			continue
+		}
+
+		name := filepath.Join(o.dir, entry.Name())
+
+		line, confidence, pass, err := o.recognize(name)
+		if err != nil {
+			fmt.Fprintf(o.diag, "reading share from %s: %s\n", name, err)
+			continue
+		}
+
+		fmt.Fprintf(o.diag, "read share %s from %s with confidence %.1f (%s)\n", shareLineIndex(line), name, confidence, pass)
+
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+// shareLineIndex returns the index of the share in a share line.
+func shareLineIndex(line string) string {
+	_, share := splitCeremonyID(line)
+
+	if i := strings.IndexAny(share, ",_ "); i >= 0 {
+		return share[:i]
+	}
+
+	return share
+}
+
+// recognize runs all OCR passes over the named image and returns the share line recognized with the highest
+// confidence, along with the confidence and the name of the pass.
+func (o *ocrSource) recognize(name string) (string, float64, string, error) {
+	fh, err := os.Open(name)
+	if err != nil {
+		return "", 0, "", err
+	}
+	defer fh.Close()
+
+	img, _, err := image.Decode(fh)
+	if err != nil {
+		return "", 0, "", err
+	}
+
+	tmp, err := os.MkdirTemp("", "secret-ocr-")
+	if err != nil {
+		return "", 0, "", err
+	}
+	defer os.RemoveAll(tmp)
+
+	var (
+		best           string
+		bestConfidence = -1.0
+		bestPass       string
+	)
+
+	for _, pass := range ocrPasses {
+		input := filepath.Join(tmp, pass.name+".png")
+
+		err := writePNG(input, pass.prepare(img))
+		if err != nil {
+			return "", 0, "", err
+		}
+
+		out, err := exec.Command(o.tesseract, input, "stdout", "--psm", "6", "tsv").Output()
+		if err != nil {
+			return "", 0, "", fmt.Errorf("running %s: %w", o.tesseract, err)
+		}
+
+		line, confidence, ok := shareFromTSV(out)
+		if ok && confidence > bestConfidence {
+			best, bestConfidence, bestPass = line, confidence, pass.name
+		}
+	}
+
+	if bestConfidence < 0 {
+		return "", 0, "", fmt.Errorf("no share recognized in %d passes", len(ocrPasses))
+	}
+
+	return best, bestConfidence, bestPass, nil
+}
+
+// shareFromTSV returns the first line of the TSV output of tesseract that is a valid share line, along with the mean
+// confidence of its words.
+func shareFromTSV(tsv []byte) (string, float64, bool) {
+	type ocrLine struct {
+		words      []string
+		confidence float64
+	}
+
+	lines := make(map[string]*ocrLine)
+
+	var order []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(tsv))
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) != 12 || fields[0] != "5" {
+			// Only word level rows carry text.
+			continue
+		}
+
+		confidence, err := strconv.ParseFloat(fields[10], 64)
+		if err != nil || strings.TrimSpace(fields[11]) == "" {
+			continue
+		}
+
+		key := strings.Join(fields[1:5], "/")
+
+		l, ok := lines[key]
+		if !ok {
+			l = &ocrLine{}
+			lines[key] = l
+			order = append(order, key)
+		}
+
+		l.words = append(l.words, strings.TrimSpace(fields[11]))
+		l.confidence += confidence
+	}
+
+	for _, key := range order {
+		l := lines[key]
+
+		line, ok := cleanOCRShare(strings.Join(l.words, ""))
+		if ok {
+			return line, l.confidence / float64(len(l.words)), true
+		}
+	}
+
+	return "", 0, false
+}
+
+// cleanOCRShare corrects common OCR confusions in the share part of a recognized line and returns it if it is a valid
+// share line.
+func cleanOCRShare(text string) (string, bool) {
+	_, text = splitShareLabel(text)
+	id, share := splitCeremonyID(text)
+
+	share = ocrConfusions.Replace(share)
+
+	_, err := parseShare(share)
+	if err != nil {
+		return "", false
+	}
+
+	return withCeremonyID(id, share), true
+}
+
+// binarizeImage converts img to black and white with a threshold halfway between its darkest and brightest pixel.
+func binarizeImage(img image.Image) image.Image {
+	bounds := img.Bounds()
+	gray := image.NewGray(bounds)
+
+	var lo, hi uint8 = 255, 0
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			v := color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y
+			gray.SetGray(x, y, color.Gray{v})
+
+			if v < lo {
+				lo = v
+			}
+
+			if v > hi {
+				hi = v
+			}
+		}
+	}
+
+	threshold := uint8((int(lo) + int(hi)) / 2)
+
+	for i, v := range gray.Pix {
+		if v > threshold {
+			gray.Pix[i] = 255
+		} else {
+			gray.Pix[i] = 0
+		}
+	}
+
+	return gray
+}
+
+// scaleImage enlarges img by factor with nearest neighbour sampling.
+func scaleImage(img image.Image, factor int) image.Image {
+	bounds := img.Bounds()
+	scaled := image.NewRGBA(image.Rect(0, 0, bounds.Dx()*factor, bounds.Dy()*factor))
+
+	for y := 0; y < scaled.Bounds().Dy(); y++ {
+		for x := 0; x < scaled.Bounds().Dx(); x++ {
+			scaled.Set(x, y, img.At(bounds.Min.X+x/factor, bounds.Min.Y+y/factor))
+		}
+	}
+
+	return scaled
+}
+
+func writePNG(name string, img image.Image) error {
+	fh, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+
+	err = png.Encode(fh, img)
+	if err != nil {
+		fh.Close()
+		return err
+	}
+
+	return fh.Close()
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// renderShareImage writes a PNG with text rendered in black on white, like a scanned share document.
+func renderShareImage(t *testing.T, name, text string) {
+	t.Helper()
+
+	small := image.NewRGBA(image.Rect(0, 0, 7*len(text)+20, 30))
+	draw.Draw(small, small.Bounds(), image.White, image.Point{}, draw.Src)
+
+	d := font.Drawer{Dst: small, Src: image.NewUniform(color.Black), Face: basicfont.Face7x13, Dot: fixed.P(10, 20)}
+	d.DrawString(text)
+
+	err := writePNG(name, scaleImage(small, 4))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+// writeMockTesseract writes a tesseract script that recognizes a share with different quality depending on the pass.
+func writeMockTesseract(t *testing.T) string {
+	t.Helper()
+
+	name := filepath.Join(t.TempDir(), "tesseract")
+	script := `#!/bin/sh
+row() { printf '5\t1\t1\t1\t1\t1\t0\t0\t10\t10\t%s\t%s\n' "$1" "$2"; }
+printf 'level\tpage_num\tblock_num\tpar_num\tline_num\tword_num\tleft\ttop\twidth\theight\tconf\ttext\n'
+case "$1" in
+*original*) row 40 '1,1994?338053965968504353533017903769217' ;;
+*binarized*) row 91 '1,199433380539659685O43535'; row 89 '33017903769217' ;;
+*upscaled*) row 80 '1,19943338053965968504353533017903769217' ;;
+esac
+`
+
+	err := os.WriteFile(name, []byte(script), 0700)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	return name
+}
+
+func TestOCR_passes(t *testing.T) {
+	dir := t.TempDir()
+	renderShareImage(t, filepath.Join(dir, "share-1.png"), "1,19943338053965968504353533017903769217")
+
+	var diag bytes.Buffer
+
+	src := newOCRSource(dir, &diag)
+	src.tesseract = writeMockTesseract(t)
+
+	lines, err := src.LoadShares()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// The binarized pass has the highest confidence, and its O is corrected to a zero.
+	if len(lines) != 1 || lines[0] != "1,19943338053965968504353533017903769217" {
+		t.Fatalf("unexpected shares: %q", lines)
+	}
+
+	if !strings.Contains(diag.String(), "with confidence 90.0 (binarized)") {
+		t.Errorf("confidence not reported: %q", diag.String())
+	}
+}
+
+func TestOCR_tesseract(t *testing.T) {
+	_, err := exec.LookPath("tesseract")
+	if err != nil {
+		t.Skip("tesseract is not installed")
+	}
+
+	dir := t.TempDir()
+	renderShareImage(t, filepath.Join(dir, "share-1.png"), "1,19943338053965968504353533017903769217")
+
+	lines, err := newOCRSource(dir, &bytes.Buffer{}).LoadShares()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(lines) != 1 || lines[0] != "1,19943338053965968504353533017903769217" {
+		t.Fatalf("unexpected shares: %q", lines)
+	}
+}
+
+func TestCleanOCRShare(t *testing.T) {
+	for _, tc := range []struct {
+		text string
+		want string
+		ok   bool
+	}{
+		{"1,19943338053965968504353533017903769217", "1,19943338053965968504353533017903769217", true},
+		{"I,l99433380539659685O4353533017903769217", "1,19943338053965968504353533017903769217", true},
+		{"share 1", "", false},
+	} {
+		have, ok := cleanOCRShare(tc.text)
+		if have != tc.want || ok != tc.ok {
+			t.Errorf("%q: want %q, %t, have %q, %t", tc.text, tc.want, tc.ok, have, ok)
+		}
+	}
+}
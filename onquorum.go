@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// quorumHandler receives the secret once the daemon reached a quorum. It returns true if the daemon is done, or false
+// if the daemon should keep watching for more shares.
+type quorumHandler func(secret string) (bool, error)
+
+// quorumExec returns a handler that runs script with the secret on its standard input. The secret is not passed as an
+// argument, where other users of the system could see it. An exit status of zero ends the daemon, any other exit status
+// keeps it watching for more shares.
+func quorumExec(script string, diag io.Writer) quorumHandler {
+	return func(secret string) (bool, error) {
+		cmd := exec.Command(script)
+		cmd.Stdin = strings.NewReader(secret + "\n")
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = diag
+
+		err := cmd.Run()
+
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			fmt.Fprintf(diag, "%s: exit status %d\n", script, exitErr.ExitCode())
+			return false, nil
+		} else if err != nil {
+			return false, fmt.Errorf("running quorum script: %w", err)
+		}
+
+		return true, nil
+	}
+}
+
+// quorumHTTPPost returns a handler that posts the secret to url. A 2xx response ends the daemon, any other response
+// keeps it watching for more shares.
+func quorumHTTPPost(client *http.Client, url string, diag io.Writer) quorumHandler {
+	return func(secret string) (bool, error) {
+		resp, err := client.Post(url, "text/plain", strings.NewReader(secret+"\n"))
+		if err != nil {
+			return false, fmt.Errorf("posting secret: %w", err)
+		}
+		defer resp.Body.Close()
+
+		io.Copy(io.Discard, resp.Body)
+
+		if resp.StatusCode/100 != 2 {
+			fmt.Fprintf(diag, "posting secret: unexpected status %s\n", resp.Status)
+			return false, nil
+		}
+
+		return true, nil
+	}
+}
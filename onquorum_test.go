@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeQuorumScript writes a mock quorum script that appends its standard input to received and exits with the
+// status given in the file status, or zero if it does not exist.
+func writeQuorumScript(t *testing.T, dir string) string {
+	t.Helper()
+
+	script := filepath.Join(dir, "on-quorum.sh")
+	content := "#!/bin/sh\ncat >> " + filepath.Join(dir, "received") + "\n" +
+		"[ -f " + filepath.Join(dir, "status") + " ] && exit $(cat " + filepath.Join(dir, "status") + ")\nexit 0\n"
+
+	err := os.WriteFile(script, []byte(content), 0700)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	return script
+}
+
+func TestQuorumExec(t *testing.T) {
+	dir := t.TempDir()
+	script := writeQuorumScript(t, dir)
+
+	for _, tc := range []struct {
+		name   string
+		status string
+		done   bool
+	}{
+		{"exit zero", "0", true},
+		{"exit non-zero", "3", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			os.Remove(filepath.Join(dir, "received"))
+
+			err := os.WriteFile(filepath.Join(dir, "status"), []byte(tc.status), 0600)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			var errBuf bytes.Buffer
+
+			done, err := quorumExec(script, &errBuf)("7uPIBqGKMPpProBYFFR3S")
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if done != tc.done {
+				t.Errorf("want done %v, have %v", tc.done, done)
+			}
+
+			received, err := os.ReadFile(filepath.Join(dir, "received"))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if string(received) != "7uPIBqGKMPpProBYFFR3S\n" {
+				t.Errorf("unexpected secret on stdin: %q", received)
+			}
+		})
+	}
+}
+
+func TestQuorumExec_missingScript(t *testing.T) {
+	_, err := quorumExec(filepath.Join(t.TempDir(), "missing.sh"), io.Discard)("secret")
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestQuorumHTTPPost(t *testing.T) {
+	var received []string
+
+	status := http.StatusInternalServerError
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := io.ReadAll(r.Body)
+		received = append(received, string(buf))
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	handler := quorumHTTPPost(srv.Client(), srv.URL, io.Discard)
+
+	done, err := handler("first")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if done {
+		t.Error("handler accepted a failed post")
+	}
+
+	status = http.StatusNoContent
+
+	done, err = handler("second")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !done {
+		t.Error("handler declined a successful post")
+	}
+
+	if strings.Join(received, "") != "first\nsecond\n" {
+		t.Errorf("unexpected posted secrets: %q", received)
+	}
+}
+
+func TestDaemon_onQuorumExec(t *testing.T) {
+	dir := t.TempDir()
+	scriptDir := t.TempDir()
+	script := writeQuorumScript(t, scriptDir)
+
+	writeShareFiles(t, dir,
+		"1,19943338053965968504353533017903769217",
+		"2,161872477868088873785792630750634181303",
+	)
+
+	var (
+		audit  bytes.Buffer
+		outBuf bytes.Buffer
+	)
+
+	d := newDaemon(dir, 3, &audit)
+	d.poll = time.Hour // Rely on the directory watch.
+	d.onQuorum = quorumExec(script, io.Discard)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+
+		err := os.WriteFile(filepath.Join(dir, "share-5.txt"), []byte("5,160274174127002500413544256698187925606\n"), 0600)
+		if err != nil {
+			t.Error(err)
+		}
+	}()
+
+	err := d.run(ctx, io.Discard, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if outBuf.Len() != 0 {
+		t.Errorf("secret written to output: %q", outBuf.String())
+	}
+
+	received, err := os.ReadFile(filepath.Join(scriptDir, "received"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := "7uPIBqGKMPpProBYFFR3S\n"
+	if string(received) != want {
+		t.Errorf("unexpected secrets received by the script. want %q, have %q", want, received)
+	}
+
+	if !strings.Contains(audit.String(), "quorum handler accepted shares=3") {
+		t.Errorf("missing accepted quorum in audit log: %q", audit.String())
+	}
+}
+
+func TestDaemon_onQuorumDeclined(t *testing.T) {
+	dir := t.TempDir()
+	writeShareFiles(t, dir,
+		"1,19943338053965968504353533017903769217",
+		"2,161872477868088873785792630750634181303",
+		"5,160274174127002500413544256698187925606",
+	)
+
+	d := newDaemon(dir, 3, io.Discard)
+	d.poll = 10 * time.Millisecond
+	d.settle = 0
+
+	// The first quorum is declined, the daemon keeps watching until the next share arrives.
+	var calls []string
+
+	declined := make(chan struct{})
+
+	d.onQuorum = func(secret string) (bool, error) {
+		calls = append(calls, secret)
+		if len(calls) == 1 {
+			close(declined)
+			return false, nil
+		}
+
+		return true, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	go func() {
+		<-declined
+
+		// Polling the unchanged directory does not call the handler again.
+		time.Sleep(50 * time.Millisecond)
+
+		err := os.WriteFile(filepath.Join(dir, "share-3.txt"), []byte("3,90786344267911571088064697511688256624\n"), 0600)
+		if err != nil {
+			t.Error(err)
+		}
+	}()
+
+	err := d.run(ctx, io.Discard, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := []string{"7uPIBqGKMPpProBYFFR3S", "7uPIBqGKMPpProBYFFR3S"}
+	if strings.Join(calls, " ") != strings.Join(want, " ") {
+		t.Errorf("unexpected handler calls. want %q, have %q", want, calls)
+	}
+}
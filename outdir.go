@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// outDirSink writes each share line to its own file share-<index>.txt in a directory, which is created if it does not
+// exist. These are the share files that -shares-dir reads.
+type outDirSink struct {
+	dir string
+}
+
+func (o *outDirSink) StoreShares(shares []storedShare) error {
+	err := os.MkdirAll(o.dir, 0700)
+	if err != nil {
+		return err
+	}
+
+	for _, share := range shares {
+		name := filepath.Join(o.dir, "share-"+share.index+".txt")
+
+		fh, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(fh, share.line)
+		if err != nil {
+			fh.Close()
+			return fmt.Errorf("writing %s: %w", name, err)
+		}
+
+		err = fh.Close()
+		if err != nil {
+			return fmt.Errorf("writing %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
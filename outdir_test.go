@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOutDir_roundtrip(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "shares")
+
+	opts := generateOptions{
+		sinks:          []shareSink{&outDirSink{dir: dir}},
+		ceremonyID:     "2026-10",
+		withholdShares: true,
+	}
+
+	var genBuf bytes.Buffer
+
+	err := cmdGenerate(5, 3, opts, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	names, err := filepath.Glob(filepath.Join(dir, "share-*.txt"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(names) != 5 {
+		t.Fatalf("want 5 share files, have %d", len(names))
+	}
+
+	var lines []string
+
+	for _, name := range names {
+		content, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		_, share := splitCeremonyID(strings.TrimSpace(string(content)))
+
+		_, err = parseShare(share)
+		if err != nil {
+			t.Errorf("reading %s: %s", name, err)
+		}
+
+		lines = append(lines, string(content))
+	}
+
+	var outBuf bytes.Buffer
+
+	err = cmdRecover(strings.NewReader(strings.Join(lines[:3], "")), recoverOptions{}, &bytes.Buffer{}, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := strings.TrimPrefix(strings.SplitN(genBuf.String(), "\n", 2)[0], "secret: ")
+	if outBuf.String() != want+"\n" {
+		t.Errorf("unexpected recovered secret. want %q, have %q", want, outBuf.String())
+	}
+
+	if strings.Contains(genBuf.String(), lines[0]) {
+		t.Errorf("share written to the output: %q", genBuf.String())
+	}
+}
+
+func TestOutDir_existingShare(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, "share-1.txt"), []byte("1,2\n"), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err = (&outDirSink{dir: dir}).StoreShares([]storedShare{{"1", "1,19943338053965968504353533017903769217"}})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// passStore stores shares in the pass password manager (https://www.passwordstore.org), one entry <prefix>/share-<index>
+// per share.
+type passStore struct {
+	pass   string // Path of the pass binary.
+	dir    string // The password store, which defaults to $PASSWORD_STORE_DIR or ~/.password-store if empty.
+	prefix string
+}
+
+func newPassStore(dir, prefix string) *passStore {
+	return &passStore{pass: "pass", dir: dir, prefix: prefix}
+}
+
+// storeDir returns the directory of the password store.
+func (p *passStore) storeDir() (string, error) {
+	if p.dir != "" {
+		return p.dir, nil
+	}
+
+	if dir := os.Getenv("PASSWORD_STORE_DIR"); dir != "" {
+		return dir, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".password-store"), nil
+}
+
+// command returns a pass command with args that works on the password store.
+func (p *passStore) command(args ...string) (*exec.Cmd, error) {
+	dir, err := p.storeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(p.pass, args...)
+	cmd.Env = append(os.Environ(), "PASSWORD_STORE_DIR="+dir)
+
+	return cmd, nil
+}
+
+func (p *passStore) StoreShares(shares []storedShare) error {
+	for _, share := range shares {
+		entry := path.Join(p.prefix, "share-"+share.index)
+
+		cmd, err := p.command("insert", "-m", entry)
+		if err != nil {
+			return err
+		}
+
+		var stderr bytes.Buffer
+
+		cmd.Stdin = strings.NewReader(share.line + "\n")
+		cmd.Stderr = &stderr
+
+		err = cmd.Run()
+		if err != nil {
+			return fmt.Errorf("inserting %s into pass: %w: %s", entry, err, strings.TrimSpace(stderr.String()))
+		}
+	}
+
+	return nil
+}
+
+// LoadShares reads the share entries below the prefix with pass show.
+func (p *passStore) LoadShares() ([]string, error) {
+	dir, err := p.storeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := filepath.Glob(filepath.Join(dir, filepath.FromSlash(p.prefix), "share-*.gpg"))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(names) == 0 {
+		return nil, fmt.Errorf("No shares found in %s.", filepath.Join(dir, filepath.FromSlash(p.prefix)))
+	}
+
+	sort.Strings(names)
+
+	var lines []string
+
+	for _, name := range names {
+		entry := path.Join(p.prefix, strings.TrimSuffix(filepath.Base(name), ".gpg"))
+
+		cmd, err := p.command("show", entry)
+		if err != nil {
+			return nil, err
+		}
+
+		var stderr bytes.Buffer
+
+		cmd.Stderr = &stderr
+
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("reading %s from pass: %w: %s", entry, err, strings.TrimSpace(stderr.String()))
+		}
+
+		// The share is the first line of the entry, like the password of pass show -c.
+		lines = append(lines, strings.TrimSpace(strings.SplitN(string(out), "\n", 2)[0]))
+	}
+
+	return lines, nil
+}
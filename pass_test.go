@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeMockPass writes a pass script that keeps the entries unencrypted in <entry>.gpg files of the password store.
+func writeMockPass(t *testing.T) string {
+	t.Helper()
+
+	name := filepath.Join(t.TempDir(), "pass")
+	script := `#!/bin/sh
+set -e
+case "$1" in
+insert)
+	[ "$2" = "-m" ] || exit 1
+	mkdir -p "$(dirname "$PASSWORD_STORE_DIR/$3")"
+	cat > "$PASSWORD_STORE_DIR/$3.gpg"
+	;;
+show)
+	cat "$PASSWORD_STORE_DIR/$2.gpg"
+	;;
+*)
+	echo "unsupported command $1" >&2
+	exit 1
+	;;
+esac
+`
+
+	err := os.WriteFile(name, []byte(script), 0700)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	return name
+}
+
+func TestPass_roundtrip(t *testing.T) {
+	store := newPassStore("", "ceremonies/2026")
+	store.pass = writeMockPass(t)
+
+	dir := t.TempDir()
+	t.Setenv("PASSWORD_STORE_DIR", dir)
+
+	var genBuf bytes.Buffer
+
+	err := cmdGenerate(3, 2, generateOptions{sinks: []shareSink{store}}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	names, _ := filepath.Glob(filepath.Join(dir, "ceremonies", "2026", "share-*.gpg"))
+	if len(names) != 3 {
+		t.Fatalf("want 3 entries, have %q", names)
+	}
+
+	lines, err := store.LoadShares()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, line := range lines {
+		if !strings.Contains(genBuf.String(), "\n"+line+"\n") {
+			t.Errorf("share %q was not generated", line)
+		}
+	}
+
+	var outBuf bytes.Buffer
+
+	err = cmdRecover(strings.NewReader(strings.Join(lines[:2], "\n")), recoverOptions{}, &bytes.Buffer{}, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !strings.HasPrefix(genBuf.String(), "secret: "+outBuf.String()) {
+		t.Errorf("unexpected recovered secret %q", outBuf.String())
+	}
+}
+
+func TestPass_storeDir(t *testing.T) {
+	t.Setenv("PASSWORD_STORE_DIR", "/from/env")
+
+	dir, err := newPassStore("/from/flag", "").storeDir()
+	if err != nil || dir != "/from/flag" {
+		t.Errorf("unexpected store dir. want %q, have %q (%v)", "/from/flag", dir, err)
+	}
+
+	dir, err = newPassStore("", "").storeDir()
+	if err != nil || dir != "/from/env" {
+		t.Errorf("unexpected store dir. want %q, have %q (%v)", "/from/env", dir, err)
+	}
+}
+
+func TestPass_errors(t *testing.T) {
+	store := newPassStore(t.TempDir(), "")
+	store.pass = writeMockPass(t)
+
+	_, err := store.LoadShares()
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	store.pass = filepath.Join(t.TempDir(), "missing")
+
+	err = store.StoreShares([]storedShare{{"1", "1,19943338053965968504353533017903769217"}})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
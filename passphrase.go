@@ -0,0 +1,150 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/term"
+)
+
+// Argon2id parameters for deriving a secret from a passphrase.
+const (
+	passphraseTime    = 3
+	passphraseMemory  = 64 * 1024 // KiB
+	passphraseThreads = 4
+	passphraseKeyLen  = 32
+	passphraseSaltLen = 16
+)
+
+// Output headers of a secret masked with a key derived from a passphrase. The salt header carries the salt of the key
+// and the check header a value derived along with the key that tells whether a typed passphrase is correct.
+const (
+	saltHeader            = "salt"
+	passphraseCheckHeader = "passphrase-check"
+	passphraseCheckLen    = 16
+)
+
+// derivePassphraseSecret derives a 32 byte secret from passphrase with Argon2id.
+func derivePassphraseSecret(passphrase, salt []byte) *big.Int {
+	key := argon2.IDKey(passphrase, salt, passphraseTime, passphraseMemory, passphraseThreads, passphraseKeyLen)
+
+	return new(big.Int).SetBytes(key)
+}
+
+// derivePassphraseMask derives a 32 byte mask for a secret and a check value from passphrase with Argon2id.
+func derivePassphraseMask(passphrase, salt []byte) (*big.Int, []byte) {
+	key := argon2.IDKey(passphrase, salt, passphraseTime, passphraseMemory, passphraseThreads, passphraseKeyLen+passphraseCheckLen)
+
+	return new(big.Int).SetBytes(key[:passphraseKeyLen]), key[passphraseKeyLen:]
+}
+
+// newPassphraseSecret generates a random 32 byte secret and masks it with a key derived from passphrase and a random
+// salt. The masked secret is split, so that the shares alone do not reveal the secret. It returns the secret, the
+// masked secret and the header lines needed to unmask it.
+func newPassphraseSecret(passphrase []byte) (secret, masked *big.Int, header []string, err error) {
+	raw := make([]byte, passphraseKeyLen)
+
+	_, err = rand.Read(raw)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	salt := make([]byte, passphraseSaltLen)
+
+	_, err = rand.Read(salt)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	mask, check := derivePassphraseMask(passphrase, salt)
+
+	secret = new(big.Int).SetBytes(raw)
+	header = []string{
+		saltHeader + ": " + base64.StdEncoding.EncodeToString(salt),
+		passphraseCheckHeader + ": " + base64.StdEncoding.EncodeToString(check),
+	}
+
+	return secret, new(big.Int).Xor(secret, mask), header, nil
+}
+
+// unmaskPassphraseSecret recovers the secret from a masked secret with passphrase and the headers written by
+// newPassphraseSecret.
+func unmaskPassphraseSecret(masked *big.Int, passphrase []byte, headers map[string]string) (*big.Int, error) {
+	salt, ok := headers[saltHeader]
+	if !ok {
+		return nil, errors.New("Shares do not contain a passphrase salt.")
+	}
+
+	rawSalt, err := base64.StdEncoding.DecodeString(salt)
+	if err != nil {
+		return nil, fmt.Errorf("decoding salt: %w", err)
+	}
+
+	check, err := base64.StdEncoding.DecodeString(headers[passphraseCheckHeader])
+	if err != nil {
+		return nil, fmt.Errorf("decoding passphrase check: %w", err)
+	}
+
+	mask, want := derivePassphraseMask(passphrase, rawSalt)
+
+	if subtle.ConstantTimeCompare(want, check) != 1 {
+		return nil, errors.New("Passphrase does not match the shares.")
+	}
+
+	return new(big.Int).Xor(masked, mask), nil
+}
+
+// readPassphrase reads a passphrase from the terminal without echo. The prompt is written to stderr. The controlling
+// terminal is used if stdin is not a terminal, for example because the shares are read from stdin.
+func readPassphrase(prompt string) ([]byte, error) {
+	tty := os.Stdin
+
+	if !term.IsTerminal(int(tty.Fd())) {
+		var err error
+
+		tty, err = os.Open("/dev/tty")
+		if err != nil {
+			return nil, fmt.Errorf("reading passphrase: %w", err)
+		}
+		defer tty.Close()
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	defer fmt.Fprintln(os.Stderr)
+
+	passphrase, err := term.ReadPassword(int(tty.Fd()))
+	if err != nil {
+		return nil, fmt.Errorf("reading passphrase: %w", err)
+	}
+
+	if len(passphrase) == 0 {
+		return nil, errors.New("Passphrase must not be empty.")
+	}
+
+	return passphrase, nil
+}
+
+// readNewPassphrase reads a passphrase from the terminal twice and checks that both match.
+func readNewPassphrase() ([]byte, error) {
+	passphrase, err := readPassphrase("Passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+
+	repeated, err := readPassphrase("Repeat passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare(passphrase, repeated) != 1 {
+		return nil, errors.New("Passphrases do not match.")
+	}
+
+	return passphrase, nil
+}
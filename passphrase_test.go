@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPassphrase_roundtrip(t *testing.T) {
+	secret, masked, header, err := newPassphraseSecret([]byte("correct horse battery staple"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if masked.Cmp(secret) == 0 {
+		t.Fatal("secret is not masked")
+	}
+
+	var genBuf bytes.Buffer
+
+	err = cmdGenerate(5, 3, generateOptions{secret: masked, printSecret: secret, header: header}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !strings.HasPrefix(genBuf.String(), "secret: "+secret.Text(62)+"\n") {
+		t.Fatalf("output does not start with the secret: %q", genBuf.String())
+	}
+
+	if !strings.Contains(genBuf.String(), "\n"+header[0]+"\n") {
+		t.Fatalf("output does not contain the salt header: %q", genBuf.String())
+	}
+
+	// Without the passphrase, the shares only reveal the masked secret.
+	err = cmdRecover(strings.NewReader(genBuf.String()), recoverOptions{}, &bytes.Buffer{}, &bytes.Buffer{})
+	if err == nil {
+		t.Error("expected an error for recovery without the passphrase")
+	}
+
+	for _, tc := range []struct {
+		name       string
+		passphrase string
+		wantErr    bool
+	}{
+		{"correct passphrase", "correct horse battery staple", false},
+		{"wrong passphrase", "incorrect horse battery staple", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				errBuf bytes.Buffer
+				outBuf bytes.Buffer
+			)
+
+			opts := recoverOptions{
+				passphrase: func() ([]byte, error) {
+					return []byte(tc.passphrase), nil
+				},
+			}
+
+			err := cmdRecover(strings.NewReader(genBuf.String()), opts, &errBuf, &outBuf)
+			if errBuf.Len() != 0 {
+				t.Errorf("unexpected diagnostic: %q", errBuf.String())
+			}
+
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, recovered %q", outBuf.String())
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if want := secret.Text(62) + "\n"; outBuf.String() != want {
+				t.Errorf("unexpected recovered secret. want %q, have %q", want, outBuf.String())
+			}
+		})
+	}
+}
+
+func TestPassphrase_missingSalt(t *testing.T) {
+	in := strings.NewReader(`1,19943338053965968504353533017903769217
+2,161872477868088873785792630750634181303
+5,160274174127002500413544256698187925606
+`)
+
+	opts := recoverOptions{
+		passphrase: func() ([]byte, error) {
+			t.Error("passphrase read without a salt")
+			return nil, nil
+		},
+	}
+
+	err := cmdRecover(in, opts, &bytes.Buffer{}, &bytes.Buffer{})
+	if err == nil {
+		t.Error("expected an error for shares without a salt")
+	}
+}
@@ -0,0 +1,236 @@
+// Package pedersen implements Pedersen's verifiable secret sharing scheme. The coefficients of the sharing polynomial
+// are committed to with two generators g and h of a prime order group, so that every share can be checked against the
+// published commitments without learning anything about the coefficients or the secret.
+package pedersen
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+)
+
+var (
+	// ErrInvalidThreshold is returned when the threshold is smaller than 1 or larger than the number of shares.
+	ErrInvalidThreshold = errors.New("pedersen: invalid threshold")
+
+	// ErrSecretRange is returned when the secret is negative or not smaller than the order of the group.
+	ErrSecretRange = errors.New("pedersen: secret out of range")
+
+	// ErrDuplicateShare is returned when recovering from two shares with the same index.
+	ErrDuplicateShare = errors.New("pedersen: duplicate share")
+
+	// ErrNoShares is returned when recovering from no shares at all.
+	ErrNoShares = errors.New("pedersen: no shares")
+)
+
+// Group is a subgroup of prime order Q of the multiplicative group modulo the safe prime P = 2Q+1, along with two
+// generators G and H of it. Nobody may know the discrete logarithm of H to the base G, or the commitments are not
+// binding.
+type Group struct {
+	P, Q *big.Int
+	G, H *big.Int
+}
+
+// oakleyGroup14 is the 2048 bit safe prime of the MODP group 14 from RFC 3526.
+const oakleyGroup14 = "FFFFFFFFFFFFFFFFC90FDAA22168C234C4C6628B80DC1CD129024E088A67CC74020BBEA63B139B22514A08798E3404DDEF9519B3CD3A431B302B0A6DF25F14374FE1356D6D51C245E485B576625E7EC6F44C42E9A637ED6B0BFF5CB6F406B7EDEE386BFB5A899FA5AE9F24117C4B1FE649286651ECE45B3DC2007CB8A163BF0598DA48361C55D39A69163FA8FD24CF5F83655D23DCA3AD961C62F356208552BB9ED529077096966D670C354E4ABC9804F1746C08CA18217C32905E462E36CE3BE39E772C180E86039B2783A2EC07A28FB5C55DF06F4C52C9DE2BCBF6955817183995497CEA956AE515D2261898FA051015728E5A8AACAA68FFFFFFFFFFFFFFFF"
+
+// DefaultGroup is the subgroup of quadratic residues modulo the RFC 3526 group 14 prime. G is 2, which is a quadratic
+// residue because P is 7 modulo 8, and H is derived by hashing, so that its discrete logarithm is unknown.
+var DefaultGroup = newDefaultGroup()
+
+func newDefaultGroup() *Group {
+	p, _ := new(big.Int).SetString(oakleyGroup14, 16)
+	q := new(big.Int).Rsh(p, 1)
+
+	return &Group{P: p, Q: q, G: big.NewInt(2), H: hashToGroup(p, "github.com/farhaven/secret/pedersen h")}
+}
+
+// hashToGroup hashes seed to a quadratic residue modulo the safe prime p.
+func hashToGroup(p *big.Int, seed string) *big.Int {
+	var buf []byte
+
+	// Hash to more bits than p has, so that the reduction modulo p is close to uniform.
+	for i := 0; len(buf) < len(p.Bytes())+32; i++ {
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%s %d", seed, i)))
+		buf = append(buf, sum[:]...)
+	}
+
+	x := new(big.Int).SetBytes(buf)
+	x.Mod(x, p)
+
+	return x.Exp(x, big.NewInt(2), p)
+}
+
+// Share is a share of a secret. Y is the value of the sharing polynomial at X, and R is the value of the blinding
+// polynomial at X.
+type Share struct {
+	X, Y, R *big.Int
+}
+
+// String returns the share as comma separated hexadecimal numbers x,y,r.
+func (s Share) String() string {
+	return s.X.Text(16) + "," + s.Y.Text(16) + "," + s.R.Text(16)
+}
+
+// ParseShare parses a share in the format written by String.
+func ParseShare(text string) (Share, error) {
+	parts := strings.Split(text, ",")
+	if len(parts) != 3 {
+		return Share{}, fmt.Errorf("pedersen: expected three parts, have %d", len(parts))
+	}
+
+	var nums [3]*big.Int
+
+	for i, part := range parts {
+		n, ok := new(big.Int).SetString(part, 16)
+		if !ok {
+			return Share{}, fmt.Errorf("pedersen: invalid number %q", part)
+		}
+
+		nums[i] = n
+	}
+
+	return Share{X: nums[0], Y: nums[1], R: nums[2]}, nil
+}
+
+// Commit shares secret into n shares, k of which are needed to recover it, and returns the shares along with the
+// commitments C_i = G^a_i * H^r_i mod P to the coefficients a_i of the sharing polynomial. The shares have the indices
+// 1 to n. The coefficients are read from random.
+func Commit(group *Group, secret *big.Int, n, k int, random io.Reader) ([]Share, []*big.Int, error) {
+	if k < 1 || k > n {
+		return nil, nil, ErrInvalidThreshold
+	}
+
+	if secret.Sign() < 0 || secret.Cmp(group.Q) >= 0 {
+		return nil, nil, ErrSecretRange
+	}
+
+	coefficients := make([]*big.Int, k)
+	blinding := make([]*big.Int, k)
+
+	for i := range coefficients {
+		var err error
+
+		coefficients[i], err = rand.Int(random, group.Q)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		blinding[i], err = rand.Int(random, group.Q)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	coefficients[0] = new(big.Int).Set(secret)
+
+	shares, commitments := commit(group, coefficients, blinding, n)
+
+	return shares, commitments, nil
+}
+
+// commit evaluates the sharing polynomial with coefficients and the blinding polynomial at 1 to n, and commits to
+// their coefficients.
+func commit(group *Group, coefficients, blinding []*big.Int, n int) ([]Share, []*big.Int) {
+	commitments := make([]*big.Int, len(coefficients))
+	for i := range coefficients {
+		c := new(big.Int).Exp(group.G, coefficients[i], group.P)
+		c.Mul(c, new(big.Int).Exp(group.H, blinding[i], group.P))
+		commitments[i] = c.Mod(c, group.P)
+	}
+
+	shares := make([]Share, n)
+	for i := range shares {
+		x := big.NewInt(int64(i + 1))
+		shares[i] = Share{X: x, Y: evaluate(coefficients, x, group.Q), R: evaluate(blinding, x, group.Q)}
+	}
+
+	return shares, commitments
+}
+
+// evaluate evaluates the polynomial with coefficients at x modulo m.
+func evaluate(coefficients []*big.Int, x, m *big.Int) *big.Int {
+	y := new(big.Int)
+
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		y.Mul(y, x)
+		y.Add(y, coefficients[i])
+		y.Mod(y, m)
+	}
+
+	return y
+}
+
+// VerifyShare reports whether share is consistent with commitments, that is whether
+// G^y * H^r = C_0 * C_1^x * ... * C_(k-1)^(x^(k-1)) mod P.
+func VerifyShare(group *Group, share Share, commitments []*big.Int) bool {
+	for _, n := range []*big.Int{share.X, share.Y, share.R} {
+		if n == nil || n.Sign() < 0 || n.Cmp(group.Q) >= 0 {
+			return false
+		}
+	}
+
+	if share.X.Sign() == 0 || len(commitments) == 0 {
+		return false
+	}
+
+	lhs := new(big.Int).Exp(group.G, share.Y, group.P)
+	lhs.Mul(lhs, new(big.Int).Exp(group.H, share.R, group.P))
+	lhs.Mod(lhs, group.P)
+
+	rhs := big.NewInt(1)
+	power := big.NewInt(1)
+
+	for _, c := range commitments {
+		rhs.Mul(rhs, new(big.Int).Exp(c, power, group.P))
+		rhs.Mod(rhs, group.P)
+
+		power.Mul(power, share.X)
+		power.Mod(power, group.Q)
+	}
+
+	return lhs.Cmp(rhs) == 0
+}
+
+// RecoverSecret recovers the secret from shares by Lagrange interpolation at 0. The result is only the secret if there
+// are at least as many shares as the threshold they were created with, which VerifyShare can not tell.
+func RecoverSecret(group *Group, shares []Share) (*big.Int, error) {
+	if len(shares) == 0 {
+		return nil, ErrNoShares
+	}
+
+	secret := new(big.Int)
+
+	for i, si := range shares {
+		num := big.NewInt(1)
+		den := big.NewInt(1)
+
+		for j, sj := range shares {
+			if i == j {
+				continue
+			}
+
+			if si.X.Cmp(sj.X) == 0 {
+				return nil, ErrDuplicateShare
+			}
+
+			num.Mul(num, sj.X)
+			num.Mod(num, group.Q)
+
+			d := new(big.Int).Sub(sj.X, si.X)
+			den.Mul(den, d)
+			den.Mod(den, group.Q)
+		}
+
+		term := new(big.Int).Mul(si.Y, num)
+		term.Mul(term, new(big.Int).ModInverse(den, group.Q))
+		secret.Add(secret, term)
+		secret.Mod(secret, group.Q)
+	}
+
+	return secret, nil
+}
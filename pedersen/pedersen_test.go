@@ -0,0 +1,152 @@
+package pedersen
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// testGroup is the subgroup of order 11 of the integers modulo 23, which is small enough to compute the test vectors
+// by hand.
+var testGroup = &Group{P: big.NewInt(23), Q: big.NewInt(11), G: big.NewInt(4), H: big.NewInt(9)}
+
+func ints(vs ...int64) []*big.Int {
+	out := make([]*big.Int, len(vs))
+	for i, v := range vs {
+		out[i] = big.NewInt(v)
+	}
+
+	return out
+}
+
+func TestCommit_knownAnswer(t *testing.T) {
+	shares, commitments := commit(testGroup, ints(7, 3, 5), ints(5, 2, 8), 5)
+
+	for i, want := range []int64{18, 9, 18} {
+		if commitments[i].Int64() != want {
+			t.Errorf("unexpected commitment %d. want %d, have %s", i, want, commitments[i])
+		}
+	}
+
+	for i, want := range []string{"1,4,4", "2,0,8", "3,6,6", "4,0,9", "5,4,6"} {
+		if shares[i].String() != want {
+			t.Errorf("unexpected share %d. want %q, have %q", i, want, shares[i])
+		}
+
+		if !VerifyShare(testGroup, shares[i], commitments) {
+			t.Errorf("share %s does not verify", shares[i])
+		}
+	}
+
+	secret, err := RecoverSecret(testGroup, []Share{shares[4], shares[0], shares[2]})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if secret.Int64() != 7 {
+		t.Errorf("unexpected secret. want 7, have %s", secret)
+	}
+}
+
+func TestVerifyShare_tampered(t *testing.T) {
+	shares, commitments := commit(testGroup, ints(7, 3, 5), ints(5, 2, 8), 5)
+
+	for _, share := range []Share{
+		{X: big.NewInt(1), Y: big.NewInt(5), R: big.NewInt(4)},
+		{X: big.NewInt(1), Y: big.NewInt(4), R: big.NewInt(5)},
+		{X: big.NewInt(2), Y: big.NewInt(4), R: big.NewInt(4)},
+		{X: big.NewInt(0), Y: big.NewInt(7), R: big.NewInt(5)},
+		{X: big.NewInt(1), Y: big.NewInt(15), R: big.NewInt(4)},
+	} {
+		if VerifyShare(testGroup, share, commitments) {
+			t.Errorf("tampered share %s verifies", share)
+		}
+	}
+
+	if VerifyShare(testGroup, shares[0], ints(18, 9, 17)) {
+		t.Error("share verifies against tampered commitments")
+	}
+}
+
+func TestCommit_defaultGroup(t *testing.T) {
+	secret := new(big.Int).Lsh(big.NewInt(1), 126)
+
+	shares, commitments, err := Commit(DefaultGroup, secret, 5, 3, rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, share := range shares {
+		parsed, err := ParseShare(share.String())
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if !VerifyShare(DefaultGroup, parsed, commitments) {
+			t.Errorf("share %s does not verify", share.X)
+		}
+	}
+
+	have, err := RecoverSecret(DefaultGroup, shares[2:])
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if have.Cmp(secret) != 0 {
+		t.Errorf("unexpected secret. want %s, have %s", secret, have)
+	}
+
+	have, err = RecoverSecret(DefaultGroup, shares[:2])
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if have.Cmp(secret) == 0 {
+		t.Error("secret recovered from fewer shares than the threshold")
+	}
+}
+
+func TestCommit_errors(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		secret *big.Int
+		n, k   int
+		want   error
+	}{
+		{"zero threshold", big.NewInt(1), 3, 0, ErrInvalidThreshold},
+		{"threshold above n", big.NewInt(1), 3, 4, ErrInvalidThreshold},
+		{"negative secret", big.NewInt(-1), 3, 2, ErrSecretRange},
+		{"secret too large", big.NewInt(11), 3, 2, ErrSecretRange},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := Commit(testGroup, tc.secret, tc.n, tc.k, rand.Reader)
+			if !errors.Is(err, tc.want) {
+				t.Errorf("unexpected error. want %v, have %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestRecoverSecret_errors(t *testing.T) {
+	_, err := RecoverSecret(testGroup, nil)
+	if !errors.Is(err, ErrNoShares) {
+		t.Errorf("unexpected error. want %v, have %v", ErrNoShares, err)
+	}
+
+	share := Share{X: big.NewInt(1), Y: big.NewInt(4), R: big.NewInt(4)}
+
+	_, err = RecoverSecret(testGroup, []Share{share, share})
+	if !errors.Is(err, ErrDuplicateShare) {
+		t.Errorf("unexpected error. want %v, have %v", ErrDuplicateShare, err)
+	}
+}
+
+func TestParseShare_invalid(t *testing.T) {
+	for _, s := range []string{"", "1,2", "1,2,3,4", "1,2,z"} {
+		_, err := ParseShare(s)
+		if err == nil {
+			t.Errorf("expected error for %q, got nil", s)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/farhaven/secret/shamir"
+	"github.com/posener/sharedsecret"
+)
+
+// pemShareType is the type of the PEM blocks shares are written in with -format pem.
+const pemShareType = "SECRET SHARE"
+
+// Headers of a share PEM block.
+const (
+	pemIndexHeader      = "Index"
+	pemThresholdHeader  = "Threshold"
+	pemCeremonyIDHeader = "Ceremony-ID"
+)
+
+// marshalPEMShare returns share as a PEM block with the big endian bytes of its value as the body. The threshold k and
+// the ceremony ID are written as headers. The ceremony ID header is left out if ceremonyID is empty.
+func marshalPEMShare(share sharedsecret.Share, k int, ceremonyID string) string {
+	x, y := shamir.ShareXY(share)
+
+	block := &pem.Block{
+		Type: pemShareType,
+		Headers: map[string]string{
+			pemIndexHeader:     x.String(),
+			pemThresholdHeader: strconv.Itoa(k),
+		},
+		Bytes: y.Bytes(),
+	}
+
+	if ceremonyID != "" {
+		block.Headers[pemCeremonyIDHeader] = ceremonyID
+	}
+
+	return strings.TrimSuffix(string(pem.EncodeToMemory(block)), "\n")
+}
+
+// pemShareLine converts a share PEM block to a share line "index,value,k", prefixed with the ceremony ID of the
+// block if it has one.
+func pemShareLine(block string) (string, error) {
+	b, _ := pem.Decode([]byte(block))
+	if b == nil || b.Type != pemShareType {
+		return "", errors.New("Invalid share PEM block.")
+	}
+
+	x, ok := new(big.Int).SetString(b.Headers[pemIndexHeader], 10)
+	if !ok {
+		return "", fmt.Errorf("Invalid index %q in share PEM block.", b.Headers[pemIndexHeader])
+	}
+
+	line := shamir.NewShare(x, new(big.Int).SetBytes(b.Bytes)).String()
+
+	if v, ok := b.Headers[pemThresholdHeader]; ok {
+		k, err := strconv.Atoi(v)
+		if err != nil || k < 1 {
+			return "", fmt.Errorf("Invalid threshold %q in share PEM block.", v)
+		}
+
+		line += "," + strconv.Itoa(k)
+	}
+
+	return withCeremonyID(b.Headers[pemCeremonyIDHeader], line), nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"encoding/pem"
+	"strings"
+	"testing"
+)
+
+func TestPEM_roundTrip(t *testing.T) {
+	var genBuf bytes.Buffer
+
+	err := cmdGenerate(5, 3, generateOptions{format: "pem", ceremonyID: "2026-10"}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	secret, rest, _ := strings.Cut(genBuf.String(), "\n")
+	secret = strings.TrimPrefix(secret, "secret: ")
+
+	var blocks []string
+
+	for data := []byte(rest); ; {
+		var block *pem.Block
+
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		if block.Type != pemShareType || block.Headers[pemIndexHeader] == "" || block.Headers[pemThresholdHeader] != "3" || block.Headers[pemCeremonyIDHeader] != "2026-10" {
+			t.Errorf("unexpected block: %+v", block)
+		}
+
+		blocks = append(blocks, string(pem.EncodeToMemory(block)))
+	}
+
+	if len(blocks) != 5 {
+		t.Fatalf("want 5 PEM blocks, have %d in %q", len(blocks), rest)
+	}
+
+	var (
+		errBuf bytes.Buffer
+		outBuf bytes.Buffer
+	)
+
+	err = cmdRecover(strings.NewReader(strings.Join(blocks[1:4], "")), recoverOptions{}, &errBuf, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if outBuf.String() != secret+"\n" {
+		t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
+	}
+
+	if errBuf.String() != "need at least 3 shares\n" {
+		t.Errorf("unexpected diagnostic: %q", errBuf.String())
+	}
+}
+
+func TestPEM_errors(t *testing.T) {
+	for _, opts := range []generateOptions{
+		{format: "pem", labels: []string{"a", "b", "c"}},
+		{format: "pem", hmacKey: []byte("key")},
+		{format: "pem", encoding: base58Encoding},
+	} {
+		err := cmdGenerate(3, 2, opts, &bytes.Buffer{})
+		if err == nil {
+			t.Errorf("expected error for %+v, got nil", opts)
+		}
+	}
+
+	for _, input := range []string{
+		"-----BEGIN SECRET SHARE-----\nIndex: 1\n\nAQ==\n",
+		"-----BEGIN SECRET SHARE-----\nIndex: x\n\nAQ==\n-----END SECRET SHARE-----\n",
+		"-----BEGIN SECRET SHARE-----\nIndex: 1\nThreshold: 0\n\nAQ==\n-----END SECRET SHARE-----\n",
+	} {
+		err := cmdRecover(strings.NewReader(input), recoverOptions{}, &bytes.Buffer{}, &bytes.Buffer{})
+		if err == nil {
+			t.Errorf("expected error for %q, got nil", input)
+		}
+	}
+}
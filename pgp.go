@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+)
+
+// pgpArmorType is the armor type of PGP encrypted shares.
+const pgpArmorType = "PGP MESSAGE"
+
+// pgpEncryptShare encrypts a share line with PGP symmetric encryption and AES-256, and returns the ASCII armored
+// message. A new salt and IV are used for every share.
+func pgpEncryptShare(line string, passphrase []byte) (string, error) {
+	if len(passphrase) == 0 {
+		return "", errors.New("Passphrase must not be empty.")
+	}
+
+	var buf bytes.Buffer
+
+	aw, err := armor.Encode(&buf, pgpArmorType, nil)
+	if err != nil {
+		return "", err
+	}
+
+	config := &packet.Config{DefaultCipher: packet.CipherAES256}
+
+	w, err := openpgp.SymmetricallyEncrypt(aw, passphrase, nil, config)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = io.WriteString(w, line)
+	if err != nil {
+		return "", err
+	}
+
+	err = w.Close()
+	if err != nil {
+		return "", err
+	}
+
+	err = aw.Close()
+	if err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// pgpDecryptShare decrypts an ASCII armored message created by pgpEncryptShare.
+func pgpDecryptShare(message string, passphrase []byte) (string, error) {
+	block, err := armor.Decode(strings.NewReader(message))
+	if err != nil {
+		return "", fmt.Errorf("decoding PGP message: %w", err)
+	}
+
+	tried := false
+	prompt := func(keys []openpgp.Key, symmetric bool) ([]byte, error) {
+		// The prompt is called again if the passphrase is wrong.
+		if tried {
+			return nil, errors.New("wrong passphrase")
+		}
+
+		tried = true
+
+		return passphrase, nil
+	}
+
+	md, err := openpgp.ReadMessage(block.Body, nil, prompt, nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypting PGP message: %w", err)
+	}
+
+	buf, err := io.ReadAll(md.UnverifiedBody)
+	if err != nil {
+		return "", fmt.Errorf("decrypting PGP message: %w", err)
+	}
+
+	return strings.TrimSpace(string(buf)), nil
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestPGP_roundtrip(t *testing.T) {
+	passphrase := []byte("correct horse battery staple")
+
+	opts := generateOptions{
+		encryptShare: func(line string) (string, error) {
+			return pgpEncryptShare(line, passphrase)
+		},
+	}
+
+	var genBuf bytes.Buffer
+
+	err := cmdGenerate(5, 3, opts, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	output := genBuf.String()
+	if n := strings.Count(output, "-----BEGIN PGP MESSAGE-----"); n != 5 {
+		t.Fatalf("want 5 PGP messages, have %d: %q", n, output)
+	}
+
+	for _, tc := range []struct {
+		name       string
+		passphrase string
+		wantErr    bool
+	}{
+		{"correct passphrase", string(passphrase), false},
+		{"wrong passphrase", "incorrect horse battery staple", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := recoverOptions{
+				decryptShares: func(in io.Reader) (io.Reader, error) {
+					return decryptArmoredShares(in, pgpArmorType, func(block string) (string, error) {
+						return pgpDecryptShare(block, []byte(tc.passphrase))
+					})
+				},
+			}
+
+			var (
+				errBuf bytes.Buffer
+				outBuf bytes.Buffer
+			)
+
+			err := cmdRecover(strings.NewReader(output), opts, &errBuf, &outBuf)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, recovered %q", outBuf.String())
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if errBuf.Len() != 0 {
+				t.Errorf("unexpected diagnostic: %q", errBuf.String())
+			}
+
+			secret := strings.TrimPrefix(strings.SplitN(output, "\n", 2)[0], "secret: ")
+			if outBuf.String() != secret+"\n" {
+				t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
+			}
+		})
+	}
+}
+
+func TestPGP_distinctMessages(t *testing.T) {
+	passphrase := []byte("passphrase")
+
+	a, err := pgpEncryptShare("1,2", passphrase)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	b, err := pgpEncryptShare("1,2", passphrase)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if a == b {
+		t.Error("encrypting the same share twice gave the same message")
+	}
+}
+
+func TestPGP_sinks(t *testing.T) {
+	passphrase := []byte("correct horse battery staple")
+
+	var stored []storedShare
+
+	opts := generateOptions{
+		sinks: []shareSink{shareSinkFunc(func(shares []storedShare) error {
+			stored = shares
+			return nil
+		})},
+		encryptShare: func(line string) (string, error) {
+			return pgpEncryptShare(line, passphrase)
+		},
+	}
+
+	var genBuf bytes.Buffer
+
+	err := cmdGenerate(5, 3, opts, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(stored) != 5 {
+		t.Fatalf("want 5 stored shares, have %d", len(stored))
+	}
+
+	for _, share := range stored {
+		if !strings.HasPrefix(share.line, "-----BEGIN PGP MESSAGE-----") {
+			t.Fatalf("share %s stored unencrypted: %q", share.index, share.line)
+		}
+
+		if !strings.Contains(genBuf.String(), share.line) {
+			t.Errorf("stored share %s differs from the output", share.index)
+		}
+
+		line, err := pgpDecryptShare(share.line, passphrase)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		_, err = parseShare(line)
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/farhaven/secret/shamir"
+	"github.com/posener/sharedsecret"
+)
+
+// maxGenerateTime is the longest the pre-flight check accepts share generation to take.
+const maxGenerateTime = 30 * time.Second
+
+// preflight describes the prerequisites of a key ceremony. The rand, createTemp and lookupEnv fields allow tests to
+// replace the system facilities that are checked.
+type preflight struct {
+	n, k int
+	dir  string   // Directory the outputs will be written to.
+	env  []string // Environment variables that must be set.
+
+	rand       io.Reader
+	createTemp func(dir, pattern string) (*os.File, error)
+	lookupEnv  func(key string) (string, bool)
+}
+
+func newPreflight(n, k int, dir string) preflight {
+	return preflight{
+		n:          n,
+		k:          k,
+		dir:        dir,
+		rand:       rand.Reader,
+		createTemp: os.CreateTemp,
+		lookupEnv:  os.LookupEnv,
+	}
+}
+
+// cmdPreflight runs all checks of p and prints a checklist to out. It returns an error if any check failed.
+func cmdPreflight(p preflight, out io.Writer) error {
+	failed := false
+
+	check := func(desc string, err error) {
+		if err != nil {
+			failed = true
+			fmt.Fprintf(out, "✗ %s: %s\n", desc, err)
+			return
+		}
+
+		fmt.Fprintf(out, "✓ %s\n", desc)
+	}
+
+	check("crypto/rand is available", p.checkRand())
+	check(fmt.Sprintf("output directory %s is writable", p.dir), p.checkDir())
+
+	for _, key := range p.env {
+		check(fmt.Sprintf("environment variable %s is set", key), p.checkEnv(key))
+	}
+
+	check(fmt.Sprintf("prime is large enough for k=%d", p.k), p.checkPrime())
+	check(fmt.Sprintf("generation finishes within %s", maxGenerateTime), p.checkTime())
+
+	if failed {
+		return errors.New("Pre-flight check failed.")
+	}
+
+	return nil
+}
+
+func (p preflight) checkRand() error {
+	buf := make([]byte, 32)
+
+	_, err := io.ReadFull(p.rand, buf)
+
+	return err
+}
+
+func (p preflight) checkDir() error {
+	fh, err := p.createTemp(p.dir, ".secret-preflight-*")
+	if err != nil {
+		return err
+	}
+
+	fh.Close()
+
+	return os.Remove(fh.Name())
+}
+
+func (p preflight) checkEnv(key string) error {
+	if v, ok := p.lookupEnv(key); !ok || v == "" {
+		return errors.New("not set")
+	}
+
+	return nil
+}
+
+func (p preflight) checkPrime() error {
+	if p.n < 1 || p.k < 1 {
+		return fmt.Errorf("invalid parameters n=%d k=%d", p.n, p.k)
+	}
+
+	if p.k > p.n {
+		return fmt.Errorf("k=%d exceeds n=%d, the secret could not be recovered", p.k, p.n)
+	}
+
+	// The n shares are selected from the pool.
+	if pool := shamir.PoolSize(p.n); pool < int64(p.n) {
+		return fmt.Errorf("pool of %d shares is smaller than n=%d", pool, p.n)
+	}
+
+	return nil
+}
+
+// checkTime generates a small sample of shares and extrapolates how long generating the full pool will take.
+func (p preflight) checkTime() error {
+	if p.k < 1 {
+		return errors.New("invalid threshold")
+	}
+
+	const sample = 100
+
+	k := int64(p.k)
+	if k > sample {
+		k = sample
+	}
+
+	start := time.Now()
+	sharedsecret.New(sample, k)
+	perShare := time.Since(start) / sample
+
+	// The evaluation cost of each share grows linearly with the degree of the polynomial.
+	estimate := perShare * time.Duration(shamir.PoolSize(p.n)) * time.Duration(p.k) / time.Duration(k)
+	if estimate > maxGenerateTime {
+		return fmt.Errorf("estimated %s", estimate)
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPreflight(t *testing.T) {
+	p := newPreflight(5, 3, t.TempDir())
+	p.env = []string{"SECRET_PREFLIGHT_TEST"}
+	p.lookupEnv = func(key string) (string, bool) {
+		return "yes", key == "SECRET_PREFLIGHT_TEST"
+	}
+
+	var buf bytes.Buffer
+
+	err := cmdPreflight(p, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n%s", err, buf.String())
+	}
+
+	if strings.Contains(buf.String(), "✗") {
+		t.Errorf("unexpected failed check: %q", buf.String())
+	}
+
+	if n := strings.Count(buf.String(), "✓"); n != 5 {
+		t.Errorf("want 5 passed checks, have %d: %q", n, buf.String())
+	}
+}
+
+func TestPreflight_failingFilesystem(t *testing.T) {
+	p := newPreflight(5, 3, "/ceremony")
+	p.createTemp = func(dir, pattern string) (*os.File, error) {
+		return nil, errors.New("read-only file system")
+	}
+
+	var buf bytes.Buffer
+
+	err := cmdPreflight(p, &buf)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	want := "✗ output directory /ceremony is writable: read-only file system\n"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("expected output to contain %q, have %q", want, buf.String())
+	}
+}
+
+func TestPreflight_missingEnv(t *testing.T) {
+	p := newPreflight(5, 3, t.TempDir())
+	p.env = []string{"SECRET_PREFLIGHT_TEST"}
+	p.lookupEnv = func(key string) (string, bool) {
+		return "", false
+	}
+
+	var buf bytes.Buffer
+
+	err := cmdPreflight(p, &buf)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	want := "✗ environment variable SECRET_PREFLIGHT_TEST is set: not set\n"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("expected output to contain %q, have %q", want, buf.String())
+	}
+}
+
+func TestPreflight_thresholdExceedsShares(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := cmdPreflight(newPreflight(3, 5, t.TempDir()), &buf)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	want := "✗ prime is large enough for k=5: k=5 exceeds n=3, the secret could not be recovered\n"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("expected output to contain %q, have %q", want, buf.String())
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// printer is a CUPS printer and the custodian that collects the share printed on it.
+type printer struct {
+	Name      string `json:"printer"`
+	Custodian string `json:"custodian"`
+}
+
+// parsePrinterList parses a JSON printer list like [{"printer":"lab-1","custodian":"Alice"}].
+func parsePrinterList(list string) ([]printer, error) {
+	var printers []printer
+
+	err := json.Unmarshal([]byte(list), &printers)
+	if err != nil {
+		return nil, fmt.Errorf("parsing printer list: %w", err)
+	}
+
+	for _, p := range printers {
+		if p.Name == "" {
+			return nil, errors.New("Printer list contains a printer without a name.")
+		}
+	}
+
+	return printers, nil
+}
+
+// printerSink prints each share on a different printer with lp. After each print job it waits until the operator
+// confirms that the custodian collected the share.
+type printerSink struct {
+	printers []printer
+	lp       string // The lp command, usually "lp".
+
+	confirm *bufio.Reader
+	prompt  io.Writer
+}
+
+func newPrinterSink(printers []printer, confirm io.Reader, prompt io.Writer) *printerSink {
+	return &printerSink{printers: printers, lp: "lp", confirm: bufio.NewReader(confirm), prompt: prompt}
+}
+
+func (p *printerSink) StoreShares(shares []storedShare) error {
+	if len(shares) > len(p.printers) {
+		return fmt.Errorf("Need a printer for each of the %d shares, have %d.", len(shares), len(p.printers))
+	}
+
+	for i, share := range shares {
+		pr := p.printers[i]
+
+		title := pr.Custodian
+		if title == "" {
+			title = "share " + share.index
+		}
+
+		cmd := exec.Command(p.lp, "-d", pr.Name, "-t", title)
+		cmd.Stdin = strings.NewReader(share.line + "\n")
+
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("printing share on %s: %w: %s", pr.Name, err, bytes.TrimSpace(out))
+		}
+
+		fmt.Fprintf(p.prompt, "Share printed on %s for %s. Press enter once it has been collected.\n", pr.Name, title)
+
+		_, err = p.confirm.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("waiting for confirmation: %w", err)
+		}
+	}
+
+	return nil
+}
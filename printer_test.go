@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeMockLP writes an lp script that appends its arguments and the printed document to a log file. Printing on the
+// printer "broken" fails.
+func writeMockLP(t *testing.T) (lp, log string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	lp = filepath.Join(dir, "lp")
+	log = filepath.Join(dir, "jobs")
+
+	script := `#!/bin/sh
+if [ "$2" = broken ]; then
+	echo "lp: The printer or class does not exist." >&2
+	exit 1
+fi
+echo "$@" >> ` + log + `
+cat >> ` + log + `
+`
+
+	err := os.WriteFile(lp, []byte(script), 0700)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	return lp, log
+}
+
+func TestPrinter_storeShares(t *testing.T) {
+	lp, log := writeMockLP(t)
+
+	printers, err := parsePrinterList(`[{"printer":"lab-1","custodian":"Alice"},{"printer":"lab-2","custodian":"Bob"},{"printer":"lab-3"}]`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var prompt bytes.Buffer
+
+	sink := newPrinterSink(printers, strings.NewReader("\n\n\n"), &prompt)
+	sink.lp = lp
+
+	var out bytes.Buffer
+
+	err = cmdGenerate(3, 2, generateOptions{sinks: []shareSink{sink}, noOversample: true, withholdShares: true}, &out)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	outLines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(outLines) != 3 || outLines[1] != "threshold: 2" || outLines[2] != "shares (need at least 2 of these for recovery):" {
+		t.Errorf("unexpected output with withheld shares: %q", out.String())
+	}
+
+	jobs, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(jobs)), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("want 3 print jobs, have %q", jobs)
+	}
+
+	for i, want := range []string{"-d lab-1 -t Alice", "-d lab-2 -t Bob", "-d lab-3 -t share "} {
+		index, _, ok := strings.Cut(lines[2*i+1], ",")
+		if !ok {
+			t.Errorf("unexpected document for job %d: %q", i, lines[2*i+1])
+		}
+
+		if i == 2 {
+			// Custodians without a name get the index of their share as title.
+			want += index
+		}
+
+		if lines[2*i] != want {
+			t.Errorf("unexpected arguments for job %d. want %q, have %q", i, want, lines[2*i])
+		}
+	}
+
+	if n := strings.Count(prompt.String(), "Press enter once it has been collected."); n != 3 {
+		t.Errorf("want 3 confirmation prompts, have %d: %q", n, prompt.String())
+	}
+}
+
+func TestPrinter_errors(t *testing.T) {
+	lp, _ := writeMockLP(t)
+
+	for _, tc := range []struct {
+		name     string
+		printers []printer
+		confirm  string
+		wantErr  string
+	}{
+		{"too few printers", []printer{{Name: "lab-1"}}, "\n\n", "Need a printer for each of the 2 shares, have 1."},
+		{"failing job", []printer{{Name: "lab-1"}, {Name: "broken"}}, "\n\n", "printing share on broken: exit status 1: lp: The printer or class does not exist."},
+		{"no confirmation", []printer{{Name: "lab-1"}, {Name: "lab-2"}}, "", "waiting for confirmation: EOF"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sink := newPrinterSink(tc.printers, strings.NewReader(tc.confirm), &bytes.Buffer{})
+			sink.lp = lp
+
+			err := cmdGenerate(2, 2, generateOptions{sinks: []shareSink{sink}}, &bytes.Buffer{})
+			if err == nil || err.Error() != tc.wantErr {
+				t.Errorf("unexpected error. want %q, have %v", tc.wantErr, err)
+			}
+		})
+	}
+}
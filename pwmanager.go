@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// passwordManagerHeaders are the header rows of the CSV import formats of the supported password managers.
+var passwordManagerHeaders = map[string][]string{
+	"1password": {"Title", "Website", "Username", "Password", "Notes"},
+	"bitwarden": {"folder", "favorite", "type", "name", "notes", "fields", "reprompt", "login_uri", "login_username", "login_password", "login_totp"},
+}
+
+// writePasswordManagerCSV writes shares as a CSV file for importing into a password manager, with one login entry per
+// share. The username of each entry is the ceremony ID, the password is the share line of the text output.
+func writePasswordManagerCSV(out io.Writer, format string, shares []storedShare, k int, ceremonyID string) error {
+	w := csv.NewWriter(out)
+
+	err := w.Write(passwordManagerHeaders[format])
+	if err != nil {
+		return err
+	}
+
+	for i, share := range shares {
+		name := "Share " + strconv.Itoa(i+1)
+		notes := fmt.Sprintf("Share %d of %d. At least %d shares are required to recover the secret.", i+1, len(shares), k)
+
+		var record []string
+
+		switch format {
+		case "1password":
+			record = []string{name, "", ceremonyID, share.line, notes}
+		case "bitwarden":
+			record = []string{"", "", "login", name, notes, "", "", "", ceremonyID, share.line, ""}
+		}
+
+		err := w.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+
+	return w.Error()
+}
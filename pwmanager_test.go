@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPasswordManagerCSV(t *testing.T) {
+	for _, tc := range []struct {
+		format   string
+		header   []string
+		name     int // Columns of the entry fields.
+		username int
+		password int
+		notes    int
+	}{
+		// 1Password imports the columns title, website, username, password and notes.
+		{"1password", []string{"Title", "Website", "Username", "Password", "Notes"}, 0, 2, 3, 4},
+		{"bitwarden", []string{"folder", "favorite", "type", "name", "notes", "fields", "reprompt", "login_uri", "login_username", "login_password", "login_totp"}, 3, 8, 9, 4},
+	} {
+		t.Run(tc.format, func(t *testing.T) {
+			var (
+				buf       bytes.Buffer
+				secretBuf bytes.Buffer
+			)
+
+			opts := generateOptions{format: tc.format, secretOut: &secretBuf, ceremonyID: "2026-10"}
+
+			err := cmdGenerate(5, 3, opts, &buf)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			// The reader fails on records with a different number of fields than the header.
+			records, err := csv.NewReader(&buf).ReadAll()
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if len(records) != 6 {
+				t.Fatalf("want 6 records, have %d: %q", len(records), records)
+			}
+
+			if !reflect.DeepEqual(records[0], tc.header) {
+				t.Errorf("unexpected header: %q", records[0])
+			}
+
+			var shares []string
+
+			for i, record := range records[1:] {
+				if want := "Share " + strconv.Itoa(i+1); record[tc.name] != want {
+					t.Errorf("unexpected name. want %q, have %q", want, record[tc.name])
+				}
+
+				if record[tc.username] != "2026-10" {
+					t.Errorf("unexpected username %q", record[tc.username])
+				}
+
+				if !strings.Contains(record[tc.notes], "At least 3 shares are required") {
+					t.Errorf("unexpected notes %q", record[tc.notes])
+				}
+
+				if tc.format == "bitwarden" && record[2] != "login" {
+					t.Errorf("unexpected type %q", record[2])
+				}
+
+				shares = append(shares, record[tc.password])
+			}
+
+			var outBuf bytes.Buffer
+
+			err = cmdRecover(strings.NewReader(strings.Join(shares[:3], "\n")), recoverOptions{}, &bytes.Buffer{}, &outBuf)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			secret := strings.TrimPrefix(secretBuf.String(), "secret: ")
+			if outBuf.String() != secret {
+				t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
+			}
+		})
+	}
+}
+
+func TestPasswordManagerCSV_requiresSecretOut(t *testing.T) {
+	err := cmdGenerate(5, 3, generateOptions{format: "1password"}, &bytes.Buffer{})
+	if err == nil {
+		t.Error("expected an error without a separate output for the secret")
+	}
+}
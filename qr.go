@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// qrImageSize is the width and height in pixels of the QR code images.
+const qrImageSize = 512
+
+// qrSink writes each share line as a QR code to the PNG file share-<index>.png in a directory.
+type qrSink struct {
+	dir string
+}
+
+func (q *qrSink) StoreShares(shares []storedShare) error {
+	// All shares are encoded before any file is written, so that a share that is too long leaves no partial output.
+	codes := make([]*qrcode.QRCode, len(shares))
+
+	for i, share := range shares {
+		code, err := qrcode.New(share.line, qrcode.Medium)
+		if err != nil {
+			return fmt.Errorf("Share %s is too long (%d bytes) for a QR code with medium error correction.", share.index, len(share.line))
+		}
+
+		codes[i] = code
+	}
+
+	for i, share := range shares {
+		png, err := codes[i].PNG(qrImageSize)
+		if err != nil {
+			return fmt.Errorf("encoding share %s as QR code: %w", share.index, err)
+		}
+
+		name := filepath.Join(q.dir, "share-"+share.index+".png")
+
+		fh, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			return err
+		}
+
+		_, err = fh.Write(png)
+		if err != nil {
+			fh.Close()
+			return fmt.Errorf("writing %s: %w", name, err)
+		}
+
+		err = fh.Close()
+		if err != nil {
+			return fmt.Errorf("writing %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
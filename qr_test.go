@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/makiuchi-d/gozxing"
+	gozxingqr "github.com/makiuchi-d/gozxing/qrcode"
+)
+
+// decodeQR returns the text of the QR code in the named PNG file.
+func decodeQR(t *testing.T, name string) string {
+	t.Helper()
+
+	fh, err := os.Open(name)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer fh.Close()
+
+	img, _, err := image.Decode(fh)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	bmp, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	result, err := gozxingqr.NewQRCodeReader().Decode(bmp, nil)
+	if err != nil {
+		t.Fatalf("decoding %s: %s", name, err)
+	}
+
+	return result.GetText()
+}
+
+func TestQR_roundtrip(t *testing.T) {
+	dir := t.TempDir()
+
+	var genBuf bytes.Buffer
+
+	err := cmdGenerate(3, 2, generateOptions{sinks: []shareSink{&qrSink{dir: dir}}}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(genBuf.String()), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("unexpected output: %q", genBuf.String())
+	}
+
+	for i, want := range lines[3:] {
+		name := filepath.Join(dir, "share-"+shareLineIndex(want)+".png")
+
+		have := decodeQR(t, name)
+		if have != want {
+			t.Errorf("unexpected QR code for share %d. want %q, have %q", i+1, want, have)
+		}
+	}
+}
+
+func TestQR_tooLong(t *testing.T) {
+	dir := t.TempDir()
+
+	shares := []storedShare{
+		{index: "1", line: "1,2"},
+		{index: "2", line: "2," + strings.Repeat("3", 10000)},
+	}
+
+	err := (&qrSink{dir: dir}).StoreShares(shares)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	if !strings.Contains(err.Error(), "too long") {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("unexpected files after error: %v", entries)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/skip2/go-qrcode"
+)
+
+// qrPDFSink writes a PDF with one page per share for printing. Each page shows the share as a QR code along with its
+// index, the ceremony ID and the threshold.
+type qrPDFSink struct {
+	path       string // The file must not exist.
+	k          int
+	ceremonyID string
+}
+
+func (q *qrPDFSink) StoreShares(shares []storedShare) error {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle("Key ceremony "+q.ceremonyID, true)
+
+	for i, share := range shares {
+		pdf.AddPage()
+
+		// The watermark is drawn first, so that it is behind the share.
+		pdf.SetFont("Helvetica", "B", 72)
+		pdf.SetTextColor(225, 225, 225)
+		pdf.TransformBegin()
+		pdf.TransformRotate(45, 105, 148)
+		pdf.Text(25, 170, "DO NOT COPY")
+		pdf.TransformEnd()
+
+		pdf.SetTextColor(0, 0, 0)
+		pdf.SetFont("Helvetica", "B", 24)
+		pdf.Text(20, 30, "Share "+share.index)
+
+		pdf.SetFont("Helvetica", "", 12)
+		if q.ceremonyID != "" {
+			pdf.Text(20, 42, "Ceremony "+q.ceremonyID)
+		}
+		pdf.Text(20, 50, fmt.Sprintf("At least %d of the %d shares are needed to recover the secret.", q.k, len(shares)))
+
+		png, err := qrcode.Encode(share.line, qrcode.Medium, 1024)
+		if err != nil {
+			return fmt.Errorf("encoding share %s as QR code: %w", share.index, err)
+		}
+
+		name := "share-" + share.index
+		opts := gofpdf.ImageOptions{ImageType: "PNG"}
+
+		pdf.RegisterImageOptionsReader(name, opts, bytes.NewReader(png))
+		pdf.ImageOptions(name, 45, 70, 120, 120, false, opts, 0, "")
+
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.Text(95, 285, fmt.Sprintf("Page %d of %d", pdf.PageNo(), len(shares)))
+
+		if pdf.Err() {
+			return fmt.Errorf("writing page %d: %w", i+1, pdf.Error())
+		}
+	}
+
+	fh, err := os.OpenFile(q.path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+
+	err = pdf.Output(fh)
+	if err != nil {
+		fh.Close()
+		os.Remove(q.path)
+		return err
+	}
+
+	return fh.Close()
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+// pdfPage matches the page objects of a PDF, but not the page tree.
+var pdfPage = regexp.MustCompile(`/Type /Page\b[^s]`)
+
+func TestQRPDF_pageCount(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "ceremony.pdf")
+	sink := &qrPDFSink{path: name, k: 3, ceremonyID: "2026-10"}
+
+	err := cmdGenerate(5, 3, generateOptions{sinks: []shareSink{sink}, ceremonyID: "2026-10", withholdShares: true}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	buf, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !bytes.HasPrefix(buf, []byte("%PDF-")) {
+		t.Fatalf("not a PDF: %q", buf[:16])
+	}
+
+	if n := len(pdfPage.FindAll(buf, -1)); n != 5 {
+		t.Errorf("want 5 pages, have %d", n)
+	}
+}
+
+func TestQRPDF_existingFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "ceremony.pdf")
+
+	err := os.WriteFile(name, []byte("keep"), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	sink := &qrPDFSink{path: name, k: 2}
+
+	err = sink.StoreShares([]storedShare{{"1", "1,19943338053965968504353533017903769217"}})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	buf, _ := os.ReadFile(name)
+	if string(buf) != "keep" {
+		t.Errorf("existing file overwritten: %q", buf)
+	}
+}
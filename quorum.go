@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// A recovery authorization is a file <custodian>.sig holding an RFC 3339 timestamp on the first line and the base64
+// encoded Ed25519 signature of authorizationDigest on the second line. The custodian's public key is expected in
+// <custodian>.pub in the custodian keys directory.
+
+// authorizationClockSkew is how far in the future an authorization may be dated, to allow for clocks that are not
+// exactly in sync.
+const authorizationClockSkew = 5 * time.Minute
+
+// authorizationDigest returns the digest a custodian signs to authorize recovering the secret of a ceremony. Every
+// field is prefixed with its length, so that different fields can not produce the same digest.
+func authorizationDigest(ceremonyID string, ts time.Time) []byte {
+	h := sha256.New()
+
+	for _, field := range []string{"AUTHORIZE-RECOVERY", ceremonyID, ts.UTC().Format(time.RFC3339)} {
+		binary.Write(h, binary.BigEndian, uint64(len(field)))
+		io.WriteString(h, field)
+	}
+
+	return h.Sum(nil)
+}
+
+// cmdAuthorizeRecovery writes a recovery authorization for ceremonyID signed with key to out.
+func cmdAuthorizeRecovery(key ed25519.PrivateKey, ceremonyID string, now time.Time, out io.Writer) error {
+	if ceremonyID == "" {
+		return errors.New("Authorizing a recovery requires a ceremony ID.")
+	}
+
+	sig := ed25519.Sign(key, authorizationDigest(ceremonyID, now))
+
+	fmt.Fprintln(out, now.UTC().Format(time.RFC3339))
+	fmt.Fprintln(out, base64.StdEncoding.EncodeToString(sig))
+
+	return nil
+}
+
+// readAuthorization reads a recovery authorization file.
+func readAuthorization(name string) (time.Time, []byte, error) {
+	fh, err := os.Open(name)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	defer fh.Close()
+
+	var lines []string
+
+	scanner := bufio.NewScanner(fh)
+	for scanner.Scan() {
+		if t := strings.TrimSpace(scanner.Text()); t != "" {
+			lines = append(lines, t)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return time.Time{}, nil, err
+	}
+
+	if len(lines) != 2 {
+		return time.Time{}, nil, errors.New("expected timestamp and signature")
+	}
+
+	ts, err := time.Parse(time.RFC3339, lines[0])
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+
+	return ts, sig, nil
+}
+
+// verifyQuorum checks that at least k custodians whose public keys are in keysDir authorized recovering the secret of
+// ceremonyID with a signature in sigsDir. Authorizations older than maxAge at now are not accepted, so that old
+// authorizations can not be replayed. Signatures that fail verification are reported to diag.
+func verifyQuorum(keysDir, sigsDir, ceremonyID string, k int, now time.Time, maxAge time.Duration, diag io.Writer) error {
+	if ceremonyID == "" {
+		return errors.New("Verifying the quorum requires shares with a ceremony ID.")
+	}
+
+	if k < 1 {
+		return errors.New("Verifying the quorum requires the threshold of the shares.")
+	}
+
+	keys, err := filepath.Glob(filepath.Join(keysDir, "*.pub"))
+	if err != nil {
+		return err
+	}
+
+	sort.Strings(keys)
+
+	valid := 0
+
+	for _, keyFile := range keys {
+		custodian := strings.TrimSuffix(filepath.Base(keyFile), ".pub")
+
+		key, err := loadVerifyKey(keyFile)
+		if err != nil {
+			fmt.Fprintf(diag, "reading key of custodian %q: %s\n", custodian, err)
+			continue
+		}
+
+		ts, sig, err := readAuthorization(filepath.Join(sigsDir, custodian+".sig"))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			fmt.Fprintf(diag, "reading authorization of custodian %q: %s\n", custodian, err)
+			continue
+		}
+
+		if !ed25519.Verify(key, authorizationDigest(ceremonyID, ts), sig) {
+			fmt.Fprintf(diag, "invalid authorization of custodian %q\n", custodian)
+			continue
+		}
+
+		if age := now.Sub(ts); age > maxAge || age < -authorizationClockSkew {
+			fmt.Fprintf(diag, "expired authorization of custodian %q from %s\n", custodian, ts.Format(time.RFC3339))
+			continue
+		}
+
+		valid++
+	}
+
+	if valid < k {
+		return fmt.Errorf("Recovery authorized by %d custodians, need at least %d.", valid, k)
+	}
+
+	return nil
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/farhaven/secret/shamir"
+)
+
+// setupCustodians writes the public keys of the named custodians to a temporary directory and returns it along with
+// their private keys.
+func setupCustodians(t *testing.T, names ...string) (string, map[string]ed25519.PrivateKey) {
+	t.Helper()
+
+	dir := t.TempDir()
+	keys := make(map[string]ed25519.PrivateKey)
+
+	for _, name := range names {
+		key := newSigningKey(t)
+		keys[name] = key
+
+		der, err := x509.MarshalPKIXPublicKey(key.Public())
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		err = os.WriteFile(filepath.Join(dir, name+".pub"), pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	return dir, keys
+}
+
+func writeAuthorization(t *testing.T, dir, name string, key ed25519.PrivateKey, ceremonyID string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	err := cmdAuthorizeRecovery(key, ceremonyID, time.Now(), &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, name+".sig"), buf.Bytes(), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestVerifyQuorum(t *testing.T) {
+	keysDir, keys := setupCustodians(t, "alice", "bob", "carol")
+	sigsDir := t.TempDir()
+
+	writeAuthorization(t, sigsDir, "alice", keys["alice"], "ceremony")
+	writeAuthorization(t, sigsDir, "bob", keys["bob"], "ceremony")
+
+	var diag bytes.Buffer
+
+	err := verifyQuorum(keysDir, sigsDir, "ceremony", 2, time.Now(), time.Hour, &diag)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if diag.Len() != 0 {
+		t.Errorf("unexpected diagnostic: %q", diag.String())
+	}
+
+	err = verifyQuorum(keysDir, sigsDir, "ceremony", 3, time.Now(), time.Hour, &diag)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	if !strings.Contains(err.Error(), "authorized by 2 custodians") {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestVerifyQuorum_invalidSignatures(t *testing.T) {
+	keysDir, keys := setupCustodians(t, "alice", "bob", "carol")
+	sigsDir := t.TempDir()
+
+	writeAuthorization(t, sigsDir, "alice", keys["alice"], "ceremony")
+	writeAuthorization(t, sigsDir, "bob", keys["bob"], "other ceremony")
+	writeAuthorization(t, sigsDir, "carol", keys["alice"], "ceremony")
+
+	var diag bytes.Buffer
+
+	err := verifyQuorum(keysDir, sigsDir, "ceremony", 2, time.Now(), time.Hour, &diag)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	want := "invalid authorization of custodian \"bob\"\ninvalid authorization of custodian \"carol\"\n"
+	if diag.String() != want {
+		t.Errorf("unexpected diagnostic: want %q, have %q", want, diag.String())
+	}
+}
+
+func TestVerifyQuorum_expired(t *testing.T) {
+	keysDir, keys := setupCustodians(t, "alice", "bob")
+	sigsDir := t.TempDir()
+
+	writeAuthorization(t, sigsDir, "alice", keys["alice"], "ceremony")
+	writeAuthorization(t, sigsDir, "bob", keys["bob"], "ceremony")
+
+	var diag bytes.Buffer
+
+	err := verifyQuorum(keysDir, sigsDir, "ceremony", 2, time.Now().Add(2*time.Hour), time.Hour, &diag)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	if n := strings.Count(diag.String(), "expired authorization"); n != 2 {
+		t.Errorf("want 2 expired authorizations, have %d: %q", n, diag.String())
+	}
+}
+
+func TestAuthorizationDigest_fieldBoundaries(t *testing.T) {
+	ts := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+
+	a := authorizationDigest("ceremony", ts)
+	b := authorizationDigest("ceremony2026-10-14T12:00:00Z", time.Time{})
+
+	if bytes.Equal(a, b) {
+		t.Error("different fields produce the same digest")
+	}
+}
+
+func TestRecover_quorum(t *testing.T) {
+	keysDir, keys := setupCustodians(t, "alice", "bob", "carol")
+	sigsDir := t.TempDir()
+
+	// The authorizations are for another ceremony than the shares.
+	writeAuthorization(t, sigsDir, "alice", keys["alice"], "other")
+	writeAuthorization(t, sigsDir, "bob", keys["bob"], "other")
+	writeAuthorization(t, sigsDir, "carol", keys["carol"], "ceremony")
+
+	var genBuf bytes.Buffer
+
+	err := cmdGenerate(5, 2, generateOptions{ceremonyID: "ceremony"}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var diag bytes.Buffer
+
+	opts := recoverOptions{
+		authorize: func(ceremonyID string, k int) error {
+			return verifyQuorum(keysDir, sigsDir, ceremonyID, k, time.Now(), time.Hour, &diag)
+		},
+	}
+
+	var outBuf bytes.Buffer
+
+	err = cmdRecover(strings.NewReader(genBuf.String()), opts, io.Discard, &outBuf)
+	if err == nil || !strings.Contains(err.Error(), "authorized by 1 custodians, need at least 2") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if outBuf.Len() != 0 {
+		t.Errorf("secret recovered without a quorum: %q", outBuf.String())
+	}
+
+	writeAuthorization(t, sigsDir, "bob", keys["bob"], "ceremony")
+
+	err = cmdRecover(strings.NewReader(genBuf.String()), opts, io.Discard, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	secret := strings.TrimPrefix(strings.SplitN(genBuf.String(), "\n", 2)[0], "secret: ")
+	if outBuf.String() != secret+"\n" {
+		t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
+	}
+
+	// Without the shares line the threshold is unknown.
+	var shares []string
+
+	for _, line := range strings.Split(genBuf.String(), "\n") {
+		if _, ok := shamir.ParseThreshold(line); !ok {
+			shares = append(shares, line)
+		}
+	}
+
+	err = cmdRecover(strings.NewReader(strings.Join(shares, "\n")), opts, io.Discard, io.Discard)
+	if err == nil {
+		t.Error("expected an error without a threshold")
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisStore stores shares in Redis at secret:<ceremony-id>:share:<index>. Setting a TTL makes the shares expire
+// after a time-limited recovery ceremony.
+type redisStore struct {
+	client     *redis.Client
+	ceremonyID string
+	ttl        time.Duration
+}
+
+func newRedisStore(addr, ceremonyID string, ttl time.Duration) (*redisStore, error) {
+	if ceremonyID == "" {
+		return nil, errors.New("Storing shares in Redis requires a ceremony ID.")
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: addr})
+
+	return &redisStore{client: client, ceremonyID: ceremonyID, ttl: ttl}, nil
+}
+
+func (r *redisStore) key(index string) string {
+	return fmt.Sprintf("secret:%s:share:%s", r.ceremonyID, index)
+}
+
+func (r *redisStore) StoreShares(shares []storedShare) error {
+	ctx := context.Background()
+
+	for _, share := range shares {
+		err := r.client.Set(ctx, r.key(share.index), share.line, r.ttl).Err()
+		if err != nil {
+			return fmt.Errorf("storing share in Redis: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *redisStore) LoadShares() ([]string, error) {
+	ctx := context.Background()
+
+	var keys []string
+
+	iter := r.client.Scan(ctx, 0, r.key("*"), 0).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("listing shares in Redis: %w", err)
+	}
+
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	sort.Strings(keys)
+
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("reading shares from Redis: %w", err)
+	}
+
+	var lines []string
+
+	for _, v := range values {
+		// Shares may expire between listing and reading them.
+		if s, ok := v.(string); ok {
+			lines = append(lines, s)
+		}
+	}
+
+	return lines, nil
+}
+
+func (r *redisStore) Close() error {
+	return r.client.Close()
+}
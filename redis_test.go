@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestRedis_roundtrip(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	store, err := newRedisStore(mr.Addr(), "test", time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer store.Close()
+
+	var genBuf bytes.Buffer
+
+	err = cmdGenerate(5, 3, generateOptions{sinks: []shareSink{store}}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	keys := mr.Keys()
+	if len(keys) != 5 {
+		t.Fatalf("want 5 keys, have %d: %q", len(keys), keys)
+	}
+
+	for _, key := range keys {
+		if !strings.HasPrefix(key, "secret:test:share:") {
+			t.Errorf("unexpected key %q", key)
+		}
+
+		if ttl := mr.TTL(key); ttl != time.Hour {
+			t.Errorf("unexpected TTL for %q: want %s, have %s", key, time.Hour, ttl)
+		}
+	}
+
+	in, err := readSource(store)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var (
+		errBuf bytes.Buffer
+		outBuf bytes.Buffer
+	)
+
+	err = cmdRecover(in, recoverOptions{}, &errBuf, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	secret := strings.TrimPrefix(strings.SplitN(genBuf.String(), "\n", 2)[0], "secret: ")
+	if outBuf.String() != secret+"\n" {
+		t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
+	}
+
+	if errBuf.Len() != 0 {
+		t.Errorf("unexpected diagnostic: %q", errBuf.String())
+	}
+}
+
+func TestRedis_expired(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	store, err := newRedisStore(mr.Addr(), "test", time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer store.Close()
+
+	err = cmdGenerate(5, 3, generateOptions{sinks: []shareSink{store}}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	mr.FastForward(2 * time.Minute)
+
+	lines, err := store.LoadShares()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(lines) != 0 {
+		t.Errorf("expected expired shares to be gone, have %q", lines)
+	}
+}
+
+func TestRedis_noCeremonyID(t *testing.T) {
+	_, err := newRedisStore("localhost:6379", "", 0)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
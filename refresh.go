@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/farhaven/secret/shamir"
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+)
+
+// refresher periodically replaces the shares in a directory with fresh shares of the same secret, so that shares that
+// leaked before a refresh are useless afterwards. Old share files are moved to the archive subdirectory.
+type refresher struct {
+	dir   string
+	n, k  int
+	audit io.Writer
+
+	// sinks are notified with the fresh shares after each refresh, so that custodians receive their new share.
+	sinks []shareSink
+
+	now func() time.Time // Clock used to name the archives.
+}
+
+func newRefresher(dir string, n, k int, audit io.Writer) *refresher {
+	return &refresher{
+		dir:   dir,
+		n:     n,
+		k:     k,
+		audit: audit,
+		now:   time.Now,
+	}
+}
+
+func (r *refresher) auditf(format string, args ...interface{}) {
+	fmt.Fprintf(r.audit, "%s %s\n", time.Now().UTC().Format(time.RFC3339Nano), fmt.Sprintf(format, args...))
+}
+
+// run refreshes the shares at the times given by schedule until ctx is done. Failed refreshes are reported to diag
+// and retried at the next scheduled time.
+func (r *refresher) run(ctx context.Context, schedule cron.Schedule, diag io.Writer) error {
+	r.auditf("started dir=%s n=%d k=%d", r.dir, r.n, r.k)
+
+	for {
+		next := schedule.Next(time.Now())
+
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			r.auditf("stopped")
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		err := r.refresh(diag)
+		if err != nil {
+			fmt.Fprintf(diag, "refreshing shares in %s: %s\n", r.dir, err)
+			r.auditf("refresh failed")
+		}
+	}
+}
+
+// refresh recovers the secret from the share files in the directory, archives them and writes fresh shares of the
+// secret with a new ceremony ID.
+func (r *refresher) refresh(diag io.Writer) error {
+	files, err := readShareDir(r.dir)
+	if err != nil {
+		return err
+	}
+
+	indices := make([]string, 0, len(files))
+	for index := range files {
+		indices = append(indices, index)
+	}
+
+	sort.Strings(indices)
+
+	var read []ceremonyShare
+
+	for _, index := range indices {
+		_, line := splitShareLabel(strings.TrimSpace(string(files[index])))
+		id, line := splitCeremonyID(line)
+
+		share, err := parseShare(line)
+		if err != nil {
+			fmt.Fprintf(diag, "reading share %q: %s\n", line, err)
+			continue
+		}
+
+		read = append(read, ceremonyShare{line: line, id: id, share: share})
+	}
+
+	_, shares, err := selectCeremony("", read, diag)
+	if err != nil {
+		return err
+	}
+
+	if len(shares) < r.k {
+		return fmt.Errorf("Not enough shares to refresh: have %d, need %d.", len(shares), r.k)
+	}
+
+	secret, err := shamir.Combine(shares)
+	if err != nil {
+		return err
+	}
+
+	fresh, _, err := shamir.GenerateShares(r.n, r.k, secret, shamir.PoolSize(r.n))
+	if err != nil {
+		return err
+	}
+
+	err = verifyShares(fresh, secret, r.k, nil)
+	if err != nil {
+		return err
+	}
+
+	ceremonyID := uuid.NewString()
+
+	lines := make([]storedShare, len(fresh))
+	for i, share := range fresh {
+		lines[i] = storedShare{index: shareIndex(share), line: withCeremonyID(ceremonyID, share.String())}
+	}
+
+	archive, err := r.archive(indices)
+	if err != nil {
+		return err
+	}
+
+	for _, share := range lines {
+		err := os.WriteFile(filepath.Join(r.dir, "share-"+share.index+".txt"), []byte(share.line+"\n"), 0600)
+		if err != nil {
+			return err
+		}
+	}
+
+	r.auditf("shares refreshed ceremony_id=%s shares=%d archive=%s", ceremonyID, len(lines), archive)
+
+	for _, sink := range r.sinks {
+		err := sink.StoreShares(lines)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// archive moves the share files with the given indices to a new subdirectory of the archive directory and returns
+// its path.
+func (r *refresher) archive(indices []string) (string, error) {
+	dir := filepath.Join(r.dir, "archive", r.now().UTC().Format("20060102T150405.000000000Z"))
+
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return "", err
+	}
+
+	for _, index := range indices {
+		name := "share-" + index + ".txt"
+
+		err := os.Rename(filepath.Join(r.dir, name), filepath.Join(dir, name))
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return dir, nil
+}
+
+// parseCronSchedule parses a cron expression with five fields, or a descriptor like @hourly.
+func parseCronSchedule(expr string) (cron.Schedule, error) {
+	if expr == "" {
+		return nil, errors.New("Scheduled refresh requires -cron.")
+	}
+
+	schedule, err := cron.ParseStandard(expr)
+	if err != nil {
+		return nil, fmt.Errorf("parsing -cron: %w", err)
+	}
+
+	return schedule, nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// everySchedule is a cron schedule with a fixed interval below the one second resolution of cron expressions.
+type everySchedule time.Duration
+
+func (s everySchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(s))
+}
+
+// readShareFiles returns the contents of the share files in dir as input for cmdRecover.
+func readShareFiles(t *testing.T, dir string) string {
+	t.Helper()
+
+	files, err := readShareDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var buf strings.Builder
+
+	for _, content := range files {
+		buf.Write(content)
+	}
+
+	return buf.String()
+}
+
+func TestRefresher_run(t *testing.T) {
+	dir := t.TempDir()
+	writeShareFiles(t, dir,
+		"1,19943338053965968504353533017903769217",
+		"2,161872477868088873785792630750634181303",
+		"5,160274174127002500413544256698187925606",
+	)
+
+	var notified [][]storedShare
+
+	r := newRefresher(dir, 5, 3, io.Discard)
+	r.sinks = []shareSink{shareSinkFunc(func(shares []storedShare) error {
+		notified = append(notified, shares)
+		return nil
+	})}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var errBuf bytes.Buffer
+
+	done := make(chan error, 1)
+	go func() {
+		done <- r.run(ctx, everySchedule(50*time.Millisecond), &errBuf)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for {
+		archives, _ := os.ReadDir(filepath.Join(dir, "archive"))
+		if len(archives) >= 2 {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("shares not refreshed twice: %q", errBuf.String())
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	<-done
+
+	if errBuf.Len() != 0 {
+		t.Errorf("unexpected diagnostic: %q", errBuf.String())
+	}
+
+	if len(notified) < 2 || len(notified[0]) != 5 {
+		t.Fatalf("custodians not notified of the fresh shares: %v", notified)
+	}
+
+	content := readShareFiles(t, dir)
+	if strings.Contains(content, "19943338053965968504353533017903769217") {
+		t.Fatalf("shares not replaced: %q", content)
+	}
+
+	var outBuf bytes.Buffer
+
+	err := cmdRecover(strings.NewReader(content), recoverOptions{}, &bytes.Buffer{}, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if want := "7uPIBqGKMPpProBYFFR3S\n"; outBuf.String() != want {
+		t.Errorf("unexpected secret after refresh. want %q, have %q", want, outBuf.String())
+	}
+
+	// The original shares are kept in the first archive.
+	archives, err := os.ReadDir(filepath.Join(dir, "archive"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	archived := readShareFiles(t, filepath.Join(dir, "archive", archives[0].Name()))
+	if !strings.Contains(archived, "1,19943338053965968504353533017903769217\n") {
+		t.Errorf("original shares not archived: %q", archived)
+	}
+}
+
+func TestRefresher_notEnoughShares(t *testing.T) {
+	dir := t.TempDir()
+	writeShareFiles(t, dir,
+		"1,19943338053965968504353533017903769217",
+		"2,161872477868088873785792630750634181303",
+	)
+
+	r := newRefresher(dir, 5, 3, io.Discard)
+
+	err := r.refresh(&bytes.Buffer{})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	// The shares are left in place.
+	if content := readShareFiles(t, dir); strings.Count(content, "\n") != 2 {
+		t.Errorf("unexpected share files after a failed refresh: %q", content)
+	}
+}
+
+func TestParseCronSchedule(t *testing.T) {
+	for _, expr := range []string{"0 3 * * 0", "@weekly"} {
+		_, err := parseCronSchedule(expr)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", expr, err)
+		}
+	}
+
+	for _, expr := range []string{"", "every sunday"} {
+		_, err := parseCronSchedule(expr)
+		if err == nil {
+			t.Errorf("%q: expected an error", expr)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/farhaven/secret/shamir"
+)
+
+// cmdReindex reads the shares in in and writes them to out with the indices 1 to the number of shares, in the order
+// they were read. The index of a share is the point its value is computed at, so the new values are interpolated from
+// the old shares with shamir.Reindex. That needs at least as many shares as the threshold, but never computes the
+// secret. The mapping from the old to the new indices is written to diag.
+func cmdReindex(in io.Reader, recoverOpts recoverOptions, diag, out io.Writer) error {
+	r, err := readInputShares(in, recoverOpts, diag)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(r.shares))
+
+	for _, share := range r.shares {
+		index := shareIndex(share)
+		if seen[index] {
+			return fmt.Errorf("Share %s is given more than once.", index)
+		}
+
+		seen[index] = true
+	}
+
+	if len(r.shares) == 0 || len(r.shares) < r.threshold {
+		return &ErrInsufficientShares{Have: len(r.shares), Need: r.threshold}
+	}
+
+	if r.threshold == 0 {
+		fmt.Fprintln(diag, "the threshold is unknown, the new shares are only valid if at least that many shares were read")
+	}
+
+	indices := make([]int64, len(r.shares))
+	for i := range indices {
+		indices[i] = int64(i + 1)
+	}
+
+	reindexed := shamir.Reindex(r.shares, indices)
+
+	fmt.Fprintln(diag, "old index -> new index")
+
+	for i, share := range r.shares {
+		fmt.Fprintf(diag, "%s -> %d\n", shareIndex(share), indices[i])
+	}
+
+	names := make([]string, 0, len(r.headers))
+	for name := range r.headers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintln(out, name+": "+r.headers[name])
+	}
+
+	if r.threshold > 0 {
+		shamir.WriteSharesHeader(out, r.threshold)
+	}
+
+	for _, share := range reindexed {
+		fmt.Fprintln(out, withCeremonyID(r.ceremonyID, share.String()))
+	}
+
+	return nil
+}
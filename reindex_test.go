@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReindex(t *testing.T) {
+	secret, err := parseTextSecret("correct horse")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var genBuf bytes.Buffer
+
+	opts := generateOptions{
+		secret:       secret,
+		encodeSecret: encodeTextSecret,
+		header:       []string{encodingHeader + ": " + textEncoding},
+		ceremonyID:   "2026-10",
+		indices:      []int64{17, 4, 902, 33, 5000},
+	}
+
+	err = cmdGenerate(5, 3, opts, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(genBuf.String()), "\n")
+
+	// The headers and four of the shares.
+	input := lines[1:8]
+
+	var (
+		diagBuf    bytes.Buffer
+		reindexBuf bytes.Buffer
+	)
+
+	err = cmdReindex(strings.NewReader(strings.Join(input, "\n")), recoverOptions{}, &diagBuf, &reindexBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	output := reindexBuf.String()
+
+	if strings.Contains(output, "secret:") || strings.Contains(output, secret.Text(62)) {
+		t.Fatalf("secret written to the output: %q", output)
+	}
+
+	reindexed := strings.Split(strings.TrimSpace(output), "\n")
+	if len(reindexed) != 7 || reindexed[0] != lines[1] || reindexed[1] != lines[2] || reindexed[2] != lines[3] {
+		t.Fatalf("unexpected output: %q", output)
+	}
+
+	for i, line := range reindexed[3:] {
+		if want := string(rune('1' + i)); shareLineIndex(line) != want {
+			t.Errorf("unexpected index of share %q, want %s", line, want)
+		}
+
+		if !strings.HasPrefix(line, "id:2026-10:") {
+			t.Errorf("share %q lost its ceremony ID", line)
+		}
+	}
+
+	for _, want := range []string{"17 -> 1", "4 -> 2", "902 -> 3", "33 -> 4"} {
+		if !strings.Contains(diagBuf.String(), want) {
+			t.Errorf("diagnostic %q does not contain %q", diagBuf.String(), want)
+		}
+	}
+
+	var outBuf bytes.Buffer
+
+	err = cmdRecover(strings.NewReader(strings.Join([]string{reindexed[0], reindexed[3], reindexed[5], reindexed[6]}, "\n")), recoverOptions{}, &bytes.Buffer{}, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if outBuf.String() != "correct horse\n" {
+		t.Errorf("unexpected recovered secret: %q", outBuf.String())
+	}
+}
+
+func TestReindex_errors(t *testing.T) {
+	var genBuf bytes.Buffer
+
+	err := cmdGenerate(5, 3, generateOptions{}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(genBuf.String()), "\n")
+
+	for name, input := range map[string][]string{
+		"too few shares": {lines[1], lines[2], lines[3], lines[4]},
+		"duplicate":      {lines[1], lines[2], lines[3], lines[4], lines[3]},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+
+			err := cmdReindex(strings.NewReader(strings.Join(input, "\n")), recoverOptions{}, &bytes.Buffer{}, &out)
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+
+			if out.Len() != 0 {
+				t.Errorf("unexpected output: %q", out.String())
+			}
+		})
+	}
+}
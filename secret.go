@@ -6,23 +6,178 @@
 package main
 
 import (
-	"bufio"
+	"context"
+	"crypto/ed25519"
+	"crypto/subtle"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
-	"math"
-	"math/rand"
+	"math/big"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
 	"strings"
+	"syscall"
 	"time"
 
+	"github.com/farhaven/secret/shamir"
 	"github.com/posener/sharedsecret"
 )
 
-const minShares = 10000 // Minimum number of shares to generate.
+// A storedShare is a generated share as it is written out. The line carries the ceremony ID, the signature, the label
+// and the encryption of the share, like the share lines of the text output.
+type storedShare struct {
+	index string // Index of the share, for naming it in external systems.
+	line  string
+}
+
+// A shareSink receives the generated shares in addition to the text output, for example to store them in an external
+// system.
+type shareSink interface {
+	StoreShares(shares []storedShare) error
+}
+
+// shareSinkFunc adapts a function to the shareSink interface.
+type shareSinkFunc func(shares []storedShare) error
+
+func (f shareSinkFunc) StoreShares(shares []storedShare) error {
+	return f(shares)
+}
+
+// A shareSource provides shares from somewhere other than an input file. The returned lines are fed to cmdRecover
+// like lines read from a file.
+type shareSource interface {
+	LoadShares() ([]string, error)
+}
+
+// generateOptions holds the optional settings for cmdGenerate. The zero value only writes the text output.
+type generateOptions struct {
+	sinks      []shareSink
+	signingKey ed25519.PrivateKey // Sign each share line if set.
+
+	// format selects the encoding of the share lines: "text" (the default), "ber-tlv" or "pem". The "spreadsheet",
+	// "1password" and "bitwarden" formats write a CSV file with one row per share instead, "csv" writes only the
+	// index, the value and the threshold of each share as CSV rows, "json" and "cbor" write a single JSON object or
+	// CBOR map with the secret, the threshold and the share lines, and "yaml" writes a YAML document with the secret,
+	// the threshold and the index and value of each share.
+	format string
+
+	// secretOut receives the "secret:" line instead of the output if set. It is required for the CSV formats.
+	secretOut io.Writer
+
+	// expiresAfter is the lifetime of the shares noted in the spreadsheet format. Zero means no expiry.
+	expiresAfter time.Duration
+
+	// secret is shared instead of a randomly generated secret if set.
+	secret *big.Int
+
+	// silent writes only the share lines to the output if set, without the headers and the "shares" line, for
+	// scripts that read the output. It requires secretOut, which still receives the "secret:" line.
+	silent bool
+
+	// omitSecret leaves out the "secret:" line if set, for shares of a secret that must not be revealed.
+	omitSecret bool
+
+	// printSecret is written to the "secret:" line instead of the shared secret if set, for secrets that are
+	// transformed before they are split.
+	printSecret *big.Int
+
+	// encodeSecret formats the secret for the output. The secret is printed in base 62 if it is nil.
+	encodeSecret func(*big.Int) (string, error)
+
+	// verifyBeforeDistribute recovers the secret from a random subset of k shares before anything is written and
+	// fails if the result does not match. verifyRecover is used for the recovery, shamir.RecoverSecret if it is nil.
+	verifyBeforeDistribute bool
+	verifyRecover          func([]sharedsecret.Share) *big.Int
+
+	// noOversample generates exactly n shares instead of selecting them from a larger pool. This is faster, but the
+	// indices of the shares reveal how many shares exist in total.
+	noOversample bool
+
+	// ceremonyID is embedded in every share line if set, so that shares of different ceremonies are not mixed up.
+	ceremonyID string
+
+	// instructions writes recovery instructions for each custodian instead of the share lines if set. It requires
+	// secretOut.
+	instructions *recoveryInstructions
+
+	// header contains "<name>: <value>" lines that are written before the share lines. cmdRecover reads the headers
+	// listed in outputHeaders.
+	header []string
+
+	// backups is the number of shares at the end that are held in escrow by backup custodians. Share lines are
+	// labeled PRIMARY-<i> or BACKUP-<j> if it is set.
+	backups int
+
+	// withholdShares writes only the headers and the threshold to the output if set, for sinks that hand each share
+	// to a different custodian. The share lines are only passed to the sinks.
+	withholdShares bool
+
+	// noiseShares is the number of fake shares that are mixed in with the real shares. noiseSeed makes the fake
+	// shares and their positions reproducible if it is not zero.
+	noiseShares int
+	noiseSeed   int64
+
+	// encoding is the encoding of the share values in share lines: "decimal" (the default), "base58", "base64" or
+	// "words".
+	encoding string
+
+	// padding left-pads the share values in share lines with zeros to this width if it is not zero, so that the width
+	// of a value does not reveal its magnitude.
+	padding int
+
+	// thresholdInShare appends the threshold to the share value of each share as "index,value,k" if set, so that
+	// custodians know how many shares are needed.
+	thresholdInShare bool
+
+	// hmacKey appends an HMAC tag of each share to its share value as "index,value,mac=<tag>" if set.
+	hmacKey []byte
+
+	// labels names the custodian of each share if set. The label of the i-th share is appended to its share value
+	// as "index,value,label".
+	labels []string
 
-func cmdGenerate(n, k int, out io.Writer) error {
+	// indices are the indices of the shares if set, in the order of the shares. They select the shares from the pool
+	// instead of the random selection.
+	indices []int64
+
+	// dryRun makes cmdGenerate only validate the parameters and write "parameters OK" instead of generating shares.
+	dryRun bool
+
+	// encryptShare encrypts each complete share line if set. The encrypted shares are written instead of the share
+	// lines.
+	encryptShare func(line string) (string, error)
+
+	// ctx cancels the generation of the shares if set. timeout limits how long the generation may take, or
+	// generateTimeout if it is zero.
+	ctx     context.Context
+	timeout time.Duration
+
+	// progress receives a dot every progressInterval while the shares are generated if set, and a newline when they
+	// are done.
+	progress io.Writer
+}
+
+// progressInterval is the interval at which the progress of generating the shares is reported.
+const progressInterval = time.Second
+
+// generateTimeout is the default time cmdGenerate may take to generate the pool of shares, which takes a while for
+// large pools.
+const generateTimeout = 30 * time.Second
+
+// poolSize returns how many shares cmdGenerate generates to select n from.
+func (o generateOptions) poolSize(n int) int64 {
+	if o.noOversample {
+		return int64(n)
+	}
+
+	return shamir.PoolSize(n)
+}
+
+func cmdGenerate(n, k int, opts generateOptions, out io.Writer) error {
 	if k > n {
 		return errors.New("There will not be enough shares to recover the secret.")
 	}
@@ -31,108 +186,1042 @@ func cmdGenerate(n, k int, out io.Writer) error {
 		return errors.New("Number of shares must be larger than 1.")
 	}
 
-	// Generate a lot more shares than we need and select random n from them to make recovering the number of shares
-	// unfeasible.
-	genSecrets := int64(math.Pow(float64(n), 2))
-	if genSecrets < minShares {
-		genSecrets = minShares
+	switch opts.format {
+	case "", "text", "ber-tlv", "json":
+	case "cbor":
+		if !cborBuild {
+			return errNoCBOR
+		}
+	case "pem":
+		if opts.signingKey != nil || opts.backups > 0 || opts.labels != nil || opts.hmacKey != nil || opts.thresholdInShare || opts.encryptShare != nil || (opts.encoding != "" && opts.encoding != decimalEncoding) {
+			return errors.New("PEM shares only carry the index, the value, the threshold and the ceremony ID.")
+		}
+	case "csv":
+		if opts.signingKey != nil || opts.backups > 0 || opts.labels != nil || opts.hmacKey != nil || opts.thresholdInShare || opts.encryptShare != nil || (opts.encoding != "" && opts.encoding != decimalEncoding) {
+			return errors.New("CSV shares only carry the index, the value and the threshold.")
+		}
+
+		if opts.secretOut == nil && !opts.omitSecret {
+			return errors.New("CSV output requires a separate output for the secret.")
+		}
+	case "yaml":
+		if opts.signingKey != nil || opts.backups > 0 || opts.labels != nil || opts.hmacKey != nil || opts.thresholdInShare || opts.encryptShare != nil || (opts.encoding != "" && opts.encoding != decimalEncoding) {
+			return errors.New("YAML shares only carry the index and the value.")
+		}
+	case "spreadsheet":
+		if opts.secretOut == nil {
+			return errors.New("Spreadsheet output requires a separate output for the secret.")
+		}
+	case "1password", "bitwarden":
+		if opts.secretOut == nil {
+			return errors.New("Password manager output requires a separate output for the secret.")
+		}
+	default:
+		return fmt.Errorf("Unknown format %q.", opts.format)
+	}
+
+	err := validateCeremonyID(opts.ceremonyID)
+	if err != nil {
+		return err
+	}
+
+	if opts.backups < 0 || opts.backups >= n {
+		return errors.New("There must be at least one primary custodian.")
+	}
+
+	if len(opts.header) > 0 && (passwordManagerHeaders[opts.format] != nil || opts.format == "spreadsheet" || opts.format == "json" || opts.format == "cbor" || opts.format == "csv" || opts.format == "yaml" || opts.instructions != nil) {
+		return errors.New("Output headers are only supported for share lines.")
+	}
+
+	if opts.withholdShares && (passwordManagerHeaders[opts.format] != nil || opts.format == "spreadsheet" || opts.format == "json" || opts.format == "cbor" || opts.format == "csv" || opts.format == "yaml" || opts.instructions != nil) {
+		return errors.New("The shares must not be written to the output.")
+	}
+
+	if opts.silent {
+		switch {
+		case opts.secretOut == nil && !opts.omitSecret:
+			return errors.New("Silent output requires a separate output for the secret.")
+		case len(opts.header) > 0:
+			return errors.New("Silent output can not leave out the headers needed for recovery.")
+		case opts.instructions != nil || opts.format == "json" || opts.format == "cbor" || opts.format == "csv" || opts.format == "yaml" || opts.format == "spreadsheet" || passwordManagerHeaders[opts.format] != nil:
+			return errors.New("Silent output is only supported for share lines.")
+		}
+	}
+
+	if opts.noiseShares > 0 && (opts.backups > 0 || opts.instructions != nil) {
+		return errors.New("Noise shares are not supported with backups or recovery instructions.")
+	}
+
+	if opts.indices != nil {
+		if len(opts.indices) != n {
+			return fmt.Errorf("There are %d indices for %d shares.", len(opts.indices), n)
+		}
+
+		if opts.noiseShares > 0 {
+			return errors.New("Share indices are not supported with noise shares.")
+		}
+
+		err := shamir.CheckIndices(opts.indices, opts.poolSize(n))
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.labels != nil {
+		err := validateLabels(opts.labels, n)
+		if err != nil {
+			return err
+		}
+
+		if opts.format == "ber-tlv" || opts.noiseShares > 0 {
+			return errors.New("Labels are not supported with BER-TLV shares or noise shares.")
+		}
+	}
+
+	err = validateShareEncoding(opts.encoding)
+	if err != nil {
+		return err
+	}
+
+	err = validatePadding(opts.padding, opts.encoding)
+	if err != nil {
+		return err
+	}
+
+	if opts.padding > 0 && (opts.format == "ber-tlv" || opts.format == "pem" || opts.format == "csv" || opts.format == "yaml") {
+		return fmt.Errorf("Padding is not supported in the %s format.", strings.ToUpper(opts.format))
+	}
+
+	if opts.encoding != "" && opts.encoding != decimalEncoding && opts.format == "ber-tlv" {
+		return errors.New("BER-TLV shares do not support other share encodings.")
+	}
+
+	if (opts.hmacKey != nil || opts.thresholdInShare) && opts.format == "ber-tlv" {
+		return errors.New("HMAC tags and thresholds in shares are not supported with BER-TLV shares.")
+	}
+
+	if opts.instructions != nil {
+		if opts.secretOut == nil {
+			return errors.New("Recovery instructions require a separate output for the secret.")
+		}
+
+		if opts.format == "json" || opts.format == "cbor" || opts.format == "csv" || opts.format == "yaml" {
+			return fmt.Errorf("Recovery instructions are not supported in the %s format.", strings.ToUpper(opts.format))
+		}
+
+		err := opts.instructions.validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.dryRun {
+		fmt.Fprintf(out, "parameters OK: n=%d k=%d pool=%d\n", n, k, opts.poolSize(n))
+		return nil
+	}
+
+	shares, secret, err := generateShares(n, k, opts)
+	if err != nil {
+		return err
+	}
+
+	if opts.secret == nil {
+		// A secret given by the caller is left to the caller to clear.
+		defer zeroSecret(secret)
+	}
+
+	if opts.verifyBeforeDistribute {
+		err := verifyShares(shares, secret, k, opts.verifyRecover)
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.noiseShares != 0 {
+		shares, err = addNoiseShares(shares, opts.noiseShares, opts.poolSize(n), opts.noiseSeed)
+		if err != nil {
+			return err
+		}
+	}
+
+	lines := make([]storedShare, len(shares))
+
+	for i, share := range shares {
+		line, err := opts.shareLine(i, n, k, share)
+		if err != nil {
+			return err
+		}
+
+		lines[i] = storedShare{index: shareIndex(share), line: line}
+	}
+
+	for _, sink := range opts.sinks {
+		err := sink.StoreShares(lines)
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.printSecret != nil {
+		secret = opts.printSecret
+	}
+
+	encoded, err := encodeSecret(opts.encodeSecret, secret)
+	if err != nil {
+		return err
+	}
+
+	if opts.format == "json" || opts.format == "cbor" || opts.format == "yaml" {
+		switch {
+		case opts.omitSecret:
+			encoded = ""
+		case opts.secretOut != nil:
+			fmt.Fprintln(opts.secretOut, "secret:", encoded)
+			encoded = ""
+		}
+
+		if opts.format == "yaml" {
+			return writeYAMLShares(out, encoded, k, shares)
+		}
+
+		if opts.format == "cbor" {
+			return writeCBORShares(out, encoded, k, lines)
+		}
+
+		return writeJSONShares(out, encoded, k, lines)
+	}
+
+	secretOut := out
+	if opts.secretOut != nil {
+		secretOut = opts.secretOut
 	}
 
-	shares, secret := sharedsecret.New(genSecrets, int64(k))
+	if !opts.omitSecret {
+		fmt.Fprintln(secretOut, "secret:", encoded)
+	}
 
-	rand.Seed(time.Now().UnixNano())
-	// Randomize list of shares, get the first n
-	rand.Shuffle(len(shares), func(i, j int) {
-		shares[i], shares[j] = shares[j], shares[i]
-	})
+	if opts.format == "csv" {
+		return writeCSVShares(out, shares, k)
+	}
+
+	if opts.format == "spreadsheet" {
+		return writeSpreadsheet(out, lines, k, opts.backups, opts.expiresAfter)
+	}
 
-	shares = shares[:n]
+	if passwordManagerHeaders[opts.format] != nil {
+		return writePasswordManagerCSV(out, opts.format, lines, k, opts.ceremonyID)
+	}
 
-	fmt.Fprintln(out, "secret:", secret.Text(62))
+	for _, line := range opts.header {
+		fmt.Fprintln(out, line)
+	}
+
+	if opts.instructions == nil && !opts.silent {
+		shamir.WriteSharesHeader(out, k)
+	}
 
-	fmt.Fprintf(out, "shares (need at least %d of these for recovery):\n", k)
-	for _, share := range shares {
-		fmt.Fprintln(out, share)
+	if opts.withholdShares {
+		return nil
+	}
+
+	for i, share := range lines {
+		if opts.instructions != nil {
+			opts.instructions.write(out, i, n, k, opts.ceremonyID, share.line)
+			continue
+		}
+
+		fmt.Fprintln(out, share.line)
 	}
 
 	return nil
 }
 
-func cmdRecover(in io.Reader, diag io.Writer, out io.Writer) error {
-	scanner := bufio.NewScanner(in)
+// generatedShares are the results of the generation of shares in generateShares.
+type generatedShares struct {
+	shares []sharedsecret.Share
+	secret *big.Int
+	err    error
+}
+
+// generateShares generates the shares for cmdGenerate in a goroutine, so that it can give up when opts.ctx is
+// canceled or opts.timeout passes.
+func generateShares(n, k int, opts generateOptions) ([]sharedsecret.Share, *big.Int, error) {
+	ctx := opts.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	timeout := opts.timeout
+	if timeout == 0 {
+		timeout = generateTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if opts.progress != nil {
+		stop := writeProgress(opts.progress, progressInterval)
+		defer stop()
+	}
+
+	results := make(chan generatedShares, 1)
+
+	go func() {
+		var r generatedShares
+
+		if opts.indices != nil {
+			r.shares, r.secret, r.err = shamir.GenerateSharesAt(opts.indices, k, opts.secret, opts.poolSize(n))
+		} else {
+			r.shares, r.secret, r.err = shamir.GenerateShares(n, k, opts.secret, opts.poolSize(n))
+		}
+
+		results <- r
+	}()
+
+	select {
+	case r := <-results:
+		return r.shares, r.secret, r.err
+	case <-ctx.Done():
+		if opts.secret == nil {
+			// The shares are still being generated, clear the secret once they are.
+			go func() {
+				r := <-results
+				if r.err == nil {
+					zeroSecret(r.secret)
+				}
+			}()
+		}
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, nil, fmt.Errorf("Generating the shares took longer than %s, use fewer shares or a longer -timeout.", timeout)
+		}
+
+		return nil, nil, errors.New("Generating the shares was canceled.")
+	}
+}
+
+// writeProgress writes a dot to w every interval until stop is called, which writes a newline. Nothing is written to w
+// after stop returns.
+func writeProgress(w io.Writer, interval time.Duration) (stop func()) {
+	var (
+		done     = make(chan struct{})
+		finished = make(chan struct{})
+	)
+
+	go func() {
+		defer close(finished)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				fmt.Fprint(w, ".")
+			case <-done:
+				fmt.Fprintln(w)
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-finished
+	}
+}
+
+// shareLine returns the line written for the i-th of n shares, k of which recover the secret.
+func (o generateOptions) shareLine(i, n, k int, share sharedsecret.Share) (string, error) {
+	labeled := labeledShare{share: share, encoding: o.encoding, padding: o.padding}
+	if o.padding > 0 {
+		err := checkSharePadding(share, o.encoding, o.padding)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if o.thresholdInShare {
+		labeled.threshold = k
+	}
+
+	if o.labels != nil {
+		labeled.label = o.labels[i]
+	}
+
+	if o.hmacKey != nil {
+		labeled.tag = shareTag(o.hmacKey, share)
+	}
+
+	line := labeled.String()
+
+	if o.format == "ber-tlv" {
+		var err error
+
+		line, err = marshalBERShare(share)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	line = withCeremonyID(o.ceremonyID, line)
+
+	if o.format == "pem" {
+		// The PEM block carries the ceremony ID in a header.
+		line = marshalPEMShare(share, k, o.ceremonyID)
+	}
+
+	if o.signingKey != nil {
+		line = signShare(o.signingKey, line)
+	}
+
+	if o.backups > 0 {
+		line = shareLabel(i, n, o.backups) + ": " + line
+	}
+
+	if o.encryptShare != nil {
+		return o.encryptShare(line)
+	}
+
+	return line, nil
+}
+
+// outputHeaders are the names of the header lines that cmdRecover reads from its input.
+var outputHeaders = map[string]bool{
+	saltHeader:            true,
+	passphraseCheckHeader: true,
+	recipientHeader:       true,
+	wifHeader:             true,
+	encodingHeader:        true,
+	totpHeader:            true,
+	timeWindowHeader:      true,
+	shamir.ThresholdHeader: true,
+}
+
+// parseHeader splits a header line into its name and value. ok is false if line is not a known header.
+func parseHeader(line string) (name, value string, ok bool) {
+	name, value, ok = strings.Cut(line, ": ")
+	if !ok || !outputHeaders[name] {
+		return "", "", false
+	}
+
+	return name, value, true
+}
+
+// ErrInsufficientShares is returned by cmdRecover if fewer valid shares were read than are needed to recover the
+// secret.
+type ErrInsufficientShares = shamir.ErrInsufficientShares
+
+// ErrMismatch is returned by cmdRecover if the recovered secret does not start with the expected prefix.
+var ErrMismatch = errors.New("The recovered secret does not start with the expected prefix.")
+
+// checkSecretPrefix returns ErrMismatch if the base 62 representation of secret does not start with prefix. The
+// comparison takes the same time no matter how many characters match.
+func checkSecretPrefix(secret *big.Int, prefix string) error {
+	text := secret.Text(62)
+	if len(text) < len(prefix) {
+		return ErrMismatch
+	}
+
+	if subtle.ConstantTimeCompare([]byte(text[:len(prefix)]), []byte(prefix)) != 1 {
+		return ErrMismatch
+	}
+
+	return nil
+}
+
+// recoverOptions holds the optional settings for cmdRecover.
+type recoverOptions struct {
+	// encodeSecret formats the recovered secret. The secret is printed in base 62 if it is nil.
+	encodeSecret func(*big.Int) (string, error)
+
+	// format selects the output: "text" (the default) writes the secret on a line of its own, "json" writes a JSON
+	// object with the secret.
+	format string
+
+	// binary writes the secret as the raw bytes it was split from instead of a line of text. It requires shares of a
+	// binary secret.
+	binary bool
+
+	// passphrase reads the passphrase a secret was masked with. It is required to recover secrets with a salt
+	// header, since the shares only contain the masked secret.
+	passphrase func() ([]byte, error)
+
+	// unwrapKey is the X25519 private key of the recipient of a wrapped secret. It is required to recover secrets
+	// with a recipient header.
+	unwrapKey []byte
+
+	// decryptShares replaces the encrypted shares in the input with the decrypted share lines if set.
+	decryptShares func(in io.Reader) (io.Reader, error)
+
+	// hmacKey is the key of the HMAC tags the shares must carry if set. Shares without a valid tag are ignored.
+	hmacKey []byte
+
+	// verifyKey is the public key the shares must be signed with if set. Shares without a valid signature are
+	// ignored.
+	verifyKey ed25519.PublicKey
+
+	// ceremonyID selects the ceremony to recover the secret of if set. Otherwise the shares of the ceremony most
+	// shares belong to are used. Shares of other ceremonies are ignored.
+	ceremonyID string
+
+	// authorize is called with the ceremony ID and the threshold of the shares before the secret is recovered if
+	// set. The secret is not recovered if it returns an error.
+	authorize func(ceremonyID string, k int) error
+
+	// verbose reports every share the secret is recovered from to diag, along with how many of the presented shares
+	// were used.
+	verbose bool
+
+	// timeWindow restricts recovery to a time range if set, in addition to the range of a time window header.
+	timeWindow *timeWindow
+
+	// now returns the time the time windows are checked against. time.Now is used if it is nil.
+	now func() time.Time
+
+	// checkPrefix is the expected start of the base 62 representation of the recovered secret if set. The secret is
+	// not written if it does not match.
+	checkPrefix string
+}
+
+// inputShares holds the shares read from the input of cmdRecover along with what the input says about them.
+type inputShares struct {
+	ceremonyID string
+	shares     []sharedsecret.Share // Shares of the selected ceremony, possibly with duplicates.
+	threshold  int                  // Zero if the input does not say.
+	presented  int                  // Number of share lines in the input, including invalid ones.
+	headers    map[string]string
+}
+
+// readInputShares reads the shares of a single ceremony from in. Lines that are not valid shares are reported to
+// diag.
+func readInputShares(in io.Reader, opts recoverOptions, diag io.Writer) (inputShares, error) {
+	if opts.decryptShares != nil {
+		var err error
+
+		in, err = opts.decryptShares(in)
+		if err != nil {
+			return inputShares{}, err
+		}
+	}
+
+	in, err := readCBORShares(in)
+	if err != nil {
+		return inputShares{}, err
+	}
+
+	in, err = readJSONShares(in)
+	if err != nil {
+		return inputShares{}, err
+	}
+
+	in, err = readCSVShares(in)
+	if err != nil {
+		return inputShares{}, err
+	}
 
-	var secrets []sharedsecret.Share
+	in, err = readYAMLShares(in)
+	if err != nil {
+		return inputShares{}, err
+	}
+
+	in, err = decryptArmoredShares(in, pemShareType, pemShareLine)
+	if err != nil {
+		return inputShares{}, err
+	}
+
+	scanner := shamir.NewScanner(in)
+
+	var (
+		read      []ceremonyShare
+		threshold int
+		claims    []int
+		presented int
+		headers   = make(map[string]string)
+
+		// encodings are the encodings of the shares in read, to read all of them as hex values if there are any.
+		encodings []string
+	)
 
 	for scanner.Scan() {
 		t := strings.TrimSpace(scanner.Text())
 
+		if k, ok := shamir.ParseThreshold(t); ok {
+			threshold = k
+			continue
+		}
+
 		if t == "" || strings.HasPrefix(t, "secret: ") || strings.HasPrefix(t, "shares") {
 			continue
 		}
 
-		var s sharedsecret.Share
+		if name, value, ok := parseHeader(t); ok {
+			headers[name] = value
+			continue
+		}
+
+		presented++
+
+		_, t = splitShareLabel(t)
 
-		err := s.UnmarshalText([]byte(t))
+		unsigned, err := stripSignature(opts.verifyKey, t)
 		if err != nil {
 			fmt.Fprintf(diag, "reading share %q: %s\n", t, err)
 			continue
 		}
 
-		secrets = append(secrets, s)
+		t = unsigned
+
+		id, share := splitCeremonyID(t)
+
+		s, err := parseLabeledShare(share)
+		if err != nil {
+			fmt.Fprintf(diag, "reading share %q: %s\n", t, err)
+			continue
+		}
+
+		if err := shamir.CheckShare(s.share); err != nil {
+			fmt.Fprintf(diag, "reading share %q: %s\n", t, err)
+			continue
+		}
+
+		if opts.hmacKey != nil {
+			err := verifyShareTag(opts.hmacKey, s)
+			if err != nil && s.encoding == "" {
+				// The tag is over the hex value if the value is a hex value without any of the digits a to f.
+				h := s
+				h.share, h.encoding = hexShare(s.share), hexEncoding
+
+				if verifyShareTag(opts.hmacKey, h) == nil {
+					s, err = h, nil
+				}
+			}
+
+			if err != nil {
+				fmt.Fprintf(diag, "reading share %q: %s\n", t, err)
+				continue
+			}
+		}
+
+		if s.label != "" {
+			fmt.Fprintf(diag, "share %s is labeled %q\n", shareIndex(s.share), s.label)
+		}
+
+		if s.threshold != 0 {
+			claims = append(claims, s.threshold)
+		}
+
+		encoding := s.encoding
+		if isBERShare(share) {
+			encoding = "BER-TLV"
+		}
+
+		read = append(read, ceremonyShare{line: t, id: id, share: s.share})
+		encodings = append(encodings, encoding)
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return inputShares{}, err
+	}
+
+	if slices.Contains(encodings, hexEncoding) {
+		for i, encoding := range encodings {
+			switch encoding {
+			case "":
+				read[i].share = hexShare(read[i].share)
+			case hexEncoding:
+			default:
+				return inputShares{}, fmt.Errorf("Share %q is in the %s encoding, but other shares have hex values.", read[i].line, encoding)
+			}
+		}
+	}
+
+	ceremonyID, secrets, err := selectCeremony(opts.ceremonyID, read, diag)
+	if err != nil {
+		return inputShares{}, err
+	}
+
+	claimed, err := shareThreshold(claims, diag)
+	if err != nil {
+		return inputShares{}, err
+	}
+
+	if threshold == 0 {
+		threshold = claimed
+	} else if claimed != 0 && claimed != threshold {
+		fmt.Fprintf(diag, "shares claim a threshold of %d, but the input needs %d\n", claimed, threshold)
+	}
+
+	return inputShares{
+		ceremonyID: ceremonyID,
+		shares:     secrets,
+		threshold:  threshold,
+		presented:  presented,
+		headers:    headers,
+	}, nil
+}
+
+// recoverShared reads shares from in and returns the number they share along with the headers of the input. Unlike
+// cmdRecover, it does not undo the transformations the headers describe, like wrapping or masking with a passphrase.
+func recoverShared(in io.Reader, opts recoverOptions, diag io.Writer) (*big.Int, map[string]string, error) {
+	r, err := readInputShares(in, opts, diag)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ceremonyID, threshold, presented, headers := r.ceremonyID, r.threshold, r.presented, r.headers
+
+	secrets, err := shamir.Dedupe(r.shares, diag)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if opts.verbose {
+		for _, share := range secrets {
+			fmt.Fprintf(diag, "accepted share index=%s\n", shareIndex(share))
+		}
+	}
+
+	if len(secrets) == 0 || len(secrets) < threshold {
+		return nil, nil, &ErrInsufficientShares{Have: len(secrets), Need: threshold}
+	}
+
+	if threshold == 0 && len(secrets) == 1 {
+		fmt.Fprintln(diag, "only one valid share was read and the threshold is unknown, the secret is only correct if one share is enough")
+	}
+
+	now := opts.now
+	if now == nil {
+		now = time.Now
+	}
+
+	if opts.timeWindow != nil {
+		err := opts.timeWindow.check(now())
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if v, ok := headers[timeWindowHeader]; ok {
+		w, err := parseTimeWindowHeader(v)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		err = w.check(now())
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if opts.authorize != nil {
+		err := opts.authorize(ceremonyID, threshold)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if opts.verbose {
+		fmt.Fprintf(diag, "recovered using %d of %d presented shares\n", len(secrets), presented)
+	}
+
+	secret, err := shamir.Combine(secrets)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return secret, headers, nil
+}
+
+func cmdRecover(in io.Reader, opts recoverOptions, diag io.Writer, out io.Writer) error {
+	switch opts.format {
+	case "", "text", "json":
+	default:
+		return fmt.Errorf("Unknown format %q.", opts.format)
+	}
+
+	secret, headers, err := recoverShared(in, opts, diag)
+	if err != nil {
+		return err
+	}
+	defer zeroSecret(secret)
+
+	if recipient, ok := headers[recipientHeader]; ok {
+		if opts.unwrapKey == nil {
+			return errors.New("The secret is wrapped, recovering it requires the private key of the recipient.")
+		}
+
+		var err error
+
+		secret, err = unwrapRecoveredSecret(secret, opts.unwrapKey, recipient)
+		if err != nil {
+			return err
+		}
+		defer zeroSecret(secret)
+	} else if opts.unwrapKey != nil {
+		return errors.New("The secret is not wrapped.")
+	}
+
+	if opts.passphrase != nil {
+		if _, ok := headers[saltHeader]; !ok {
+			return errors.New("Shares do not contain a passphrase salt.")
+		}
+
+		passphrase, err := opts.passphrase()
+		if err != nil {
+			return err
+		}
+
+		secret, err = unmaskPassphraseSecret(secret, passphrase, headers)
+		if err != nil {
+			return err
+		}
+		defer zeroSecret(secret)
+	} else if _, ok := headers[saltHeader]; ok {
+		return errors.New("The secret is masked with a passphrase, recover it with -derive-from-passphrase.")
+	}
+
+	if opts.checkPrefix != "" {
+		err := checkSecretPrefix(secret, opts.checkPrefix)
+		if err != nil {
+			return err
+		}
 	}
 
-	secret := sharedsecret.Recover(secrets...)
+	encode := opts.encodeSecret
+
+	if v, ok := headers[wifHeader]; ok && encode == nil {
+		params, err := parseWIFHeader(v)
+		if err != nil {
+			return err
+		}
+
+		encode = params.encode
+	}
 
-	fmt.Fprintln(out, secret.Text(62))
+	if v, ok := headers[totpHeader]; ok && encode == nil {
+		params, err := parseTOTPHeader(v)
+		if err != nil {
+			return err
+		}
+
+		encode = params.encode
+	}
+
+	binaryLen, binary, err := parseBinaryEncoding(headers[encodingHeader])
+	if err != nil {
+		return err
+	}
+
+	if binary != opts.binary {
+		if binary {
+			return errors.New("The secret is binary, recover it with -binary.")
+		}
+
+		return errors.New("The shares do not contain a binary secret.")
+	}
+
+	if binary {
+		if opts.format == "json" {
+			return errors.New("Binary secrets are not supported in the JSON format.")
+		}
+
+		raw, err := binarySecretBytes(secret, binaryLen)
+		if err != nil {
+			return err
+		}
+
+		_, err = out.Write(raw)
+
+		return err
+	}
+
+	if v, ok := headers[encodingHeader]; ok && encode == nil {
+		if v != textEncoding {
+			return fmt.Errorf("Unknown secret encoding %q.", v)
+		}
+
+		encode = encodeTextSecret
+	}
+
+	encoded, err := encodeSecret(encode, secret)
+	if err != nil {
+		return err
+	}
+
+	if opts.format == "json" {
+		return json.NewEncoder(out).Encode(jsonSecret{Secret: encoded})
+	}
+
+	fmt.Fprintln(out, encoded)
 
 	return nil
 }
 
-func die(err error, printUsage bool) {
-	fmt.Fprintln(os.Stderr, err.Error())
+// encodeSecret formats secret with encode, or in base 62 if encode is nil.
+func encodeSecret(encode func(*big.Int) (string, error), secret *big.Int) (string, error) {
+	if encode == nil {
+		return secret.Text(62), nil
+	}
 
-	if printUsage {
-		fmt.Fprintf(os.Stderr, "\nUsage of %s:\n", os.Args[0])
-		flag.PrintDefaults()
+	return encode(secret)
+}
+
+// parseShare parses a single share line in any of the supported formats.
+func parseShare(line string) (sharedsecret.Share, error) {
+	s, err := parseLabeledShare(line)
+
+	return s.share, err
+}
+
+// shareIndex returns the index part of a share.
+func shareIndex(share sharedsecret.Share) string {
+	return strings.SplitN(share.String(), ",", 2)[0]
+}
+
+// waitAndShred shreds the named file once after has passed if timed is set. If onExit is set, the file is shredded
+// when the process receives SIGINT or SIGTERM before that, or right away if timed is not set, since the process exits
+// afterwards.
+func waitAndShred(name string, timed bool, after time.Duration, onExit bool) error {
+	if !timed {
+		return shredFile(name)
 	}
 
-	os.Exit(1)
+	timer := shredAfter(name, after)
+
+	sig := make(chan os.Signal, 1)
+
+	if onExit {
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(sig)
+	}
+
+	select {
+	case err := <-timer:
+		return err
+	case <-sig:
+		return shredFile(name)
+	}
 }
 
-func main() {
-	doRecover := flag.Bool("recover", false, "Recover shares instead of generating")
-	minShares := flag.Int("k", 3, "Minimum number of shares required. Must be <= n.")
-	numShares := flag.Int("n", 5, "How many shares to generate")
-	secrets := flag.String("secrets", "-", "File to read secrets from. Use - to read from stdin.")
+// openInput opens the input named by the -secrets flag. It is "-" for stdin, a file name, a glob pattern or a comma
+// separated list of those. Multiple files are read in order, as if their lines were in one file.
+func openInput(name string) (io.ReadCloser, error) {
+	var names []string
 
-	flag.Parse()
+	for _, part := range strings.Split(name, ",") {
+		if part == "-" || !strings.ContainsAny(part, "*?[") {
+			names = append(names, part)
+			continue
+		}
 
-	if !*doRecover {
-		err := cmdGenerate(*numShares, *minShares, os.Stdout)
+		matches, err := filepath.Glob(part)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("No files match %q.", part)
+		}
+
+		names = append(names, matches...)
+	}
+
+	if len(names) == 1 {
+		return openInputFile(names[0])
+	}
 
+	var (
+		in      multiInput
+		readers []io.Reader
+	)
+
+	for _, name := range names {
+		f, err := openInputFile(name)
 		if err != nil {
-			die(err, true)
+			in.Close()
+			return nil, err
 		}
 
-		return
+		// The newline keeps the last line of a file without a trailing newline apart from the next file.
+		in.files = append(in.files, f)
+		readers = append(readers, f, strings.NewReader("\n"))
 	}
 
-	var fh io.ReadCloser
+	in.Reader = io.MultiReader(readers...)
 
-	switch *secrets {
-	case "-":
-		fh = os.Stdin
-	default:
-		fh, err := os.Open(*secrets)
+	return &in, nil
+}
+
+func openInputFile(name string) (io.ReadCloser, error) {
+	if name == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+
+	return os.Open(name)
+}
+
+// multiInput reads several input files one after the other.
+type multiInput struct {
+	io.Reader
+	files []io.ReadCloser
+}
+
+func (m *multiInput) Close() error {
+	var errs []error
+
+	for _, f := range m.files {
+		errs = append(errs, f.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+// readSource loads the shares from src and returns them as a reader suitable for cmdRecover.
+func readSource(src shareSource) (io.Reader, error) {
+	lines, err := src.LoadShares()
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.NewReader(strings.Join(lines, "\n")), nil
+}
+
+func printError(err error, printUsage bool) {
+	fmt.Fprintln(os.Stderr, err.Error())
+
+	if printUsage {
+		fmt.Fprintf(os.Stderr, "\nUsage of %s:\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+}
+
+func main() {
+	c := newCLIFlags(flag.CommandLine)
+	flag.Parse()
+
+	restore := func() {}
+
+	if c.sanitizeOutput {
+		var err error
+
+		restore, err = sanitizeStdio()
 		if err != nil {
-			die(err, false)
+			printError(err, false)
+			os.Exit(1)
 		}
-		defer fh.Close()
 	}
 
-	err := cmdRecover(fh, os.Stderr, os.Stdout)
+	err := run(c)
+	if err != nil {
+		var usage usageError
+		printError(err, errors.As(err, &usage))
+	}
+
+	restore()
 
 	if err != nil {
-		die(err, true)
+		os.Exit(1)
 	}
 }
@@ -2,8 +2,18 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/farhaven/secret/shamir"
 )
 
 func TestRecover_onlyShares(t *testing.T) {
@@ -20,7 +30,7 @@ func TestRecover_onlyShares(t *testing.T) {
 		errBuf bytes.Buffer
 	)
 
-	err := cmdRecover(inBuf, &errBuf, &outBuf)
+	err := cmdRecover(inBuf, recoverOptions{}, &errBuf, &outBuf)
 	if err != nil {
 		t.Fatalf("unexpected error: %s", err)
 	}
@@ -53,7 +63,7 @@ func TestRecover_withGarbage(t *testing.T) {
 		errBuf bytes.Buffer
 	)
 
-	err := cmdRecover(inBuf, &errBuf, &outBuf)
+	err := cmdRecover(inBuf, recoverOptions{}, &errBuf, &outBuf)
 	if err != nil {
 		t.Fatalf("unexpected error: %s", err)
 	}
@@ -87,7 +97,7 @@ func TestRecover_fromOutput(t *testing.T) {
 		errBuf bytes.Buffer
 	)
 
-	err := cmdRecover(inBuf, &errBuf, &outBuf)
+	err := cmdRecover(inBuf, recoverOptions{}, &errBuf, &outBuf)
 	if err != nil {
 		t.Fatalf("unexpected error: %s", err)
 	}
@@ -102,6 +112,225 @@ func TestRecover_fromOutput(t *testing.T) {
 	}
 }
 
+func TestRecover_duplicateShares(t *testing.T) {
+	secrets := []string{
+		"1,19943338053965968504353533017903769217",
+		"2,161872477868088873785792630750634181303",
+		"1,19943338053965968504353533017903769217",
+		"5,160274174127002500413544256698187925606",
+		"2,161872477868088873785792630750634181303",
+	}
+
+	var (
+		outBuf bytes.Buffer
+		errBuf bytes.Buffer
+	)
+
+	err := cmdRecover(strings.NewReader(strings.Join(secrets, "\n")), recoverOptions{}, &errBuf, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	wantSecret := "7uPIBqGKMPpProBYFFR3S\n"
+	if outBuf.String() != wantSecret {
+		t.Errorf("unexpected secret. want %q, have %q", wantSecret, outBuf.String())
+	}
+
+	expectDiagnostic := "reading share \"1,19943338053965968504353533017903769217\": duplicate index 1\nreading share \"2,161872477868088873785792630750634181303\": duplicate index 2\n"
+	if errBuf.String() != expectDiagnostic {
+		t.Errorf("unexpected diagnostic: %q", errBuf.String())
+	}
+}
+
+func TestRecover_conflictingIndices(t *testing.T) {
+	secrets := []string{
+		"1,19943338053965968504353533017903769217",
+		"2,161872477868088873785792630750634181303",
+		"2,1",
+		"5,160274174127002500413544256698187925606",
+	}
+
+	var outBuf bytes.Buffer
+
+	err := cmdRecover(strings.NewReader(strings.Join(secrets, "\n")), recoverOptions{}, &bytes.Buffer{}, &outBuf)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	if outBuf.Len() != 0 {
+		t.Errorf("unexpected output: %q", outBuf.String())
+	}
+}
+
+func TestRecover_largeIndices(t *testing.T) {
+	// Shares of 42 on the line 42 + x. The second index is 2^64 + 1, which must not be mistaken for index 1.
+	secrets := []string{
+		"1,43",
+		"18446744073709551617,18446744073709551659",
+	}
+
+	var outBuf bytes.Buffer
+
+	err := cmdRecover(strings.NewReader(strings.Join(secrets, "\n")), recoverOptions{}, &bytes.Buffer{}, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if want := big.NewInt(42).Text(62) + "\n"; outBuf.String() != want {
+		t.Errorf("unexpected secret. want %q, have %q", want, outBuf.String())
+	}
+}
+
+func TestRecover_indexZero(t *testing.T) {
+	secrets := []string{
+		"0,42",
+		"1,19943338053965968504353533017903769217",
+		"2,161872477868088873785792630750634181303",
+		"5,160274174127002500413544256698187925606",
+	}
+
+	var (
+		outBuf bytes.Buffer
+		errBuf bytes.Buffer
+	)
+
+	err := cmdRecover(strings.NewReader(strings.Join(secrets, "\n")), recoverOptions{}, &errBuf, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	wantSecret := "7uPIBqGKMPpProBYFFR3S\n"
+	if outBuf.String() != wantSecret {
+		t.Errorf("unexpected secret. want %q, have %q", wantSecret, outBuf.String())
+	}
+
+	expectDiagnostic := "reading share \"0,42\": invalid index 0\n"
+	if errBuf.String() != expectDiagnostic {
+		t.Errorf("unexpected diagnostic: %q", errBuf.String())
+	}
+}
+
+func TestRecover_indexOutOfRange(t *testing.T) {
+	secrets := []string{
+		"170141183460469231731687303715884105727,42",
+		"1,19943338053965968504353533017903769217",
+		"170141183460469231731687303715884105728,6",
+		"2,161872477868088873785792630750634181303",
+		"5,160274174127002500413544256698187925606",
+	}
+
+	var (
+		outBuf bytes.Buffer
+		errBuf bytes.Buffer
+	)
+
+	err := cmdRecover(strings.NewReader(strings.Join(secrets, "\n")), recoverOptions{}, &errBuf, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	wantSecret := "7uPIBqGKMPpProBYFFR3S\n"
+	if outBuf.String() != wantSecret {
+		t.Errorf("unexpected secret. want %q, have %q", wantSecret, outBuf.String())
+	}
+
+	expectDiagnostic := "reading share \"170141183460469231731687303715884105727,42\": invalid index 170141183460469231731687303715884105727\n" +
+		"reading share \"170141183460469231731687303715884105728,6\": invalid index 170141183460469231731687303715884105728\n"
+	if errBuf.String() != expectDiagnostic {
+		t.Errorf("unexpected diagnostic: %q", errBuf.String())
+	}
+}
+
+func TestRecover_textIndexOutOfRange(t *testing.T) {
+	in := "secret-encoding: text\n1,5\n170141183460469231731687303715884105728,6\n"
+
+	var outBuf bytes.Buffer
+
+	err := cmdRecover(strings.NewReader(in), recoverOptions{}, &bytes.Buffer{}, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if outBuf.String() != "\x05\n" {
+		t.Errorf("unexpected secret %q", outBuf.String())
+	}
+}
+
+func TestRecover_verbose(t *testing.T) {
+	secrets := []string{
+		"secret: 7uPIBqGKMPpProBYFFR3S",
+		"1,19943338053965968504353533017903769217",
+		"foo",
+		"2,161872477868088873785792630750634181303",
+		"5,160274174127002500413544256698187925606",
+	}
+
+	var (
+		outBuf bytes.Buffer
+		errBuf bytes.Buffer
+	)
+
+	err := cmdRecover(strings.NewReader(strings.Join(secrets, "\n")), recoverOptions{verbose: true}, &errBuf, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	wantSecret := "7uPIBqGKMPpProBYFFR3S\n"
+	if outBuf.String() != wantSecret {
+		t.Errorf("unexpected secret. want %q, have %q", wantSecret, outBuf.String())
+	}
+
+	expectDiagnostic := "reading share \"foo\": expected two parts\naccepted share index=1\naccepted share index=2\naccepted share index=5\nrecovered using 3 of 4 presented shares\n"
+	if errBuf.String() != expectDiagnostic {
+		t.Errorf("unexpected diagnostic: %q", errBuf.String())
+	}
+}
+
+func TestRecover_insufficientShares(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		input string
+		have  int
+		need  int
+	}{
+		{"below threshold", "shares (need at least 3 of these for recovery):\n1,19943338053965968504353533017903769217\n2,161872477868088873785792630750634181303", 2, 3},
+		{"below threshold header", "threshold: 3\n1,19943338053965968504353533017903769217\n2,161872477868088873785792630750634181303", 2, 3},
+		{"no valid shares", "foo\nbar", 0, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var outBuf bytes.Buffer
+
+			err := cmdRecover(strings.NewReader(tc.input), recoverOptions{}, &bytes.Buffer{}, &outBuf)
+
+			var insufficient *ErrInsufficientShares
+			if !errors.As(err, &insufficient) {
+				t.Fatalf("want ErrInsufficientShares, have %v", err)
+			}
+
+			if insufficient.Have != tc.have || insufficient.Need != tc.need {
+				t.Errorf("want %d of %d shares, have %d of %d", tc.have, tc.need, insufficient.Have, insufficient.Need)
+			}
+
+			if outBuf.Len() != 0 {
+				t.Errorf("unexpected output: %q", outBuf.String())
+			}
+		})
+	}
+}
+
+func TestRecover_singleShare(t *testing.T) {
+	var errBuf bytes.Buffer
+
+	err := cmdRecover(strings.NewReader("1,19943338053965968504353533017903769217"), recoverOptions{}, &errBuf, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !strings.Contains(errBuf.String(), "only one valid share") {
+		t.Errorf("unexpected diagnostic: %q", errBuf.String())
+	}
+}
+
 func TestGenerate_invalidParams(t *testing.T) {
 	testCases := map[string]struct {
 		n         int
@@ -117,7 +346,7 @@ func TestGenerate_invalidParams(t *testing.T) {
 
 	for desc, tc := range testCases {
 		t.Run(desc, func(t *testing.T) {
-			err := cmdGenerate(tc.n, tc.k, nil)
+			err := cmdGenerate(tc.n, tc.k, generateOptions{}, nil)
 
 			if err == nil {
 				t.Fatal("expected error, got nil")
@@ -133,18 +362,18 @@ func TestGenerate_invalidParams(t *testing.T) {
 func TestGenerate(t *testing.T) {
 	var buf bytes.Buffer
 
-	err := cmdGenerate(5, 3, &buf)
+	err := cmdGenerate(5, 3, generateOptions{}, &buf)
 	if err != nil {
 		t.Fatalf("unexpected error %s", err)
 	}
 
 	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
 
-	if len(lines) != 7 {
-		t.Fatalf("want 7 lines, have %d: %q", len(lines), buf.String())
+	if len(lines) != 8 {
+		t.Fatalf("want 8 lines, have %d: %q", len(lines), buf.String())
 	}
 
-	for _, line := range lines[2:] {
+	for _, line := range lines[3:] {
 		parts := strings.Split(line, ",")
 		if len(parts) != 2 {
 			t.Errorf("unexpected number of parts: want 2, have %q", parts)
@@ -159,7 +388,7 @@ func TestRoundtrip(t *testing.T) {
 		outBuf bytes.Buffer
 	)
 
-	err := cmdGenerate(5, 3, &buf)
+	err := cmdGenerate(5, 3, generateOptions{}, &buf)
 	if err != nil {
 		t.Fatalf("unexpected error: %s", err)
 	}
@@ -181,7 +410,7 @@ func TestRoundtrip(t *testing.T) {
 		t.Errorf("zero-length secret generated")
 	}
 
-	err = cmdRecover(&buf, &errBuf, &outBuf)
+	err = cmdRecover(&buf, recoverOptions{}, &errBuf, &outBuf)
 	if err != nil {
 		t.Fatalf("unexpected error: %s", err)
 	}
@@ -194,3 +423,532 @@ func TestRoundtrip(t *testing.T) {
 		t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
 	}
 }
+
+func TestGenerate_noOversample(t *testing.T) {
+	opts := generateOptions{noOversample: true}
+
+	if have := opts.poolSize(5); have != 5 {
+		t.Errorf("want pool of 5 shares, have %d", have)
+	}
+
+	if have := (generateOptions{}).poolSize(5); have != shamir.MinPool {
+		t.Errorf("want pool of %d shares by default, have %d", shamir.MinPool, have)
+	}
+
+	var (
+		buf    bytes.Buffer
+		errBuf bytes.Buffer
+		outBuf bytes.Buffer
+	)
+
+	err := cmdGenerate(5, 3, opts, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	secret := strings.TrimPrefix(strings.SplitN(buf.String(), "\n", 2)[0], "secret: ")
+
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n")[3:] {
+		index := strings.SplitN(line, ",", 2)[0]
+		if len(index) != 1 || index < "1" || index > "5" {
+			t.Errorf("unexpected index %q outside of 1..5", index)
+		}
+	}
+
+	err = cmdRecover(&buf, recoverOptions{}, &errBuf, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if outBuf.String() != secret+"\n" {
+		t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
+	}
+}
+
+func TestOpenInput_multipleFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	shares := []string{
+		"1,19943338053965968504353533017903769217",
+		"2,161872477868088873785792630750634181303",
+		"5,160274174127002500413544256698187925606",
+	}
+
+	var names []string
+
+	for i, share := range shares {
+		name := filepath.Join(dir, fmt.Sprintf("share-%d.txt", i))
+
+		// The last file lacks a trailing newline.
+		if i < len(shares)-1 {
+			share += "\n"
+		}
+
+		err := os.WriteFile(name, []byte(share), 0600)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		names = append(names, name)
+	}
+
+	for _, tc := range []struct {
+		name  string
+		input string
+	}{
+		{"list", strings.Join(names, ",")},
+		{"glob", filepath.Join(dir, "share-*.txt")},
+		{"glob and file", filepath.Join(dir, "share-[01].txt") + "," + names[2]},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			in, err := openInput(tc.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			defer in.Close()
+
+			var outBuf bytes.Buffer
+
+			err = cmdRecover(in, recoverOptions{}, &bytes.Buffer{}, &outBuf)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			wantSecret := "7uPIBqGKMPpProBYFFR3S\n"
+			if outBuf.String() != wantSecret {
+				t.Errorf("unexpected secret. want %q, have %q", wantSecret, outBuf.String())
+			}
+		})
+	}
+
+	for _, input := range []string{filepath.Join(dir, "missing-*.txt"), names[0] + "," + filepath.Join(dir, "missing.txt")} {
+		_, err := openInput(input)
+		if err == nil {
+			t.Errorf("expected error for %q, got nil", input)
+		}
+	}
+}
+
+func TestGenerate_silent(t *testing.T) {
+	var (
+		outBuf    bytes.Buffer
+		secretBuf bytes.Buffer
+	)
+
+	err := cmdGenerate(5, 3, generateOptions{silent: true, secretOut: &secretBuf}, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(outBuf.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("unexpected output: %q", outBuf.String())
+	}
+
+	for _, line := range lines {
+		_, err := parseShare(line)
+		if err != nil {
+			t.Errorf("reading share %q: %s", line, err)
+		}
+	}
+
+	var recovered bytes.Buffer
+
+	err = cmdRecover(strings.NewReader(strings.Join(lines[:3], "\n")), recoverOptions{}, &bytes.Buffer{}, &recovered)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if secretBuf.String() != "secret: "+recovered.String() {
+		t.Errorf("unexpected secret. want %q, have %q", secretBuf.String(), recovered.String())
+	}
+
+	for _, opts := range []generateOptions{
+		{silent: true},
+		{silent: true, secretOut: &bytes.Buffer{}, header: []string{encodingHeader + ": " + textEncoding}},
+		{silent: true, secretOut: &bytes.Buffer{}, format: "json"},
+	} {
+		err := cmdGenerate(5, 3, opts, &bytes.Buffer{})
+		if err == nil {
+			t.Errorf("expected error for %+v, got nil", opts)
+		}
+	}
+}
+
+func TestGenerate_dryRun(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "shares")
+
+	var (
+		buf       bytes.Buffer
+		secretBuf bytes.Buffer
+	)
+
+	opts := generateOptions{dryRun: true, secretOut: &secretBuf, sinks: []shareSink{&outDirSink{dir: dir}}}
+
+	err := cmdGenerate(5, 3, opts, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := fmt.Sprintf("parameters OK: n=5 k=3 pool=%d\n", shamir.PoolSize(5))
+	if buf.String() != want {
+		t.Errorf("unexpected output. want %q, have %q", want, buf.String())
+	}
+
+	if secretBuf.Len() != 0 {
+		t.Errorf("unexpected secret output: %q", secretBuf.String())
+	}
+
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("shares were stored: %v", err)
+	}
+
+	buf.Reset()
+
+	err = cmdGenerate(5, 3, generateOptions{dryRun: true, noOversample: true}, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if buf.String() != "parameters OK: n=5 k=3 pool=5\n" {
+		t.Errorf("unexpected output: %q", buf.String())
+	}
+
+	buf.Reset()
+
+	err = cmdGenerate(3, 5, generateOptions{dryRun: true}, &buf)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	if buf.Len() != 0 {
+		t.Errorf("unexpected output: %q", buf.String())
+	}
+}
+
+func TestGenerate_indices(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := cmdGenerate(3, 2, generateOptions{indices: []int64{7, 1, 42}}, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+
+	for i, want := range []string{"7", "1", "42"} {
+		if have := shareLineIndex(lines[3+i]); have != want {
+			t.Errorf("unexpected index of share %d. want %s, have %s", i, want, have)
+		}
+	}
+
+	var outBuf bytes.Buffer
+
+	err = cmdRecover(strings.NewReader(lines[3]+"\n"+lines[5]), recoverOptions{}, &bytes.Buffer{}, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if "secret: "+outBuf.String() != lines[0]+"\n" {
+		t.Errorf("unexpected recovered secret. want %q, have %q", lines[0], outBuf.String())
+	}
+
+	for _, opts := range []generateOptions{
+		{indices: []int64{1, 2}},
+		{indices: []int64{1, 2, 2}},
+		{indices: []int64{0, 1, 2}},
+		{indices: []int64{-1, 1, 2}},
+		{indices: []int64{1, 2, 4}, noOversample: true},
+		{indices: []int64{1, 2, 3}, noiseShares: 2},
+	} {
+		var buf bytes.Buffer
+
+		err := cmdGenerate(3, 2, opts, &buf)
+		if err == nil {
+			t.Errorf("expected error for indices %v, got nil", opts.indices)
+		}
+
+		if buf.Len() != 0 {
+			t.Errorf("unexpected output: %q", buf.String())
+		}
+	}
+}
+
+// TestBackwardCompatibility recovers archived shares in the output formats of earlier versions. Any change that breaks
+// the recovery of one of them breaks the recovery of shares that custodians may still hold.
+func TestBackwardCompatibility(t *testing.T) {
+	const want = "pSG3qSqueavxHguT1r30k\n"
+
+	for _, tc := range []struct {
+		name  string
+		input string
+	}{
+		{
+			"shares header",
+			"secret: pSG3qSqueavxHguT1r30k\n" +
+				"shares:\n" +
+				"4545,81029291050530278684613982716275613045\n" +
+				"2208,39305801000292589657149442287448880404\n" +
+				"6215,151832763310753264624197095471167951528\n",
+		},
+		{
+			"threshold header",
+			"secret: pSG3qSqueavxHguT1r30k\n" +
+				"shares (need at least 2 of these for recovery):\n" +
+				"4545,81029291050530278684613982716275613045\n" +
+				"2208,39305801000292589657149442287448880404\n" +
+				"6215,151832763310753264624197095471167951528\n",
+		},
+		{
+			"ceremony IDs",
+			"secret: pSG3qSqueavxHguT1r30k\n" +
+				"shares (need at least 2 of these for recovery):\n" +
+				"id:bc29e488-e686-4f6e-9514-8f841c45f7e6:4545,81029291050530278684613982716275613045\n" +
+				"id:bc29e488-e686-4f6e-9514-8f841c45f7e6:2208,39305801000292589657149442287448880404\n" +
+				"id:bc29e488-e686-4f6e-9514-8f841c45f7e6:6215,151832763310753264624197095471167951528\n",
+		},
+		{
+			"two shares without headers",
+			"2208,39305801000292589657149442287448880404\n" +
+				"6215,151832763310753264624197095471167951528\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var outBuf bytes.Buffer
+
+			err := cmdRecover(strings.NewReader(tc.input), recoverOptions{}, &bytes.Buffer{}, &outBuf)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if outBuf.String() != want {
+				t.Errorf("unexpected recovered secret. want %q, have %q", want, outBuf.String())
+			}
+		})
+	}
+}
+
+func TestRecover_longShares(t *testing.T) {
+	// The secret is split into a lot of chunks, which are all packed into the value of each share.
+	secret := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 240000), big.NewInt(1))
+
+	var buf bytes.Buffer
+
+	err := cmdGenerate(2, 2, generateOptions{secret: secret, noOversample: true}, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines[3]) <= 64000 {
+		t.Fatalf("share line is only %d bytes long", len(lines[3]))
+	}
+
+	var outBuf bytes.Buffer
+
+	err = cmdRecover(strings.NewReader(strings.Join(lines[3:], "\n")), recoverOptions{}, &bytes.Buffer{}, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if outBuf.String() != secret.Text(62)+"\n" {
+		t.Errorf("unexpected recovered secret of %d bytes", outBuf.Len())
+	}
+}
+
+func TestRecover_checkPrefix(t *testing.T) {
+	var genBuf bytes.Buffer
+
+	err := cmdGenerate(5, 3, generateOptions{}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	secret := strings.TrimPrefix(strings.SplitN(genBuf.String(), "\n", 2)[0], "secret: ")
+
+	mismatch := "0" + secret[1:4]
+	if secret[0] == '0' {
+		mismatch = "1" + secret[1:4]
+	}
+
+	for _, tc := range []struct {
+		prefix  string
+		wantErr bool
+	}{
+		{secret[:4], false},
+		{secret, false},
+		{mismatch, true},
+		{secret + "0", true},
+	} {
+		t.Run(tc.prefix, func(t *testing.T) {
+			var outBuf bytes.Buffer
+
+			err := cmdRecover(strings.NewReader(genBuf.String()), recoverOptions{checkPrefix: tc.prefix}, &bytes.Buffer{}, &outBuf)
+			if tc.wantErr {
+				if !errors.Is(err, ErrMismatch) {
+					t.Fatalf("want ErrMismatch, have %v", err)
+				}
+
+				if outBuf.Len() != 0 {
+					t.Errorf("secret written despite the mismatch: %q", outBuf.String())
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if outBuf.String() != secret+"\n" {
+				t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
+			}
+		})
+	}
+}
+
+func TestGenerate_context(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := cmdGenerate(5, 3, generateOptions{ctx: context.Background()}, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 8 {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for name, opts := range map[string]generateOptions{
+		"canceled": {ctx: canceled},
+		"timeout":  {timeout: time.Nanosecond},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			err := cmdGenerate(100, 3, opts, &buf)
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+
+			if buf.Len() != 0 {
+				t.Errorf("unexpected output: %q", buf.String())
+			}
+		})
+	}
+}
+
+func TestGenerate_progress(t *testing.T) {
+	var (
+		genBuf      bytes.Buffer
+		progressBuf bytes.Buffer
+	)
+
+	err := cmdGenerate(5, 3, generateOptions{progress: &progressBuf}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if strings.Trim(progressBuf.String(), ".") != "\n" {
+		t.Errorf("unexpected progress output: %q", progressBuf.String())
+	}
+
+	secret := strings.TrimPrefix(strings.SplitN(genBuf.String(), "\n", 2)[0], "secret: ")
+
+	var outBuf bytes.Buffer
+
+	err = cmdRecover(&genBuf, recoverOptions{}, &bytes.Buffer{}, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if outBuf.String() != secret+"\n" {
+		t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
+	}
+}
+
+func TestWriteProgress(t *testing.T) {
+	var buf bytes.Buffer
+
+	stop := writeProgress(&buf, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	stop()
+
+	have := buf.String()
+	if !strings.HasPrefix(have, ".") || strings.Trim(have, ".") != "\n" {
+		t.Errorf("unexpected progress output: %q", have)
+	}
+}
+
+func FuzzRecover(f *testing.F) {
+	for _, seed := range []string{
+		"1,19943338053965968504353533017903769217\n2,161872477868088873785792630750634181303\n5,160274174127002500413544256698187925606",
+		"foo\nbar\n1,19943338053965968504353533017903769217\n2,161872477868088873785792630750634181303\n\n5,160274174127002500413544256698187925606\nthis is some random junk",
+		"secret: 1tMC82zztRsFLxQAz3ohEG\nshares:\n1,9039905250649971436987941679095917908\n2,149669079771399886069631951128619842789\n3,146260035808749368841095381324331189475",
+		"1,19943338053965968504353533017903769217\n2,161872477868088873785792630750634181303\n2,1\n5,160274174127002500413544256698187925606",
+		"0,42\n1,19943338053965968504353533017903769217",
+		"threshold: 3\nshares (need at least 3 of these for recovery):\n1,19943338053965968504353533017903769217\n2,161872477868088873785792630750634181303",
+		"secret: pSG3qSqueavxHguT1r30k\nshares (need at least 2 of these for recovery):\nid:bc29e488-e686-4f6e-9514-8f841c45f7e6:4545,81029291050530278684613982716275613045\nid:bc29e488-e686-4f6e-9514-8f841c45f7e6:2208,39305801000292589657149442287448880404",
+		"secret-encoding: rot13\n1,19943338053965968504353533017903769217\n2,161872477868088873785792630750634181303\n",
+		"1,19943338053965968504353533017903769217,mac=00ff,3,alice\n2,161872477868088873785792630750634181303,bob",
+		"1,1f\n2,2e",
+		"1_2NEpo7TZRRrLZSi2U\n2_3dEzj6ZvEyk3zP8Pn",
+		"secret-encoding: text\n1,5\n170141183460469231731687303715884105728,6",
+		"1,5\n18446744073709551617,6",
+		"",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		// Errors and garbage secrets are fine, but a failed recovery must not print anything.
+		var outBuf bytes.Buffer
+
+		err := cmdRecover(strings.NewReader(input), recoverOptions{}, io.Discard, &outBuf)
+		if err != nil && outBuf.Len() != 0 {
+			t.Errorf("unexpected output %q with error %s", outBuf.String(), err)
+		}
+
+		if strings.Contains(outBuf.String(), "<nil>") {
+			t.Errorf("no secret recovered, but output is %q", outBuf.String())
+		}
+	})
+}
+
+func BenchmarkGenerate(b *testing.B) {
+	b.ReportAllocs()
+
+	for b.Loop() {
+		err := cmdGenerate(5, 3, generateOptions{}, io.Discard)
+		if err != nil {
+			b.Fatalf("unexpected error: %s", err)
+		}
+	}
+}
+
+func BenchmarkRecover(b *testing.B) {
+	var genBuf bytes.Buffer
+
+	err := cmdGenerate(5, 3, generateOptions{}, &genBuf)
+	if err != nil {
+		b.Fatalf("unexpected error: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(genBuf.String()), "\n")
+	input := strings.Join(lines[3:6], "\n")
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		err := cmdRecover(strings.NewReader(input), recoverOptions{}, io.Discard, io.Discard)
+		if err != nil {
+			b.Fatalf("unexpected error: %s", err)
+		}
+	}
+}
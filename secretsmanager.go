@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+)
+
+// secretsManagerAPI is the part of the Secrets Manager client used by secretsManagerStore.
+type secretsManagerAPI interface {
+	CreateSecret(ctx context.Context, params *secretsmanager.CreateSecretInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.CreateSecretOutput, error)
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+	ListSecrets(ctx context.Context, params *secretsmanager.ListSecretsInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.ListSecretsOutput, error)
+}
+
+// secretsManagerStore stores shares in AWS Secrets Manager as secrets named <prefix>-share-<index>.
+type secretsManagerStore struct {
+	client     secretsManagerAPI
+	prefix     string
+	ceremonyID string
+}
+
+func newSecretsManagerStore(client secretsManagerAPI, prefix, ceremonyID string) *secretsManagerStore {
+	return &secretsManagerStore{client: client, prefix: prefix, ceremonyID: ceremonyID}
+}
+
+func (s *secretsManagerStore) StoreShares(shares []storedShare) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	for _, share := range shares {
+		index := share.index
+
+		tags := []types.Tag{
+			{Key: aws.String("managed-by"), Value: aws.String("github.com/farhaven/secret")},
+			{Key: aws.String("share-index"), Value: aws.String(index)},
+		}
+
+		if s.ceremonyID != "" {
+			tags = append(tags, types.Tag{Key: aws.String("ceremony-id"), Value: aws.String(s.ceremonyID)})
+		}
+
+		_, err := s.client.CreateSecret(ctx, &secretsmanager.CreateSecretInput{
+			Name:         aws.String(s.prefix + "-share-" + index),
+			SecretString: aws.String(share.line),
+			Tags:         tags,
+		})
+		if err != nil {
+			return fmt.Errorf("storing share in Secrets Manager: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *secretsManagerStore) LoadShares() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	prefix := s.prefix + "-share-"
+
+	var names []string
+
+	paginator := secretsmanager.NewListSecretsPaginator(s.client, &secretsmanager.ListSecretsInput{
+		Filters: []types.Filter{{Key: types.FilterNameStringTypeName, Values: []string{prefix}}},
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing shares in Secrets Manager: %w", err)
+		}
+
+		for _, entry := range page.SecretList {
+			// The name filter also matches names that only contain the prefix.
+			if name := aws.ToString(entry.Name); strings.HasPrefix(name, prefix) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	sort.Strings(names)
+
+	lines := make([]string, 0, len(names))
+
+	for _, name := range names {
+		out, err := s.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(name)})
+		if err != nil {
+			return nil, fmt.Errorf("reading share %s from Secrets Manager: %w", name, err)
+		}
+
+		lines = append(lines, aws.ToString(out.SecretString))
+	}
+
+	return lines, nil
+}
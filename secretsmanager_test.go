@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+)
+
+// fakeSecretsManager is an in-memory implementation of secretsManagerAPI.
+type fakeSecretsManager struct {
+	secrets map[string]string
+	tags    map[string][]types.Tag
+}
+
+func newFakeSecretsManager() *fakeSecretsManager {
+	return &fakeSecretsManager{secrets: make(map[string]string), tags: make(map[string][]types.Tag)}
+}
+
+func (f *fakeSecretsManager) CreateSecret(ctx context.Context, params *secretsmanager.CreateSecretInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.CreateSecretOutput, error) {
+	name := aws.ToString(params.Name)
+	if _, ok := f.secrets[name]; ok {
+		return nil, &types.ResourceExistsException{Message: aws.String("secret exists")}
+	}
+
+	f.secrets[name] = aws.ToString(params.SecretString)
+	f.tags[name] = params.Tags
+
+	return &secretsmanager.CreateSecretOutput{Name: params.Name}, nil
+}
+
+func (f *fakeSecretsManager) GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	value, ok := f.secrets[aws.ToString(params.SecretId)]
+	if !ok {
+		return nil, &types.ResourceNotFoundException{Message: aws.String("no such secret")}
+	}
+
+	return &secretsmanager.GetSecretValueOutput{SecretString: aws.String(value)}, nil
+}
+
+func (f *fakeSecretsManager) ListSecrets(ctx context.Context, params *secretsmanager.ListSecretsInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.ListSecretsOutput, error) {
+	out := &secretsmanager.ListSecretsOutput{}
+
+	for name := range f.secrets {
+		if strings.HasPrefix(name, params.Filters[0].Values[0]) {
+			out.SecretList = append(out.SecretList, types.SecretListEntry{Name: aws.String(name)})
+		}
+	}
+
+	return out, nil
+}
+
+func TestSecretsManager_roundtrip(t *testing.T) {
+	client := newFakeSecretsManager()
+	client.secrets["ceremony-shares"] = "not a share"
+
+	store := newSecretsManagerStore(client, "ceremony", "2026-10")
+
+	var genBuf bytes.Buffer
+
+	err := cmdGenerate(5, 3, generateOptions{sinks: []shareSink{store}}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(client.secrets) != 6 {
+		t.Fatalf("want 6 secrets, have %d", len(client.secrets))
+	}
+
+	for name, value := range client.secrets {
+		if name == "ceremony-shares" {
+			continue
+		}
+
+		index := strings.SplitN(value, ",", 2)[0]
+		if name != "ceremony-share-"+index {
+			t.Errorf("unexpected name %q for share %q", name, value)
+		}
+
+		tags := make(map[string]string)
+		for _, tag := range client.tags[name] {
+			tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+
+		if tags["share-index"] != index || tags["ceremony-id"] != "2026-10" || tags["managed-by"] == "" {
+			t.Errorf("unexpected tags for %q: %v", name, tags)
+		}
+	}
+
+	in, err := readSource(store)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var (
+		errBuf bytes.Buffer
+		outBuf bytes.Buffer
+	)
+
+	err = cmdRecover(in, recoverOptions{}, &errBuf, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if errBuf.Len() != 0 {
+		t.Errorf("unexpected diagnostic: %q", errBuf.String())
+	}
+
+	secret := strings.TrimPrefix(strings.SplitN(genBuf.String(), "\n", 2)[0], "secret: ")
+	if outBuf.String() != secret+"\n" {
+		t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
+	}
+}
+
+func TestSecretsManager_exists(t *testing.T) {
+	client := newFakeSecretsManager()
+	store := newSecretsManagerStore(client, "ceremony", "")
+
+	// Without oversampling both share sets have the indices 1 to 5.
+	err := cmdGenerate(5, 3, generateOptions{sinks: []shareSink{store}, noOversample: true}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err = cmdGenerate(5, 3, generateOptions{sinks: []shareSink{store}, noOversample: true}, &bytes.Buffer{})
+
+	var exists *types.ResourceExistsException
+	if !errors.As(err, &exists) {
+		t.Errorf("expected ResourceExistsException, got %v", err)
+	}
+}
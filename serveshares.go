@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// shareFile matches the names of files holding a single share and captures the share index.
+var shareFile = regexp.MustCompile(`^share-([0-9]+)\.txt$`)
+
+// readShareDir reads all share files from dir and returns their contents by share index.
+func readShareDir(dir string) (map[string][]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	shares := make(map[string][]byte)
+
+	for _, entry := range entries {
+		m := shareFile.FindStringSubmatch(entry.Name())
+		if m == nil || entry.IsDir() {
+			continue
+		}
+
+		buf, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		shares[m[1]] = buf
+	}
+
+	if len(shares) == 0 {
+		return nil, fmt.Errorf("%s: no share files found", dir)
+	}
+
+	return shares, nil
+}
+
+// shareServer hands out each share exactly once at /share/<index>. Done is closed once every share has been
+// retrieved.
+type shareServer struct {
+	mu     sync.Mutex
+	shares map[string][]byte
+	Done   chan struct{}
+}
+
+func newShareServer(shares map[string][]byte) *shareServer {
+	return &shareServer{shares: shares, Done: make(chan struct{})}
+}
+
+func (s *shareServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/healthz" {
+		fmt.Fprintln(w, "ok")
+		return
+	}
+
+	index := strings.TrimPrefix(r.URL.Path, "/share/")
+	if index == r.URL.Path || r.Method != http.MethodGet {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	share, ok := s.shares[index]
+	if !ok {
+		http.Error(w, "share not available", http.StatusGone)
+		return
+	}
+
+	_, err := w.Write(share)
+	if err != nil {
+		return
+	}
+
+	delete(s.shares, index)
+
+	if len(s.shares) == 0 {
+		close(s.Done)
+	}
+}
+
+// cmdServeShares serves the share files in dir over TLS until each of them has been retrieved once.
+func cmdServeShares(dir, addr, certFile, keyFile string) error {
+	if certFile == "" || keyFile == "" {
+		return errors.New("Serving shares requires -tls-cert and -tls-key.")
+	}
+
+	shares, err := readShareDir(dir)
+	if err != nil {
+		return err
+	}
+
+	handler := newShareServer(shares)
+	srv := &http.Server{Addr: addr, Handler: handler}
+
+	go func() {
+		<-handler.Done
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		srv.Shutdown(ctx)
+	}()
+
+	err = srv.ListenAndServeTLS(certFile, keyFile)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	return err
+}
+
+// cmdHealthCheck probes the health endpoint of a share server. It is used as the container health check, where no
+// other HTTP client is available. The certificate is not verified because the probe only checks liveness and does
+// not transfer any share.
+func cmdHealthCheck(url string) error {
+	client := &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("health check failed: %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestShareServer(t *testing.T) {
+	handler := newShareServer(map[string][]byte{
+		"1": []byte("1,19943338053965968504353533017903769217\n"),
+		"2": []byte("2,161872477868088873785792630750634181303\n"),
+	})
+
+	srv := httptest.NewTLSServer(handler)
+	defer srv.Close()
+
+	client := srv.Client()
+
+	get := func(path string) (int, string) {
+		resp, err := client.Get(srv.URL + path)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		return resp.StatusCode, string(body)
+	}
+
+	status, body := get("/share/1")
+	if status != http.StatusOK || body != "1,19943338053965968504353533017903769217\n" {
+		t.Errorf("unexpected response: %d %q", status, body)
+	}
+
+	status, _ = get("/share/1")
+	if status != http.StatusGone {
+		t.Errorf("want status %d for second GET, have %d", http.StatusGone, status)
+	}
+
+	select {
+	case <-handler.Done:
+		t.Fatal("server done before all shares were retrieved")
+	default:
+	}
+
+	status, _ = get("/share/2")
+	if status != http.StatusOK {
+		t.Errorf("unexpected status %d", status)
+	}
+
+	select {
+	case <-handler.Done:
+	default:
+		t.Error("server not done after all shares were retrieved")
+	}
+
+	err := cmdHealthCheck(srv.URL + "/healthz")
+	if err != nil {
+		t.Errorf("unexpected health check error: %s", err)
+	}
+}
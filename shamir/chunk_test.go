@@ -0,0 +1,41 @@
+package shamir
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestDistribute_largeSecret(t *testing.T) {
+	secret, _ := new(big.Int).SetString("123456789012345678901234567890123456789012345678901234567890123456789012345678", 10)
+
+	shares, err := Distribute(secret, 5, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, subset := range [][]int{{0, 1, 2}, {1, 3, 4}, {0, 2, 3, 4}} {
+		var picked []Share
+		for _, i := range subset {
+			picked = append(picked, shares[i])
+		}
+
+		if have := RecoverSecret(picked); have.Cmp(secret) != 0 {
+			t.Errorf("shares %v: want %s, have %s", subset, secret, have)
+		}
+	}
+}
+
+func TestRecoverSecret_sameIndex(t *testing.T) {
+	secret, _ := new(big.Int).SetString("123456789012345678901234567890123456789012345678901234567890123456789012345678", 10)
+
+	shares, err := Distribute(secret, 5, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, picked := range [][]Share{{shares[0], shares[0], shares[1]}, {shares[0], shares[0]}} {
+		if have := RecoverSecret(picked); have != nil {
+			t.Errorf("want nil, have %s", have)
+		}
+	}
+}
@@ -0,0 +1,594 @@
+// Package shamir implements the secret sharing of the secret command, so that other programs can generate and recover
+// secrets without running it. Shares are Shamir shares over the field of integers modulo Prime, written as
+// "index,value" lines.
+package shamir
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/posener/sharedsecret"
+)
+
+// Share is a single share of a secret.
+type Share = sharedsecret.Share
+
+// MinPool is the minimum number of shares GenerateShares selects from if the pool size is chosen with PoolSize.
+const MinPool = 10000
+
+// Prime is the modulus of the field the shares are computed in (2^127 - 1).
+var Prime = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 127), big.NewInt(1))
+
+var (
+	// ErrNegativeSecret is returned when sharing a negative secret.
+	ErrNegativeSecret = errors.New("Secret must not be negative.")
+
+	// ErrInvalidThreshold is returned when the threshold is smaller than 1 or larger than the number of shares.
+	ErrInvalidThreshold = errors.New("There will not be enough shares to recover the secret.")
+
+	// ErrNoShares is returned by Recover when its input contains no shares.
+	ErrNoShares = errors.New("No shares found.")
+
+	// ErrUnrecoverable is returned by Combine if the secret can not be interpolated from the shares, which happens if
+	// two of them have the same index.
+	ErrUnrecoverable = errors.New("The secret can not be recovered from these shares.")
+)
+
+// ErrInsufficientShares is returned by Recover if fewer valid shares were read than are needed to recover the secret.
+type ErrInsufficientShares struct {
+	Have int
+	Need int // Zero if the input does not note the threshold.
+}
+
+func (e *ErrInsufficientShares) Error() string {
+	if e.Need == 0 {
+		return "No valid shares were read."
+	}
+
+	return fmt.Sprintf("Only %d valid shares were read, at least %d are needed to recover the secret.", e.Have, e.Need)
+}
+
+// PoolSize returns how many shares are generated to select n from. Generating a lot more shares than we need and
+// selecting random n from them makes recovering the number of shares unfeasible.
+func PoolSize(n int) int64 {
+	genSecrets := int64(math.Pow(float64(n), 2))
+	if genSecrets < MinPool {
+		genSecrets = MinPool
+	}
+
+	return genSecrets
+}
+
+// GenerateShares creates a pool of shares for secret and randomly selects n of them, k of which are required to
+// recover the secret. A random secret is generated if secret is nil.
+func GenerateShares(n, k int, secret *big.Int, pool int64) ([]Share, *big.Int, error) {
+	if k < 1 || k > n || int64(n) > pool {
+		return nil, nil, ErrInvalidThreshold
+	}
+
+	shares, secret, err := generatePool(k, secret, pool)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Randomize list of shares, get the first n
+	err = Shuffle(len(shares), func(i, j int) {
+		shares[i], shares[j] = shares[j], shares[i]
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return shares[:n], secret, nil
+}
+
+// GenerateSharesAt is like GenerateShares, but selects the shares with the given indices from the pool instead of
+// random ones. The shares are returned in the order of indices, which must be unique and between 1 and pool.
+func GenerateSharesAt(indices []int64, k int, secret *big.Int, pool int64) ([]Share, *big.Int, error) {
+	if k < 1 || k > len(indices) || int64(len(indices)) > pool {
+		return nil, nil, ErrInvalidThreshold
+	}
+
+	err := CheckIndices(indices, pool)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	shares, secret, err := generatePool(k, secret, pool)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byIndex := make(map[int64]Share, len(shares))
+	for _, share := range shares {
+		x, _ := ShareXY(share)
+		byIndex[x.Int64()] = share
+	}
+
+	selected := make([]Share, len(indices))
+	for i, x := range indices {
+		selected[i] = byIndex[x]
+	}
+
+	return selected, secret, nil
+}
+
+// CheckIndices returns an error if the share indices are not unique or not between 1 and pool.
+func CheckIndices(indices []int64, pool int64) error {
+	seen := make(map[int64]bool, len(indices))
+
+	for _, x := range indices {
+		if x < 1 || x > pool {
+			return fmt.Errorf("Share index %d is not between 1 and %d.", x, pool)
+		}
+
+		if seen[x] {
+			return fmt.Errorf("Share index %d is given more than once.", x)
+		}
+
+		seen[x] = true
+	}
+
+	return nil
+}
+
+// generatePool creates pool shares of secret, k of which are required to recover it. A random secret is generated if
+// secret is nil.
+func generatePool(k int, secret *big.Int, pool int64) ([]Share, *big.Int, error) {
+	if secret == nil {
+		shares, secret := sharedsecret.New(pool, int64(k))
+		return shares, secret, nil
+	}
+
+	shares, err := Distribute(secret, pool, int64(k))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return shares, secret, nil
+}
+
+// Shuffle randomizes the order of n elements with a Fisher-Yates shuffle, calling swap to swap the elements with the
+// indices i and j. The swap indices are drawn from crypto/rand, so the resulting order is unpredictable.
+func Shuffle(n int, swap func(i, j int)) error {
+	for i := n - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return err
+		}
+
+		swap(i, int(j.Int64()))
+	}
+
+	return nil
+}
+
+// Perm returns a random permutation of the integers 0 to n-1, drawn with Shuffle.
+func Perm(n int) ([]int, error) {
+	p := make([]int, n)
+	for i := range p {
+		p[i] = i
+	}
+
+	err := Shuffle(n, func(i, j int) {
+		p[i], p[j] = p[j], p[i]
+	})
+
+	return p, err
+}
+
+// ParseShare parses a share line "index,value".
+func ParseShare(line string) (Share, error) {
+	var s Share
+
+	err := s.UnmarshalText([]byte(line))
+
+	return s, err
+}
+
+// Buffer sizes for reading share lines. The values of shares of large secrets are long numbers, so share lines can be
+// a lot longer than the default limit of bufio.Scanner.
+const (
+	shareLineBufferSize = 1 << 20
+	maxShareLineSize    = 64 << 20
+)
+
+// NewScanner returns a scanner for the lines of in that accepts share lines of up to 64 MiB.
+func NewScanner(in io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, shareLineBufferSize), maxShareLineSize)
+
+	return scanner
+}
+
+// ThresholdHeader is the name of the header line that notes the threshold of the shares for scripts, which is also
+// noted in the "shares" line for humans.
+const ThresholdHeader = "threshold"
+
+// WriteSharesHeader writes the lines that precede the shares in the output of Generate, which note the threshold k.
+func WriteSharesHeader(out io.Writer, k int) {
+	fmt.Fprintf(out, "%s: %d\n", ThresholdHeader, k)
+	fmt.Fprintf(out, "shares (need at least %d of these for recovery):\n", k)
+}
+
+// ParseThreshold returns the threshold noted in a line written by WriteSharesHeader.
+func ParseThreshold(line string) (k int, ok bool) {
+	if v, ok := strings.CutPrefix(line, ThresholdHeader+": "); ok {
+		k, err := strconv.Atoi(v)
+		return k, err == nil && k > 0
+	}
+
+	_, err := fmt.Sscanf(line, "shares (need at least %d of these for recovery):", &k)
+
+	return k, err == nil
+}
+
+// CheckShare returns an error if the index of share is not between 1 and Prime-1. The share at index 0 is the secret
+// itself, and larger indices are the same as smaller ones in the field.
+func CheckShare(share Share) error {
+	x, _ := ShareXY(share)
+	if x.Sign() <= 0 || x.Cmp(Prime) >= 0 {
+		return fmt.Errorf("invalid index %s", x)
+	}
+
+	return nil
+}
+
+// Dedupe returns shares without the shares that were already seen, which happens when a share is pasted twice. Only
+// the first of the identical shares is kept, and the others are reported to diag. Two different shares with the same
+// index are an error, since the secret can not be interpolated from them.
+func Dedupe(shares []Share, diag io.Writer) ([]Share, error) {
+	seen := make(map[string]Share, len(shares))
+
+	var unique []Share
+
+	for _, share := range shares {
+		x, _ := ShareXY(share)
+
+		// Indices are the same if they are the same in the field.
+		key := new(big.Int).Mod(x, Prime).String()
+
+		if first, ok := seen[key]; ok {
+			if first.String() != share.String() {
+				return nil, fmt.Errorf("Shares %s and %s have the same index.", first.String(), share.String())
+			}
+
+			fmt.Fprintf(diag, "reading share %q: duplicate index %s\n", share.String(), x)
+			continue
+		}
+
+		seen[key] = share
+		unique = append(unique, share)
+	}
+
+	return unique, nil
+}
+
+// Combine recovers the secret from shares like RecoverSecret, but returns ErrUnrecoverable instead of nil if the
+// secret can not be interpolated from them.
+func Combine(shares []Share) (*big.Int, error) {
+	if len(shares) == 0 {
+		return nil, ErrNoShares
+	}
+
+	secret := RecoverSecret(shares)
+	if secret == nil {
+		return nil, ErrUnrecoverable
+	}
+
+	return secret, nil
+}
+
+// Generate generates n shares of a random secret, k of which are required to recover it, and writes the secret and the
+// shares to out in the output format of the secret command.
+func Generate(n, k int, out io.Writer) error {
+	if n < 1 || k < 1 {
+		return errors.New("Number of shares must be larger than 1.")
+	}
+
+	shares, secret, err := GenerateShares(n, k, nil, PoolSize(n))
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out, "secret:", secret.Text(62))
+
+	WriteSharesHeader(out, k)
+	for _, share := range shares {
+		fmt.Fprintln(out, share)
+	}
+
+	return nil
+}
+
+// ReadShares reads the share lines of the output of Generate from in. The other lines of the output are skipped, and
+// lines that are not valid shares as well as duplicate shares are reported to diag. The threshold is zero if in does
+// not note it.
+func ReadShares(in io.Reader, diag io.Writer) (shares []Share, threshold int, err error) {
+	scanner := NewScanner(in)
+	for scanner.Scan() {
+		t := strings.TrimSpace(scanner.Text())
+
+		if k, ok := ParseThreshold(t); ok {
+			threshold = k
+			continue
+		}
+
+		if t == "" || strings.HasPrefix(t, "secret: ") || strings.HasPrefix(t, "shares") {
+			continue
+		}
+
+		s, err := ParseShare(t)
+		if err == nil {
+			err = CheckShare(s)
+		}
+
+		if err != nil {
+			fmt.Fprintf(diag, "reading share %q: %s\n", t, err)
+			continue
+		}
+
+		shares = append(shares, s)
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	shares, err = Dedupe(shares, diag)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return shares, threshold, nil
+}
+
+// Recover reads share lines from in with ReadShares, recovers the secret and writes it to out in base 62.
+func Recover(in io.Reader, diag io.Writer, out io.Writer) error {
+	shares, threshold, err := ReadShares(in, diag)
+	if err != nil {
+		return err
+	}
+
+	if len(shares) == 0 {
+		return ErrNoShares
+	}
+
+	if len(shares) < threshold {
+		return &ErrInsufficientShares{Have: len(shares), Need: threshold}
+	}
+
+	secret, err := Combine(shares)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out, secret.Text(62))
+
+	return nil
+}
+
+// chunkBits is the size of the chunks a secret that does not fit into the field is split into.
+const chunkBits = 120
+
+// Distribute creates pool shares of secret, k of which are required to recover it.
+//
+// Secrets that do not fit into the field are split into chunks of chunkBits bits, each of which is shared with its own
+// polynomial. The values of all chunk polynomials at the same index are packed into a single number in base prime, so
+// that every share is still a single "index,value" pair. RecoverSecret detects packed shares by their value being
+// larger than Prime.
+func Distribute(secret *big.Int, pool, k int64) ([]Share, error) {
+	if secret.Sign() < 0 {
+		return nil, ErrNegativeSecret
+	}
+
+	if secret.Cmp(Prime) < 0 {
+		return sharedsecret.Distribute(secret, pool, k), nil
+	}
+
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), chunkBits), big.NewInt(1))
+
+	var chunks []*big.Int
+	for rest := new(big.Int).Set(secret); rest.Sign() > 0; rest.Rsh(rest, chunkBits) {
+		chunks = append(chunks, new(big.Int).And(rest, mask))
+	}
+
+	xs := make([]*big.Int, pool)
+	values := make([]*big.Int, pool)
+
+	// Pack the chunk values with the first chunk in the least significant digit.
+	for j := len(chunks) - 1; j >= 0; j-- {
+		for i, share := range sharedsecret.Distribute(chunks[j], pool, k) {
+			x, y := ShareXY(share)
+
+			if values[i] == nil {
+				xs[i] = x
+				values[i] = new(big.Int)
+			}
+
+			values[i].Mul(values[i], Prime).Add(values[i], y)
+		}
+	}
+
+	shares := make([]Share, pool)
+	for i := range shares {
+		shares[i] = NewShare(xs[i], values[i])
+	}
+
+	return shares, nil
+}
+
+// VerifyRecoveredSecret reports whether secret equals the number with the big endian bytes expected. The comparison
+// takes the same time for all secrets that fit into the field, so that timing it does not reveal how much of a
+// recovered secret is correct. Callers should use it instead of comparing recovered secrets directly.
+func VerifyRecoveredSecret(secret *big.Int, expected []byte) bool {
+	if secret == nil || secret.Sign() < 0 {
+		return false
+	}
+
+	width := max((Prime.BitLen()+7)/8, len(expected), (secret.BitLen()+7)/8)
+
+	want := make([]byte, width)
+	copy(want[width-len(expected):], expected)
+
+	return subtle.ConstantTimeCompare(secret.FillBytes(make([]byte, width)), want) == 1
+}
+
+// chunkCount returns the number of chunks packed into the values of shares.
+func chunkCount(shares []Share) int {
+	chunks := 1
+
+	for _, share := range shares {
+		_, y := ShareXY(share)
+
+		n := 1
+		for v := new(big.Int).Set(y); v.Cmp(Prime) >= 0; v.Quo(v, Prime) {
+			n++
+		}
+
+		if n > chunks {
+			chunks = n
+		}
+	}
+
+	return chunks
+}
+
+// RecoverSecret recovers the secret from shares created by Distribute. It returns nil if the secret can not be
+// interpolated from shares, which happens if two of them have the same index.
+func RecoverSecret(shares []Share) *big.Int {
+	chunks := chunkCount(shares)
+
+	if chunks == 1 {
+		return sharedsecret.Recover(shares...)
+	}
+
+	xs := make([]*big.Int, len(shares))
+	rest := make([]*big.Int, len(shares))
+
+	for i, share := range shares {
+		xs[i], rest[i] = ShareXY(share)
+	}
+
+	secret := new(big.Int)
+
+	for j := 0; j < chunks; j++ {
+		chunkShares := make([]Share, len(shares))
+
+		for i := range shares {
+			y := new(big.Int)
+			rest[i].QuoRem(rest[i], Prime, y)
+
+			chunkShares[i] = NewShare(xs[i], y)
+		}
+
+		chunk := sharedsecret.Recover(chunkShares...)
+		if chunk == nil {
+			return nil
+		}
+
+		secret.Or(secret, chunk.Lsh(chunk, uint(j*chunkBits)))
+	}
+
+	return secret
+}
+
+// Reindex returns shares of the same secret as shares with the given indices. The value of each new share is
+// interpolated from shares at its index, which never computes the secret itself. The new shares are only shares of
+// the secret if shares has at least as many shares as are required to recover it, and the indices of shares must be
+// unique.
+func Reindex(shares []Share, indices []int64) []Share {
+	chunks := chunkCount(shares)
+
+	xs := make([]*big.Int, len(shares))
+	rest := make([]*big.Int, len(shares))
+
+	for i, share := range shares {
+		xs[i], rest[i] = ShareXY(share)
+	}
+
+	// digits[j][i] is the value of the chunk polynomial j at xs[i], with the first chunk in the least significant
+	// digit of the share values.
+	digits := make([][]*big.Int, chunks)
+
+	for j := range digits {
+		digits[j] = make([]*big.Int, len(shares))
+
+		for i := range shares {
+			digits[j][i] = new(big.Int)
+			rest[i].QuoRem(rest[i], Prime, digits[j][i])
+		}
+	}
+
+	reindexed := make([]Share, len(indices))
+
+	for i, index := range indices {
+		x := big.NewInt(index)
+		value := new(big.Int)
+
+		for j := chunks - 1; j >= 0; j-- {
+			value.Mul(value, Prime).Add(value, interpolate(xs, digits[j], x))
+		}
+
+		reindexed[i] = NewShare(x, value)
+	}
+
+	return reindexed
+}
+
+// interpolate returns the value at x of the polynomial through the points (xs[i], ys[i]) in the field.
+func interpolate(xs, ys []*big.Int, x *big.Int) *big.Int {
+	sum := new(big.Int)
+
+	for i := range xs {
+		num := big.NewInt(1)
+		den := big.NewInt(1)
+
+		for j := range xs {
+			if i == j {
+				continue
+			}
+
+			num.Mul(num, new(big.Int).Sub(x, xs[j])).Mod(num, Prime)
+			den.Mul(den, new(big.Int).Sub(xs[i], xs[j])).Mod(den, Prime)
+		}
+
+		term := num.Mul(num, ys[i])
+		term.Mul(term, den.ModInverse(den, Prime))
+		sum.Add(sum, term).Mod(sum, Prime)
+	}
+
+	return sum
+}
+
+// ShareXY returns the index and the value of share.
+func ShareXY(share Share) (x, y *big.Int) {
+	// Shares are always formatted as two decimal numbers separated by a comma.
+	xs, ys, _ := strings.Cut(share.String(), ",")
+
+	x, _ = new(big.Int).SetString(xs, 10)
+	y, _ = new(big.Int).SetString(ys, 10)
+
+	return x, y
+}
+
+// NewShare returns a share with index x and value y.
+func NewShare(x, y *big.Int) Share {
+	var s Share
+
+	err := s.UnmarshalText([]byte(x.String() + "," + y.String()))
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
@@ -0,0 +1,308 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestGenerateRecover(t *testing.T) {
+	var genBuf bytes.Buffer
+
+	err := Generate(5, 3, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(genBuf.String()), "\n")
+	if len(lines) != 8 {
+		t.Fatalf("want 8 lines, have %d: %q", len(lines), lines)
+	}
+
+	if lines[1] != "threshold: 3" {
+		t.Errorf("unexpected threshold line %q", lines[1])
+	}
+
+	if lines[2] != "shares (need at least 3 of these for recovery):" {
+		t.Errorf("unexpected shares line %q", lines[2])
+	}
+
+	secret := strings.TrimPrefix(lines[0], "secret: ")
+
+	for _, tc := range []struct {
+		name  string
+		input string
+	}{
+		{"complete output", genBuf.String()},
+		{"only shares", strings.Join(lines[5:], "\n")},
+		{"with garbage", "garbage\n" + strings.Join(lines[3:6], "\n")},
+		{"with duplicates", strings.Join(lines[3:6], "\n") + "\n" + lines[3]},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var outBuf bytes.Buffer
+
+			err := Recover(strings.NewReader(tc.input), &bytes.Buffer{}, &outBuf)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if outBuf.String() != secret+"\n" {
+				t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
+			}
+		})
+	}
+}
+
+func TestRecover_reportsGarbage(t *testing.T) {
+	var diag bytes.Buffer
+
+	err := Recover(strings.NewReader("garbage\n1,19943338053965968504353533017903769217\n"), &diag, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !strings.Contains(diag.String(), `reading share "garbage"`) {
+		t.Errorf("garbage not reported: %q", diag.String())
+	}
+}
+
+func TestRecover_noShares(t *testing.T) {
+	err := Recover(strings.NewReader("secret: abc\n"), &bytes.Buffer{}, &bytes.Buffer{})
+	if !errors.Is(err, ErrNoShares) {
+		t.Errorf("unexpected error. want %v, have %v", ErrNoShares, err)
+	}
+}
+
+func TestRecover_invalidShares(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		input string
+	}{
+		{"same index", "1,5\n1,6\n"},
+		{"index zero", "0,5\n"},
+		{"index out of range", "170141183460469231731687303715884105728,5\n"},
+		{"too few shares", "threshold: 2\n1,5\n1,5\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var outBuf bytes.Buffer
+
+			err := Recover(strings.NewReader(tc.input), &bytes.Buffer{}, &outBuf)
+			if err == nil {
+				t.Errorf("expected error, got nil")
+			}
+
+			if outBuf.Len() != 0 {
+				t.Errorf("unexpected output %q", outBuf.String())
+			}
+		})
+	}
+}
+
+func TestRecover_duplicateShares(t *testing.T) {
+	var (
+		outBuf bytes.Buffer
+		diag   bytes.Buffer
+	)
+
+	err := Recover(strings.NewReader("1,5\n1,5\n2,7\n"), &diag, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// The shares are on the line 3 + 2x.
+	if outBuf.String() != "3\n" {
+		t.Errorf("unexpected secret %q", outBuf.String())
+	}
+
+	if diag.String() != "reading share \"1,5\": duplicate index 1\n" {
+		t.Errorf("unexpected diagnostic %q", diag.String())
+	}
+}
+
+func TestParseThreshold(t *testing.T) {
+	for _, tc := range []struct {
+		line string
+		k    int
+		ok   bool
+	}{
+		{"threshold: 3", 3, true},
+		{"shares (need at least 4 of these for recovery):", 4, true},
+		{"threshold: 0", 0, false},
+		{"threshold: x", 0, false},
+		{"1,5", 0, false},
+	} {
+		k, ok := ParseThreshold(tc.line)
+		if ok != tc.ok || (ok && k != tc.k) {
+			t.Errorf("%q: want %d, %t, have %d, %t", tc.line, tc.k, tc.ok, k, ok)
+		}
+	}
+}
+
+func TestDedupe_largeIndices(t *testing.T) {
+	shares := []Share{
+		NewShare(big.NewInt(1), big.NewInt(5)),
+		NewShare(new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 64), big.NewInt(1)), big.NewInt(6)),
+	}
+
+	unique, err := Dedupe(shares, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(unique) != 2 {
+		t.Errorf("want 2 shares, have %d", len(unique))
+	}
+}
+
+func TestCombine_sameIndex(t *testing.T) {
+	share := NewShare(big.NewInt(1), big.NewInt(5))
+
+	_, err := Combine([]Share{share, share})
+	if !errors.Is(err, ErrUnrecoverable) {
+		t.Errorf("unexpected error. want %v, have %v", ErrUnrecoverable, err)
+	}
+}
+
+func TestGenerate_invalidParams(t *testing.T) {
+	for _, tc := range [][2]int{{0, 1}, {3, 0}, {2, 3}} {
+		err := Generate(tc[0], tc[1], &bytes.Buffer{})
+		if err == nil {
+			t.Errorf("expected error for n=%d, k=%d, got nil", tc[0], tc[1])
+		}
+	}
+}
+
+func TestPoolSize(t *testing.T) {
+	for _, tc := range []struct {
+		n    int
+		want int64
+	}{
+		{1, MinPool},
+		{100, MinPool},
+		{101, 10201},
+	} {
+		if have := PoolSize(tc.n); have != tc.want {
+			t.Errorf("unexpected pool size for %d shares. want %d, have %d", tc.n, tc.want, have)
+		}
+	}
+}
+
+func TestGenerateShares_order(t *testing.T) {
+	indices := func() string {
+		shares, _, err := GenerateShares(10, 3, big.NewInt(42), 10)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		var order []string
+		for _, share := range shares {
+			x, _ := ShareXY(share)
+			order = append(order, x.String())
+		}
+
+		return strings.Join(order, " ")
+	}
+
+	first, second := indices(), indices()
+	if first == second {
+		t.Errorf("back to back calls selected the shares in the same order %s", first)
+	}
+}
+
+func TestGenerateSharesAt(t *testing.T) {
+	secret := new(big.Int).Lsh(big.NewInt(1), 300)
+
+	shares, _, err := GenerateSharesAt([]int64{7, 1, 42}, 2, secret, 100)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for i, want := range []int64{7, 1, 42} {
+		x, _ := ShareXY(shares[i])
+		if x.Int64() != want {
+			t.Errorf("unexpected index of share %d. want %d, have %s", i, want, x)
+		}
+	}
+
+	if have := RecoverSecret(shares[1:]); have.Cmp(secret) != 0 {
+		t.Errorf("unexpected secret. want %s, have %s", secret, have)
+	}
+
+	for _, indices := range [][]int64{{1, 1}, {0, 1}, {1, 101}, {1}} {
+		_, _, err := GenerateSharesAt(indices, 2, nil, 100)
+		if err == nil {
+			t.Errorf("expected error for indices %v, got nil", indices)
+		}
+	}
+}
+
+func TestReindex(t *testing.T) {
+	for _, secret := range []*big.Int{big.NewInt(42), new(big.Int).Lsh(big.NewInt(1), 300)} {
+		shares, _, err := GenerateSharesAt([]int64{50, 60, 70, 80, 90}, 3, secret, 100)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		reindexed := Reindex(shares[1:], []int64{1, 2, 3, 4})
+
+		for i, share := range reindexed {
+			x, _ := ShareXY(share)
+			if x.Int64() != int64(i+1) {
+				t.Errorf("unexpected index of share %d: %s", i, x)
+			}
+		}
+
+		if have := RecoverSecret(reindexed[1:]); have.Cmp(secret) != 0 {
+			t.Errorf("unexpected secret. want %s, have %s", secret, have)
+		}
+
+		if have := RecoverSecret([]Share{reindexed[3], shares[0], reindexed[0]}); have.Cmp(secret) != 0 {
+			t.Errorf("reindexed shares do not mix with the original ones. want %s, have %s", secret, have)
+		}
+	}
+}
+
+func TestPerm(t *testing.T) {
+	p, err := Perm(100)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	seen := make(map[int]bool)
+	for _, i := range p {
+		if i < 0 || i >= 100 || seen[i] {
+			t.Fatalf("not a permutation of 0..99: %v", p)
+		}
+
+		seen[i] = true
+	}
+}
+
+func TestVerifyRecoveredSecret(t *testing.T) {
+	secret, _ := new(big.Int).SetString("19943338053965968504353533017903769217", 10)
+	long := new(big.Int).Lsh(secret, 200)
+
+	for _, tc := range []struct {
+		name     string
+		secret   *big.Int
+		expected []byte
+		want     bool
+	}{
+		{"equal", secret, secret.Bytes(), true},
+		{"leading zeros", secret, append([]byte{0, 0}, secret.Bytes()...), true},
+		{"different", secret, new(big.Int).Add(secret, big.NewInt(1)).Bytes(), false},
+		{"zero", new(big.Int), nil, true},
+		{"longer than the field", long, long.Bytes(), true},
+		{"longer than expected", long, secret.Bytes(), false},
+		{"negative", new(big.Int).Neg(secret), secret.Bytes(), false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			have := VerifyRecoveredSecret(tc.secret, tc.expected)
+			if have != tc.want {
+				t.Errorf("want %t, have %t", tc.want, have)
+			}
+		})
+	}
+}
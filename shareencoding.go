@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/farhaven/secret/base58"
+	"github.com/farhaven/secret/shamir"
+	"github.com/posener/sharedsecret"
+)
+
+// Encodings of the share values. Decimal shares are written as "index,value", base58 shares as "index_value",
+// base64 shares as "index,value" with the big endian bytes of the value in base64, hex shares as "index,value" with
+// the value in hexadecimal and word shares as an index word followed by BIP-39 words.
+const (
+	decimalEncoding = "decimal"
+	base58Encoding  = "base58"
+	base64Encoding  = "base64"
+	hexEncoding     = "hex"
+	wordsEncoding   = "words"
+)
+
+// validateShareEncoding checks that encoding names a known encoding of share values. The empty encoding is decimal.
+func validateShareEncoding(encoding string) error {
+	switch encoding {
+	case "", decimalEncoding, base58Encoding, base64Encoding, hexEncoding, wordsEncoding:
+		return nil
+	default:
+		return fmt.Errorf("Unknown share encoding %q.", encoding)
+	}
+}
+
+// shareValueBytes returns the big endian bytes of the value of share. Zero is a single zero byte, so that the encoded
+// value is never empty.
+func shareValueBytes(y *big.Int) []byte {
+	if y.Sign() == 0 {
+		return []byte{0}
+	}
+
+	return y.Bytes()
+}
+
+// formatShare returns the text of share with its value in encoding. The value is left-padded with zeros to padding
+// characters, or padding words for word shares: "0" digits for decimal and hex values, "1" digits for base58 values,
+// zero bytes for base64 values and "abandon" for word values. Values that are already as wide are not padded.
+func formatShare(share sharedsecret.Share, encoding string, padding int) string {
+	x, y := shamir.ShareXY(share)
+
+	switch encoding {
+	case base58Encoding:
+		return x.String() + "_" + padLeft(base58.Encode(shareValueBytes(y)), "1", padding)
+	case base64Encoding:
+		raw := shareValueBytes(y)
+		if n := padding / 4 * 3; len(raw) < n {
+			raw = append(make([]byte, n-len(raw)), raw...)
+		}
+
+		return x.String() + "," + base64.StdEncoding.EncodeToString(raw)
+	case hexEncoding:
+		return x.String() + "," + padLeft(y.Text(16), "0", padding)
+	case wordsEncoding:
+		return formatWordsShare(share, padding)
+	default:
+		return x.String() + "," + padLeft(y.String(), "0", padding)
+	}
+}
+
+// padLeft prepends pad to s until it is width long.
+func padLeft(s, pad string, width int) string {
+	if len(s) >= width {
+		return s
+	}
+
+	return strings.Repeat(pad, width-len(s)) + s
+}
+
+// validatePadding checks that padding can be used for share values in encoding.
+func validatePadding(padding int, encoding string) error {
+	switch {
+	case padding < 0:
+		return errors.New("Padding must not be negative.")
+	case encoding == base64Encoding && padding%4 != 0:
+		return errors.New("Padding of base64 share values must be a multiple of 4.")
+	default:
+		return nil
+	}
+}
+
+// checkSharePadding returns an error if the value of share in encoding is wider than padding, so that padding does not
+// make all values equally wide.
+func checkSharePadding(share sharedsecret.Share, encoding string, padding int) error {
+	x, y := shamir.ShareXY(share)
+
+	var width int
+
+	switch encoding {
+	case base58Encoding:
+		width = len(base58.Encode(shareValueBytes(y)))
+	case base64Encoding:
+		width = base64.StdEncoding.EncodedLen(len(shareValueBytes(y)))
+	case hexEncoding:
+		width = len(y.Text(16))
+	case wordsEncoding:
+		width = len(strings.Fields(encodeWordsValue(y)))
+	default:
+		width = len(y.String())
+	}
+
+	if width > padding {
+		return fmt.Errorf("The value of share %s is %d wide, which is more than the padding of %d.", x, width, padding)
+	}
+
+	return nil
+}
+
+// parseBase58Share parses the index and the base58 value of a share written as "index_value".
+func parseBase58Share(index, value string) (sharedsecret.Share, error) {
+	x, ok := new(big.Int).SetString(index, 10)
+	if !ok || x.Sign() <= 0 {
+		return sharedsecret.Share{}, errors.New("invalid index")
+	}
+
+	raw, err := base58.Decode(value)
+	if err != nil || len(raw) == 0 || base58.Encode(raw) != value {
+		return sharedsecret.Share{}, errors.New("invalid base58 value")
+	}
+
+	return shamir.NewShare(x, new(big.Int).SetBytes(raw)), nil
+}
+
+// parseBase64Share parses the index and the base64 value of a share written as "index,value". ok is false if the
+// value is not exactly the base64 encoding of some bytes.
+func parseBase64Share(index, value string) (share sharedsecret.Share, ok bool) {
+	x, ok := new(big.Int).SetString(index, 10)
+	if !ok || x.Sign() <= 0 {
+		return sharedsecret.Share{}, false
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(raw) == 0 || base64.StdEncoding.EncodeToString(raw) != value {
+		return sharedsecret.Share{}, false
+	}
+
+	return shamir.NewShare(x, new(big.Int).SetBytes(raw)), true
+}
+
+// isHexValue reports whether s is a non-empty string of hexadecimal digits.
+func isHexValue(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, r := range s {
+		if !('0' <= r && r <= '9' || 'a' <= r && r <= 'f' || 'A' <= r && r <= 'F') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseHexShare parses the index and the hexadecimal value of a share written as "index,value".
+func parseHexShare(index, value string) (sharedsecret.Share, error) {
+	x, ok := new(big.Int).SetString(index, 10)
+	if !ok || x.Sign() <= 0 {
+		return sharedsecret.Share{}, errors.New("invalid index")
+	}
+
+	y, ok := new(big.Int).SetString(value, 16)
+	if !ok {
+		return sharedsecret.Share{}, errors.New("invalid hex value")
+	}
+
+	return shamir.NewShare(x, y), nil
+}
+
+// hexShare returns the share with the decimal digits of the value of share read as hexadecimal digits. Hex values
+// without any of the digits a to f look like decimal values, so they are read as decimal values at first.
+func hexShare(share sharedsecret.Share) sharedsecret.Share {
+	x, y := shamir.ShareXY(share)
+
+	v, _ := new(big.Int).SetString(y.String(), 16)
+
+	return shamir.NewShare(x, v)
+}
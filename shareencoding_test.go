@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/farhaven/secret/shamir"
+)
+
+func TestShareEncoding_roundTrip(t *testing.T) {
+	for _, encoding := range []string{decimalEncoding, base58Encoding, base64Encoding, hexEncoding, wordsEncoding} {
+		t.Run(encoding, func(t *testing.T) {
+			var genBuf bytes.Buffer
+
+			err := cmdGenerate(5, 3, generateOptions{encoding: encoding, labels: []string{"a", "b", "c", "d", "e"}, hmacKey: []byte("key")}, &genBuf)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			lines := strings.Split(strings.TrimSpace(genBuf.String()), "\n")
+			secret := strings.TrimPrefix(lines[0], "secret: ")
+			shares := lines[3:]
+
+			for _, share := range shares {
+				s, err := parseLabeledShare(share)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				if s.encoding != encoding && !(encoding == decimalEncoding && s.encoding == "") {
+					t.Errorf("share %q detected as %q", share, s.encoding)
+				}
+
+				if s.String() != share {
+					t.Errorf("share %q formatted as %q", share, s.String())
+				}
+			}
+
+			var outBuf bytes.Buffer
+
+			err = cmdRecover(strings.NewReader(strings.Join(shares[2:], "\n")), recoverOptions{hmacKey: []byte("key")}, &bytes.Buffer{}, &outBuf)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if outBuf.String() != secret+"\n" {
+				t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
+			}
+		})
+	}
+}
+
+func TestShareEncoding_parse(t *testing.T) {
+	for _, tc := range []struct {
+		line     string
+		want     string
+		encoding string
+		wantErr  bool
+	}{
+		{"1,19943338053965968504353533017903769217", "1,19943338053965968504353533017903769217", "", false},
+		{"3_2", "3,1", base58Encoding, false},
+		{"3,AQ==", "3,1", base64Encoding, false},
+		// Digits are preferred as decimal.
+		{"3,1234", "3,1234", "", false},
+		{"3_0", "", "", true},
+		{"x_2", "", "", true},
+		{"3,AQ", "", "", true},
+		{"3,ff", "3,255", hexEncoding, false},
+		// Hex values are not read with a base prefix.
+		{"3,0b11", "3,2833", hexEncoding, false},
+	} {
+		t.Run(tc.line, func(t *testing.T) {
+			s, err := parseLabeledShare(tc.line)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if s.share.String() != tc.want || s.encoding != tc.encoding {
+				t.Errorf("want %q in %q, have %q in %q", tc.want, tc.encoding, s.share.String(), s.encoding)
+			}
+		})
+	}
+}
+
+func TestShareEncoding_hexInput(t *testing.T) {
+	// The shares of 0x10 for the polynomial 0x10 + x. The hex values of the first two shares have no digits a to f.
+	for _, tc := range []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"hex", "1,11\n10,1a\n", "g\n", false},
+		{"three hex shares", "1,11\n2,12\n10,1a\n", "g\n", false},
+		{"decimal", "1,11\n2,12\n", "a\n", false},
+		{"mixed", "1,11\n10,1a\n3_3\n", "", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var outBuf bytes.Buffer
+
+			err := cmdRecover(strings.NewReader(tc.input), recoverOptions{}, &bytes.Buffer{}, &outBuf)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if outBuf.String() != tc.want {
+				t.Errorf("unexpected recovered secret. want %q, have %q", tc.want, outBuf.String())
+			}
+		})
+	}
+}
+
+func TestShareEncoding_hexTags(t *testing.T) {
+	key := []byte("key")
+
+	var lines []string
+
+	// The tag of the first share is over its hex value, even though the value looks like a decimal value.
+	for _, x := range []int64{1, 10} {
+		share := shamir.NewShare(big.NewInt(x), big.NewInt(0x10+x))
+		lines = append(lines, labeledShare{share: share, encoding: hexEncoding, tag: shareTag(key, share)}.String())
+	}
+
+	var outBuf bytes.Buffer
+
+	err := cmdRecover(strings.NewReader(strings.Join(lines, "\n")), recoverOptions{hmacKey: key}, &bytes.Buffer{}, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if outBuf.String() != "g\n" {
+		t.Errorf("unexpected recovered secret: %q", outBuf.String())
+	}
+}
+
+func TestShareEncoding_errors(t *testing.T) {
+	for _, opts := range []generateOptions{
+		{encoding: "base32"},
+		{encoding: base58Encoding, format: "ber-tlv"},
+	} {
+		err := cmdGenerate(3, 2, opts, &bytes.Buffer{})
+		if err == nil {
+			t.Errorf("expected error for %+v, got nil", opts)
+		}
+	}
+}
+
+func TestShareEncoding_padding(t *testing.T) {
+	for _, encoding := range []string{decimalEncoding, base58Encoding, base64Encoding, hexEncoding, wordsEncoding} {
+		t.Run(encoding, func(t *testing.T) {
+			var genBuf bytes.Buffer
+
+			err := cmdGenerate(5, 3, generateOptions{encoding: encoding, padding: 64, hmacKey: []byte("key")}, &genBuf)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			lines := strings.Split(strings.TrimSpace(genBuf.String()), "\n")
+			secret := strings.TrimPrefix(lines[0], "secret: ")
+			shares := lines[3:]
+
+			for _, share := range shares {
+				value := strings.Split(share, ",")[1]
+
+				var width int
+
+				switch encoding {
+				case base58Encoding:
+					_, value, _ = strings.Cut(strings.Split(share, ",")[0], "_")
+					width = len(value)
+				case wordsEncoding:
+					width = len(strings.Fields(strings.Split(share, ",")[0])) - 1
+				default:
+					width = len(value)
+				}
+
+				if width != 64 {
+					t.Errorf("share %q is %d wide", share, width)
+				}
+			}
+
+			var outBuf bytes.Buffer
+
+			err = cmdRecover(strings.NewReader(strings.Join(shares[1:4], "\n")), recoverOptions{hmacKey: []byte("key")}, &bytes.Buffer{}, &outBuf)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if outBuf.String() != secret+"\n" {
+				t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
+			}
+		})
+	}
+}
+
+func TestShareEncoding_paddingErrors(t *testing.T) {
+	for _, opts := range []generateOptions{
+		{padding: -1},
+		{padding: 10},
+		{padding: 62, encoding: base64Encoding},
+		{padding: 64, format: "ber-tlv"},
+	} {
+		var buf bytes.Buffer
+
+		err := cmdGenerate(3, 2, opts, &buf)
+		if err == nil {
+			t.Errorf("expected error for %+v, got nil", opts)
+		}
+
+		if buf.Len() != 0 {
+			t.Errorf("unexpected output: %q", buf.String())
+		}
+	}
+}
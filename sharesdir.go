@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// shareDirSource reads shares from the *.txt files in a directory, like the share files written by -outdir. Every
+// line of a file is read as a share, the files are read in the order of their names.
+type shareDirSource struct {
+	dir string
+}
+
+func (s shareDirSource) LoadShares() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		lines []string
+		files int
+	)
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".txt" {
+			continue
+		}
+
+		buf, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		files++
+		lines = append(lines, strings.Split(strings.TrimSpace(string(buf)), "\n")...)
+	}
+
+	if files == 0 {
+		return nil, fmt.Errorf("%s: no share files found", s.dir)
+	}
+
+	return lines, nil
+}
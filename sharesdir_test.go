@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestShareDirSource(t *testing.T) {
+	var genBuf bytes.Buffer
+
+	err := cmdGenerate(5, 3, generateOptions{noOversample: true}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(genBuf.String()), "\n")
+	secret := strings.TrimPrefix(lines[0], "secret: ")
+
+	dir := t.TempDir()
+	writeShareFiles(t, dir, lines[3:6]...)
+
+	for name, content := range map[string]string{"notes.txt": "not a share\n", "share-9.bak": "9,1\n"} {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	in, err := readSource(shareDirSource{dir})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var (
+		errBuf bytes.Buffer
+		outBuf bytes.Buffer
+	)
+
+	err = cmdRecover(in, recoverOptions{}, &errBuf, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if outBuf.String() != secret+"\n" {
+		t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
+	}
+
+	if want := "reading share \"not a share\": expected two parts\n"; errBuf.String() != want {
+		t.Errorf("unexpected diagnostic. want %q, have %q", want, errBuf.String())
+	}
+}
+
+func TestShareDirSource_sorted(t *testing.T) {
+	dir := t.TempDir()
+	writeShareFiles(t, dir, "3,3", "1,1", "2,2")
+
+	have, err := shareDirSource{dir}.LoadShares()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if want := []string{"1,1", "2,2", "3,3"}; strings.Join(have, " ") != strings.Join(want, " ") {
+		t.Errorf("want shares %q, have %q", want, have)
+	}
+}
+
+func TestShareDirSource_empty(t *testing.T) {
+	_, err := shareDirSource{t.TempDir()}.LoadShares()
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
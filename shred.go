@@ -0,0 +1,67 @@
+package main
+
+import (
+	"crypto/rand"
+	"io"
+	"os"
+	"time"
+)
+
+// shredPasses is how often a file is overwritten with random data before it is deleted.
+const shredPasses = 3
+
+// overwriteFile overwrites the contents of the named file with random data. The file is opened without truncating it,
+// so the data is written over the existing blocks instead of newly allocated ones.
+func overwriteFile(name string, passes int) error {
+	info, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+
+	fh, err := os.OpenFile(name, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	for i := 0; i < passes; i++ {
+		_, err := fh.Seek(0, io.SeekStart)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.CopyN(fh, rand.Reader, info.Size())
+		if err != nil {
+			return err
+		}
+
+		err = fh.Sync()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// shredFile overwrites the named file with random data and deletes it.
+func shredFile(name string) error {
+	err := overwriteFile(name, shredPasses)
+	if err != nil {
+		return err
+	}
+
+	return os.Remove(name)
+}
+
+// shredAfter shreds the named file in the background once d has passed. The result is sent on the returned channel.
+func shredAfter(name string, d time.Duration) <-chan error {
+	result := make(chan error, 1)
+
+	go func() {
+		time.Sleep(d)
+		result <- shredFile(name)
+	}()
+
+	return result
+}
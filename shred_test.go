@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestShredAfter(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "secret.txt")
+	content := []byte("secret: 7uPIBqGKMPpProBYFFR3S\n")
+
+	err := os.WriteFile(name, content, 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Keep a second link to the file to inspect its contents after it has been deleted.
+	link := filepath.Join(dir, "link")
+
+	err = os.Link(name, link)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	start := time.Now()
+
+	err = <-shredAfter(name, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if time.Since(start) < 50*time.Millisecond {
+		t.Errorf("file shredded too early")
+	}
+
+	if _, err := os.Stat(name); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected file to be deleted, got %v", err)
+	}
+
+	shredded, err := os.ReadFile(link)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(shredded) != len(content) {
+		t.Errorf("unexpected size after shredding: want %d, have %d", len(content), len(shredded))
+	}
+
+	if bytes.Equal(shredded, content) {
+		t.Error("file content has not changed")
+	}
+}
+
+func TestShredFile_missing(t *testing.T) {
+	err := shredFile(filepath.Join(t.TempDir(), "secret.txt"))
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestWaitAndShred_onExit(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "secret.txt")
+
+	err := os.WriteFile(name, []byte("secret: 7uPIBqGKMPpProBYFFR3S\n"), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Without a timer, the file is shredded right away instead of waiting for a signal.
+	err = waitAndShred(name, false, 0, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, err = os.Stat(name)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("file not deleted: %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// signaturePrefix separates a share from its Ed25519 signature on a share line: "index,value sig=<base64>".
+const signaturePrefix = " sig="
+
+// loadSigningKey reads a PEM encoded PKCS #8 Ed25519 private key, as written by
+// "openssl genpkey -algorithm ed25519".
+func loadSigningKey(name string) (ed25519.PrivateKey, error) {
+	buf, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(buf)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", name)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: not an Ed25519 key", name)
+	}
+
+	return edKey, nil
+}
+
+// signShare returns the share line for share signed with key.
+func signShare(key ed25519.PrivateKey, share string) string {
+	sig := ed25519.Sign(key, []byte(share))
+
+	return share + signaturePrefix + base64.StdEncoding.EncodeToString(sig)
+}
+
+// splitSignature splits a share line into the share and its signature. The signature is empty for unsigned shares.
+func splitSignature(line string) (share, sig string) {
+	i := strings.Index(line, signaturePrefix)
+	if i < 0 {
+		return line, ""
+	}
+
+	return line[:i], line[i+len(signaturePrefix):]
+}
+
+// verifyShare checks that line is a share signed by key and returns the bare share.
+func verifyShare(key ed25519.PublicKey, line string) (string, error) {
+	share, sig := splitSignature(line)
+	if sig == "" {
+		return "", errors.New("share is not signed")
+	}
+
+	rawSig, err := base64.StdEncoding.DecodeString(sig)
+	if err != nil {
+		return "", fmt.Errorf("decoding signature: %w", err)
+	}
+
+	if !ed25519.Verify(key, []byte(share), rawSig) {
+		return "", errors.New("invalid signature")
+	}
+
+	return share, nil
+}
+
+// stripSignature returns the share of a share line without its signature. If key is set, the share must carry a valid
+// signature by key.
+func stripSignature(key ed25519.PublicKey, line string) (string, error) {
+	if key == nil {
+		share, _ := splitSignature(line)
+		return share, nil
+	}
+
+	return verifyShare(key, line)
+}
+
+// cmdResign reads a share set signed with oldKey and writes it to out with every share signed with newKey instead.
+// The share values and labels are not changed. Header lines are copied as they are. Nothing is written if any share
+// does not carry a valid signature by oldKey.
+func cmdResign(in io.Reader, oldKey, newKey ed25519.PrivateKey, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+
+	oldPub := oldKey.Public().(ed25519.PublicKey)
+
+	var lines []string
+
+	for scanner.Scan() {
+		t := strings.TrimSpace(scanner.Text())
+
+		if t == "" || strings.HasPrefix(t, "secret: ") || strings.HasPrefix(t, "shares") {
+			lines = append(lines, t)
+			continue
+		}
+
+		if _, _, ok := parseHeader(t); ok {
+			lines = append(lines, t)
+			continue
+		}
+
+		label, line := splitShareLabel(t)
+
+		share, err := verifyShare(oldPub, line)
+		if err != nil {
+			return fmt.Errorf("verifying share %q: %w", t, err)
+		}
+
+		line = signShare(newKey, share)
+		if label != "" {
+			line = label + ": " + line
+		}
+
+		lines = append(lines, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	for _, line := range lines {
+		fmt.Fprintln(out, line)
+	}
+
+	return nil
+}
+
+// loadVerifyKey reads a PEM encoded PKIX Ed25519 public key.
+func loadVerifyKey(name string) (ed25519.PublicKey, error) {
+	buf, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(buf)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", name)
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	edKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: not an Ed25519 key", name)
+	}
+
+	return edKey, nil
+}
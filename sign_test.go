@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newSigningKey(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	return key
+}
+
+func TestLoadSigningKey(t *testing.T) {
+	key := newSigningKey(t)
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	name := filepath.Join(t.TempDir(), "key.pem")
+
+	err = os.WriteFile(name, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	loaded, err := loadSigningKey(name)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !key.Equal(loaded) {
+		t.Errorf("loaded key does not match")
+	}
+}
+
+func TestResign(t *testing.T) {
+	oldKey := newSigningKey(t)
+	newKey := newSigningKey(t)
+
+	var genBuf bytes.Buffer
+
+	err := cmdGenerate(5, 3, generateOptions{signingKey: oldKey}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var resignBuf bytes.Buffer
+
+	err = cmdResign(strings.NewReader(genBuf.String()), oldKey, newKey, &resignBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	oldLines := strings.Split(strings.TrimSpace(genBuf.String()), "\n")
+	newLines := strings.Split(strings.TrimSpace(resignBuf.String()), "\n")
+
+	if len(oldLines) != len(newLines) {
+		t.Fatalf("want %d lines, have %d: %q", len(oldLines), len(newLines), resignBuf.String())
+	}
+
+	newPub := newKey.Public().(ed25519.PublicKey)
+
+	for i, line := range newLines[3:] {
+		share, err := verifyShare(newPub, line)
+		if err != nil {
+			t.Errorf("share %q: %s", line, err)
+		}
+
+		oldShare, _ := splitSignature(oldLines[i+3])
+		if share != oldShare {
+			t.Errorf("share value changed: want %q, have %q", oldShare, share)
+		}
+	}
+
+	var (
+		errBuf bytes.Buffer
+		outBuf bytes.Buffer
+	)
+
+	err = cmdRecover(&resignBuf, recoverOptions{}, &errBuf, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if errBuf.Len() != 0 {
+		t.Errorf("unexpected diagnostic: %q", errBuf.String())
+	}
+
+	secret := strings.TrimPrefix(oldLines[0], "secret: ")
+	if outBuf.String() != secret+"\n" {
+		t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
+	}
+}
+
+func TestResign_invalidSignature(t *testing.T) {
+	oldKey := newSigningKey(t)
+	newKey := newSigningKey(t)
+
+	lines := []string{
+		signShare(oldKey, "1,19943338053965968504353533017903769217"),
+		signShare(newSigningKey(t), "2,161872477868088873785792630750634181303"),
+		signShare(oldKey, "5,160274174127002500413544256698187925606"),
+	}
+
+	var out bytes.Buffer
+
+	err := cmdResign(strings.NewReader(strings.Join(lines, "\n")), oldKey, newKey, &out)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	if !strings.Contains(err.Error(), "invalid signature") {
+		t.Errorf("expected error to contain %q, have %s", "invalid signature", err)
+	}
+
+	if out.Len() != 0 {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+}
+
+func TestResign_headersAndLabels(t *testing.T) {
+	oldKey := newSigningKey(t)
+	newKey := newSigningKey(t)
+
+	opts := generateOptions{
+		signingKey: oldKey,
+		header:     []string{saltHeader + ": x"},
+		backups:    2,
+		ceremonyID: "2026-10",
+	}
+
+	var genBuf bytes.Buffer
+
+	err := cmdGenerate(5, 3, opts, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var resignBuf bytes.Buffer
+
+	err = cmdResign(strings.NewReader(genBuf.String()), oldKey, newKey, &resignBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !strings.Contains(resignBuf.String(), "\nsalt: x\n") {
+		t.Errorf("header not copied: %q", resignBuf.String())
+	}
+
+	newPub := newKey.Public().(ed25519.PublicKey)
+
+	var labels []string
+
+	for _, line := range strings.Split(strings.TrimSpace(resignBuf.String()), "\n")[4:] {
+		label, share := splitShareLabel(line)
+		labels = append(labels, label)
+
+		_, err := verifyShare(newPub, share)
+		if err != nil {
+			t.Errorf("share %q: %s", line, err)
+		}
+	}
+
+	want := "PRIMARY-1 PRIMARY-2 PRIMARY-3 BACKUP-1 BACKUP-2"
+	if strings.Join(labels, " ") != want {
+		t.Errorf("unexpected labels. want %q, have %q", want, strings.Join(labels, " "))
+	}
+}
+
+func TestRecover_verifyKey(t *testing.T) {
+	key := newSigningKey(t)
+	pub := key.Public().(ed25519.PublicKey)
+
+	lines := []string{
+		signShare(key, "1,19943338053965968504353533017903769217"),
+		signShare(newSigningKey(t), "3,90786344267911571088064697511688256624"),
+		"4,1",
+		signShare(key, "2,161872477868088873785792630750634181303"),
+		signShare(key, "5,160274174127002500413544256698187925606"),
+	}
+
+	var (
+		errBuf bytes.Buffer
+		outBuf bytes.Buffer
+	)
+
+	err := cmdRecover(strings.NewReader(strings.Join(lines, "\n")), recoverOptions{verifyKey: pub}, &errBuf, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	wantSecret := "7uPIBqGKMPpProBYFFR3S\n"
+	if outBuf.String() != wantSecret {
+		t.Errorf("unexpected secret. want %q, have %q", wantSecret, outBuf.String())
+	}
+
+	diag := errBuf.String()
+	if strings.Count(diag, "\n") != 2 || !strings.Contains(diag, "invalid signature") || !strings.Contains(diag, "share is not signed") {
+		t.Errorf("unexpected diagnostic: %q", diag)
+	}
+}
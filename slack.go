@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// slackStore posts each share as a message to its own Slack channel or DM and reads them back by message timestamp.
+type slackStore struct {
+	api      string            // Base URL of the Slack Web API.
+	token    string            // Bot token with chat:write and channels:history.
+	channels map[string]string // Channel ID by share position, starting at "1".
+
+	// codeBlock wraps the messages in a mrkdwn code block, which keeps Slack from formatting or unfurling the share.
+	codeBlock bool
+
+	// timestamps of the messages to read, either "<channel>:<ts>" or a bare ts of the channel at the same position.
+	timestamps []string
+
+	diag   io.Writer
+	client *http.Client
+}
+
+// parseChannelMap parses a JSON channel map like {"1": "C12345", "2": "D67890"}.
+func parseChannelMap(m string) (map[string]string, error) {
+	var channels map[string]string
+
+	err := json.Unmarshal([]byte(m), &channels)
+	if err != nil {
+		return nil, fmt.Errorf("parsing channel map: %w", err)
+	}
+
+	return channels, nil
+}
+
+func newSlackStore(token string, channels map[string]string, diag io.Writer) *slackStore {
+	return &slackStore{api: "https://slack.com/api", token: token, channels: channels, diag: diag, client: http.DefaultClient}
+}
+
+// call calls the Slack API method and decodes the response into v. It returns an error if Slack does not report
+// success.
+func (s *slackStore) call(req *http.Request, v interface{}) error {
+	req.Header.Set("Authorization", "Bearer "+s.token)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.New(resp.Status)
+	}
+
+	buf, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var status struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+
+	err = json.Unmarshal(buf, &status)
+	if err != nil {
+		return err
+	}
+
+	if !status.OK {
+		return errors.New(status.Error)
+	}
+
+	return json.Unmarshal(buf, v)
+}
+
+func (s *slackStore) StoreShares(shares []storedShare) error {
+	for i, share := range shares {
+		pos := strconv.Itoa(i + 1)
+
+		channel, ok := s.channels[pos]
+		if !ok {
+			return fmt.Errorf("No Slack channel for share %s.", pos)
+		}
+
+		text := share.line
+		if s.codeBlock {
+			text = "```" + text + "```"
+		}
+
+		body, err := json.Marshal(map[string]string{"channel": channel, "text": text})
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequest(http.MethodPost, s.api+"/chat.postMessage", bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+		var msg struct {
+			Channel string `json:"channel"`
+			TS      string `json:"ts"`
+		}
+
+		err = s.call(req, &msg)
+		if err != nil {
+			return fmt.Errorf("posting share to Slack channel %s: %w", channel, err)
+		}
+
+		fmt.Fprintf(s.diag, "share %s posted to %s ts=%s\n", pos, msg.Channel, msg.TS)
+	}
+
+	return nil
+}
+
+func (s *slackStore) LoadShares() ([]string, error) {
+	lines := make([]string, 0, len(s.timestamps))
+
+	for i, ts := range s.timestamps {
+		channel, ts, ok := strings.Cut(ts, ":")
+		if !ok {
+			ts = channel
+			channel, ok = s.channels[strconv.Itoa(i+1)]
+
+			if !ok {
+				return nil, fmt.Errorf("No Slack channel for message %s.", ts)
+			}
+		}
+
+		query := url.Values{"channel": {channel}, "latest": {ts}, "inclusive": {"true"}, "limit": {"1"}}
+
+		req, err := http.NewRequest(http.MethodGet, s.api+"/conversations.history?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var history struct {
+			Messages []struct {
+				TS   string `json:"ts"`
+				Text string `json:"text"`
+			} `json:"messages"`
+		}
+
+		err = s.call(req, &history)
+		if err != nil {
+			return nil, fmt.Errorf("reading share %s from Slack channel %s: %w", ts, channel, err)
+		}
+
+		if len(history.Messages) == 0 || history.Messages[0].TS != ts {
+			return nil, fmt.Errorf("reading share %s from Slack channel %s: no such message", ts, channel)
+		}
+
+		lines = append(lines, strings.Trim(history.Messages[0].Text, "` \n"))
+	}
+
+	return lines, nil
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type slackMessage struct {
+	Channel string `json:"channel"`
+	TS      string `json:"ts"`
+	Text    string `json:"text"`
+}
+
+// fakeSlack implements chat.postMessage and conversations.history of the Slack Web API.
+type fakeSlack struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	messages []slackMessage
+}
+
+func newFakeSlack() *fakeSlack {
+	f := &fakeSlack{}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+
+	return f
+}
+
+func (f *fakeSlack) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer xoxb-test" {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "error": "invalid_auth"})
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/chat.postMessage":
+		var msg slackMessage
+
+		err := json.NewDecoder(r.Body).Decode(&msg)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		msg.TS = "1700000000." + strconv.Itoa(100000+len(f.messages))
+		f.messages = append(f.messages, msg)
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "channel": msg.Channel, "ts": msg.TS})
+	case "/conversations.history":
+		q := r.URL.Query()
+
+		var found []slackMessage
+
+		for _, msg := range f.messages {
+			if msg.Channel == q.Get("channel") && msg.TS == q.Get("latest") {
+				found = append(found, msg)
+			}
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "messages": found})
+	default:
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "error": "unknown_method"})
+	}
+}
+
+func TestSlack_roundtrip(t *testing.T) {
+	srv := newFakeSlack()
+	defer srv.Close()
+
+	channels, err := parseChannelMap(`{"1": "C1", "2": "C2", "3": "D3"}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var diag bytes.Buffer
+
+	store := newSlackStore("xoxb-test", channels, &diag)
+	store.api = srv.URL
+	store.codeBlock = true
+
+	var genBuf bytes.Buffer
+
+	err = cmdGenerate(3, 2, generateOptions{sinks: []shareSink{store}}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(srv.messages) != 3 {
+		t.Fatalf("want 3 messages, have %d", len(srv.messages))
+	}
+
+	for i, msg := range srv.messages {
+		if msg.Channel != channels[strconv.Itoa(i+1)] {
+			t.Errorf("unexpected channel for share %d: %q", i+1, msg.Channel)
+		}
+
+		if !strings.HasPrefix(msg.Text, "```") || !strings.HasSuffix(msg.Text, "```") {
+			t.Errorf("message is not a code block: %q", msg.Text)
+		}
+
+		if !strings.Contains(diag.String(), " ts="+msg.TS+"\n") {
+			t.Errorf("timestamp %s not reported: %q", msg.TS, diag.String())
+		}
+	}
+
+	// The first share is read by position, the last one with an explicit channel.
+	store.timestamps = []string{srv.messages[0].TS, "D3:" + srv.messages[2].TS}
+
+	in, err := readSource(store)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var (
+		errBuf bytes.Buffer
+		outBuf bytes.Buffer
+	)
+
+	err = cmdRecover(in, recoverOptions{}, &errBuf, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if errBuf.Len() != 0 {
+		t.Errorf("unexpected diagnostic: %q", errBuf.String())
+	}
+
+	secret := strings.TrimPrefix(strings.SplitN(genBuf.String(), "\n", 2)[0], "secret: ")
+	if outBuf.String() != secret+"\n" {
+		t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
+	}
+}
+
+func TestSlack_errors(t *testing.T) {
+	srv := newFakeSlack()
+	defer srv.Close()
+
+	store := newSlackStore("xoxb-wrong", map[string]string{"1": "C1", "2": "C2"}, &bytes.Buffer{})
+	store.api = srv.URL
+
+	err := cmdGenerate(2, 2, generateOptions{sinks: []shareSink{store}}, &bytes.Buffer{})
+	if err == nil || !strings.HasSuffix(err.Error(), ": invalid_auth") {
+		t.Errorf("unexpected error for an invalid token: %v", err)
+	}
+
+	store.token = "xoxb-test"
+	store.timestamps = []string{"C1:1.0"}
+
+	_, err = store.LoadShares()
+	if err == nil || !strings.HasSuffix(err.Error(), ": no such message") {
+		t.Errorf("unexpected error for a missing message: %v", err)
+	}
+}
@@ -0,0 +1,212 @@
+//go:build cgo
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/miekg/pkcs11"
+)
+
+// smartcardApplication is the CKA_APPLICATION of the data objects that hold shares.
+const smartcardApplication = "github.com/farhaven/secret"
+
+// pkcs11API is the part of the PKCS#11 interface used by smartcardStore.
+type pkcs11API interface {
+	OpenSession(slotID uint, flags uint) (pkcs11.SessionHandle, error)
+	CloseSession(sh pkcs11.SessionHandle) error
+	Login(sh pkcs11.SessionHandle, userType uint, pin string) error
+	Logout(sh pkcs11.SessionHandle) error
+	CreateObject(sh pkcs11.SessionHandle, temp []*pkcs11.Attribute) (pkcs11.ObjectHandle, error)
+	FindObjectsInit(sh pkcs11.SessionHandle, temp []*pkcs11.Attribute) error
+	FindObjects(sh pkcs11.SessionHandle, max int) ([]pkcs11.ObjectHandle, bool, error)
+	FindObjectsFinal(sh pkcs11.SessionHandle) error
+	GetAttributeValue(sh pkcs11.SessionHandle, o pkcs11.ObjectHandle, a []*pkcs11.Attribute) ([]*pkcs11.Attribute, error)
+}
+
+// smartcard is a smart card in a slot and the custodian it is handed to.
+type smartcard struct {
+	Slot      uint   `json:"slot"`
+	Custodian string `json:"custodian"`
+}
+
+// parseCardList parses a JSON card list like [{"slot":0,"custodian":"Alice"}].
+func parseCardList(list string) ([]smartcard, error) {
+	var cards []smartcard
+
+	err := json.Unmarshal([]byte(list), &cards)
+	if err != nil {
+		return nil, fmt.Errorf("parsing card list: %w", err)
+	}
+
+	return cards, nil
+}
+
+// openPKCS11 loads and initializes the PKCS#11 module at path. The returned function finalizes and unloads it.
+func openPKCS11(path string) (*pkcs11.Ctx, func(), error) {
+	ctx := pkcs11.New(path)
+	if ctx == nil {
+		return nil, nil, fmt.Errorf("Loading PKCS#11 module %s failed.", path)
+	}
+
+	err := ctx.Initialize()
+	if err != nil {
+		ctx.Destroy()
+		return nil, nil, fmt.Errorf("initializing PKCS#11 module %s: %w", path, err)
+	}
+
+	return ctx, func() {
+		ctx.Finalize()
+		ctx.Destroy()
+	}, nil
+}
+
+// newSmartcardSink returns a sink that stores the shares on the cards in the JSON card list with the PKCS#11 module at
+// path. The returned function unloads the module.
+func newSmartcardSink(path, cardList, pin, ceremonyID string) (shareSink, func(), error) {
+	cards, err := parseCardList(cardList)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p11, closeP11, err := openPKCS11(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	store := newSmartcardStore(p11, pin, ceremonyID)
+	store.cards = cards
+
+	return store, closeP11, nil
+}
+
+// newSmartcardSource returns a source that reads the shares from the cards in slots with the PKCS#11 module at path.
+// The returned function unloads the module.
+func newSmartcardSource(path string, slots []uint, pin, ceremonyID string) (shareSource, func(), error) {
+	p11, closeP11, err := openPKCS11(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	store := newSmartcardStore(p11, pin, ceremonyID)
+	store.slots = slots
+
+	return store, closeP11, nil
+}
+
+// smartcardStore stores each share as a CKO_DATA object on its own smart card. The objects are labeled with the
+// ceremony ID.
+type smartcardStore struct {
+	p11        pkcs11API
+	pin        string
+	ceremonyID string
+
+	cards []smartcard // Cards to store the shares on, one per share.
+	slots []uint      // Slots to read shares from.
+}
+
+func newSmartcardStore(p11 pkcs11API, pin, ceremonyID string) *smartcardStore {
+	return &smartcardStore{p11: p11, pin: pin, ceremonyID: ceremonyID}
+}
+
+// withSession runs f in a logged in session on the card in slot.
+func (s *smartcardStore) withSession(slot uint, rw bool, f func(sh pkcs11.SessionHandle) error) error {
+	flags := uint(pkcs11.CKF_SERIAL_SESSION)
+	if rw {
+		flags |= pkcs11.CKF_RW_SESSION
+	}
+
+	sh, err := s.p11.OpenSession(slot, flags)
+	if err != nil {
+		return err
+	}
+	defer s.p11.CloseSession(sh)
+
+	err = s.p11.Login(sh, pkcs11.CKU_USER, s.pin)
+	if err != nil {
+		return err
+	}
+	defer s.p11.Logout(sh)
+
+	return f(sh)
+}
+
+func (s *smartcardStore) StoreShares(shares []storedShare) error {
+	if len(shares) > len(s.cards) {
+		return fmt.Errorf("Need a smart card for each of the %d shares, have %d.", len(shares), len(s.cards))
+	}
+
+	for i, share := range shares {
+		card := s.cards[i]
+
+		err := s.withSession(card.Slot, true, func(sh pkcs11.SessionHandle) error {
+			_, err := s.p11.CreateObject(sh, []*pkcs11.Attribute{
+				pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_DATA),
+				pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+				pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+				pkcs11.NewAttribute(pkcs11.CKA_MODIFIABLE, false),
+				pkcs11.NewAttribute(pkcs11.CKA_LABEL, s.ceremonyID),
+				pkcs11.NewAttribute(pkcs11.CKA_APPLICATION, smartcardApplication),
+				pkcs11.NewAttribute(pkcs11.CKA_VALUE, []byte(share.line)),
+			})
+
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("storing share on smart card in slot %d for %s: %w", card.Slot, card.Custodian, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *smartcardStore) LoadShares() ([]string, error) {
+	var lines []string
+
+	for _, slot := range s.slots {
+		err := s.withSession(slot, false, func(sh pkcs11.SessionHandle) error {
+			template := []*pkcs11.Attribute{
+				pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_DATA),
+				pkcs11.NewAttribute(pkcs11.CKA_APPLICATION, smartcardApplication),
+			}
+
+			if s.ceremonyID != "" {
+				template = append(template, pkcs11.NewAttribute(pkcs11.CKA_LABEL, s.ceremonyID))
+			}
+
+			err := s.p11.FindObjectsInit(sh, template)
+			if err != nil {
+				return err
+			}
+
+			objects, _, err := s.p11.FindObjects(sh, 16)
+			s.p11.FindObjectsFinal(sh)
+
+			if err != nil {
+				return err
+			}
+
+			if len(objects) == 0 {
+				return errors.New("no share on the card")
+			}
+
+			for _, o := range objects {
+				attrs, err := s.p11.GetAttributeValue(sh, o, []*pkcs11.Attribute{pkcs11.NewAttribute(pkcs11.CKA_VALUE, nil)})
+				if err != nil {
+					return err
+				}
+
+				lines = append(lines, string(attrs[0].Value))
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("reading share from smart card in slot %d: %w", slot, err)
+		}
+	}
+
+	return lines, nil
+}
@@ -0,0 +1,16 @@
+//go:build !cgo
+
+package main
+
+import "errors"
+
+// The PKCS#11 bindings require cgo.
+var errNoSmartcards = errors.New("Smart card support requires a build with cgo.")
+
+func newSmartcardSink(path, cardList, pin, ceremonyID string) (shareSink, func(), error) {
+	return nil, nil, errNoSmartcards
+}
+
+func newSmartcardSource(path string, slots []uint, pin, ceremonyID string) (shareSource, func(), error) {
+	return nil, nil, errNoSmartcards
+}
@@ -0,0 +1,234 @@
+//go:build cgo
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/miekg/pkcs11"
+)
+
+// fakePKCS11 is an in-memory PKCS#11 module with a token in every slot.
+type fakePKCS11 struct {
+	pin      string
+	objects  map[uint][][]*pkcs11.Attribute // Objects by slot.
+	sessions map[pkcs11.SessionHandle]*fakeSession
+}
+
+type fakeSession struct {
+	slot     uint
+	rw       bool
+	loggedIn bool
+	found    []pkcs11.ObjectHandle
+}
+
+func newFakePKCS11(pin string) *fakePKCS11 {
+	return &fakePKCS11{pin: pin, objects: make(map[uint][][]*pkcs11.Attribute), sessions: make(map[pkcs11.SessionHandle]*fakeSession)}
+}
+
+func (f *fakePKCS11) OpenSession(slotID uint, flags uint) (pkcs11.SessionHandle, error) {
+	sh := pkcs11.SessionHandle(len(f.sessions) + 1)
+	f.sessions[sh] = &fakeSession{slot: slotID, rw: flags&pkcs11.CKF_RW_SESSION != 0}
+
+	return sh, nil
+}
+
+func (f *fakePKCS11) CloseSession(sh pkcs11.SessionHandle) error {
+	delete(f.sessions, sh)
+	return nil
+}
+
+func (f *fakePKCS11) Login(sh pkcs11.SessionHandle, userType uint, pin string) error {
+	if pin != f.pin {
+		return pkcs11.Error(pkcs11.CKR_PIN_INCORRECT)
+	}
+
+	f.sessions[sh].loggedIn = true
+
+	return nil
+}
+
+func (f *fakePKCS11) Logout(sh pkcs11.SessionHandle) error {
+	f.sessions[sh].loggedIn = false
+	return nil
+}
+
+func (f *fakePKCS11) CreateObject(sh pkcs11.SessionHandle, temp []*pkcs11.Attribute) (pkcs11.ObjectHandle, error) {
+	s := f.sessions[sh]
+	if !s.rw {
+		return 0, pkcs11.Error(pkcs11.CKR_SESSION_READ_ONLY)
+	}
+
+	if !s.loggedIn {
+		return 0, pkcs11.Error(pkcs11.CKR_USER_NOT_LOGGED_IN)
+	}
+
+	f.objects[s.slot] = append(f.objects[s.slot], temp)
+
+	return pkcs11.ObjectHandle(len(f.objects[s.slot])), nil
+}
+
+func (f *fakePKCS11) FindObjectsInit(sh pkcs11.SessionHandle, temp []*pkcs11.Attribute) error {
+	s := f.sessions[sh]
+	s.found = nil
+
+	for i, attrs := range f.objects[s.slot] {
+		if fakeMatches(attrs, temp) {
+			s.found = append(s.found, pkcs11.ObjectHandle(i+1))
+		}
+	}
+
+	return nil
+}
+
+func fakeMatches(attrs, temp []*pkcs11.Attribute) bool {
+	for _, want := range temp {
+		ok := false
+
+		for _, have := range attrs {
+			if have.Type == want.Type && bytes.Equal(have.Value, want.Value) {
+				ok = true
+			}
+		}
+
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (f *fakePKCS11) FindObjects(sh pkcs11.SessionHandle, max int) ([]pkcs11.ObjectHandle, bool, error) {
+	s := f.sessions[sh]
+
+	found := s.found
+	if len(found) > max {
+		found = found[:max]
+	}
+
+	s.found = s.found[len(found):]
+
+	return found, false, nil
+}
+
+func (f *fakePKCS11) FindObjectsFinal(sh pkcs11.SessionHandle) error {
+	f.sessions[sh].found = nil
+	return nil
+}
+
+func (f *fakePKCS11) GetAttributeValue(sh pkcs11.SessionHandle, o pkcs11.ObjectHandle, a []*pkcs11.Attribute) ([]*pkcs11.Attribute, error) {
+	s := f.sessions[sh]
+	if !s.loggedIn {
+		return nil, pkcs11.Error(pkcs11.CKR_USER_NOT_LOGGED_IN)
+	}
+
+	var out []*pkcs11.Attribute
+
+	for _, want := range a {
+		for _, have := range f.objects[s.slot][o-1] {
+			if have.Type == want.Type {
+				out = append(out, have)
+			}
+		}
+	}
+
+	return out, nil
+}
+
+func fakeAttribute(attrs []*pkcs11.Attribute, typ uint) string {
+	for _, a := range attrs {
+		if a.Type == typ {
+			return string(a.Value)
+		}
+	}
+
+	return ""
+}
+
+func TestSmartcard_roundtrip(t *testing.T) {
+	p11 := newFakePKCS11("123456")
+
+	cards, err := parseCardList(`[{"slot":0,"custodian":"Alice"},{"slot":1,"custodian":"Bob"},{"slot":4,"custodian":"Carol"}]`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	store := newSmartcardStore(p11, "123456", "2026-10")
+	store.cards = cards
+
+	var genBuf bytes.Buffer
+
+	err = cmdGenerate(3, 2, generateOptions{sinks: []shareSink{store}}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, slot := range []uint{0, 1, 4} {
+		if len(p11.objects[slot]) != 1 {
+			t.Fatalf("want 1 object in slot %d, have %d", slot, len(p11.objects[slot]))
+		}
+
+		attrs := p11.objects[slot][0]
+
+		if label := fakeAttribute(attrs, pkcs11.CKA_LABEL); label != "2026-10" {
+			t.Errorf("unexpected label in slot %d: %q", slot, label)
+		}
+
+		if !fakeMatches(attrs, []*pkcs11.Attribute{pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_DATA)}) {
+			t.Errorf("object in slot %d is not a CKO_DATA object", slot)
+		}
+	}
+
+	if len(p11.sessions) != 0 {
+		t.Errorf("%d sessions left open", len(p11.sessions))
+	}
+
+	store.slots = []uint{1, 4}
+
+	in, err := readSource(store)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var (
+		errBuf bytes.Buffer
+		outBuf bytes.Buffer
+	)
+
+	err = cmdRecover(in, recoverOptions{}, &errBuf, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if errBuf.Len() != 0 {
+		t.Errorf("unexpected diagnostic: %q", errBuf.String())
+	}
+
+	secret := strings.TrimPrefix(strings.SplitN(genBuf.String(), "\n", 2)[0], "secret: ")
+	if outBuf.String() != secret+"\n" {
+		t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
+	}
+}
+
+func TestSmartcard_errors(t *testing.T) {
+	p11 := newFakePKCS11("123456")
+
+	store := newSmartcardStore(p11, "000000", "2026-10")
+	store.cards = []smartcard{{Slot: 0, Custodian: "Alice"}, {Slot: 1, Custodian: "Bob"}}
+
+	err := cmdGenerate(2, 2, generateOptions{sinks: []shareSink{store}}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "CKR_PIN_INCORRECT") {
+		t.Errorf("unexpected error for a wrong PIN: %v", err)
+	}
+
+	store.pin = "123456"
+	store.slots = []uint{7}
+
+	_, err = store.LoadShares()
+	if err == nil || !strings.HasSuffix(err.Error(), "no share on the card") {
+		t.Errorf("unexpected error for an empty card: %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var (
+	// smsHeader matches the header lines SMS gateways add around message bodies.
+	smsHeader = regexp.MustCompile(`(?i)^(from|to|received|sent|date|status|smsc|message-id|delivery report|delivered)\b[^,]*(:|$)`)
+
+	// smsTimestamp matches a timestamp at the start of a line, like "2026-10-14 09:12:33" or "[14/10/2026 09:12]".
+	smsTimestamp = regexp.MustCompile(`^\[?(\d{4}-\d{2}-\d{2}|\d{1,2}/\d{1,2}/\d{2,4})[ T]\d{1,2}:\d{2}(:\d{2})?([.,]\d+)?(Z|[+-]\d{2}:?\d{2})?\]?\s*`)
+
+	// smsShare matches a share in the text of a message.
+	smsShare = regexp.MustCompile(`(id:[^:\s]+:)?\d+,\d+`)
+
+	// smsReplacer undoes common autocorrect substitutions of phones and gateways.
+	smsReplacer = strings.NewReplacer(
+		"‘", "'", "’", "'", "“", `"`, "”", `"`,
+		"‚", ",", "，", ",", "،", ",",
+		"–", "-", "—", "-",
+		"\u00a0", " ", "\u200b", "", "\ufeff", "",
+	)
+
+	// smsCommaSpace matches the whitespace that autocorrect inserts after a comma.
+	smsCommaSpace = regexp.MustCompile(`(\d),\s+(\d)`)
+)
+
+// smsSource reads shares from SMS messages, like the log of an SMS gateway. Gateway headers, timestamps and
+// autocorrect substitutions are removed before the shares are extracted from the message texts.
+type smsSource struct {
+	r io.Reader
+}
+
+func (s smsSource) LoadShares() ([]string, error) {
+	var lines []string
+
+	scanner := bufio.NewScanner(s.r)
+	for scanner.Scan() {
+		t := strings.TrimSpace(smsReplacer.Replace(scanner.Text()))
+		t = smsTimestamp.ReplaceAllString(t, "")
+
+		if smsHeader.MatchString(t) {
+			continue
+		}
+
+		t = smsCommaSpace.ReplaceAllString(t, "$1,$2")
+
+		lines = append(lines, smsShare.FindAllString(t, -1)...)
+	}
+
+	return lines, scanner.Err()
+}
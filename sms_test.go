@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestSMS_gatewayLog(t *testing.T) {
+	fh, err := os.Open("testdata/sms-gateway.log")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer fh.Close()
+
+	lines, err := smsSource{fh}.LoadShares()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := []string{
+		"1,19943338053965968504353533017903769217",
+		"2,161872477868088873785792630750634181303",
+		"5,160274174127002500413544256698187925606",
+	}
+
+	if len(lines) != len(want) {
+		t.Fatalf("unexpected shares. want %q, have %q", want, lines)
+	}
+
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("unexpected share %d. want %q, have %q", i, want[i], lines[i])
+		}
+	}
+
+	_, err = fh.Seek(0, io.SeekStart)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	in, err := readSource(smsSource{fh})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var (
+		errBuf bytes.Buffer
+		outBuf bytes.Buffer
+	)
+
+	err = cmdRecover(in, recoverOptions{}, &errBuf, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if errBuf.Len() != 0 {
+		t.Errorf("unexpected diagnostic: %q", errBuf.String())
+	}
+
+	wantSecret := "7uPIBqGKMPpProBYFFR3S\n"
+	if outBuf.String() != wantSecret {
+		t.Errorf("unexpected secret. want %q, have %q", wantSecret, outBuf.String())
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// socketConnTimeout bounds how long a connection to the socket server may take, since it serves one connection at a
+// time.
+const socketConnTimeout = time.Minute
+
+// socketCommand is a line sent to the socket server. Command is "generate", with N and K, or "recover", with Shares.
+type socketCommand struct {
+	Command string   `json:"command"`
+	N       int      `json:"n,omitempty"`
+	K       int      `json:"k,omitempty"`
+	Shares  []string `json:"shares,omitempty"`
+}
+
+// socketError is the response of the socket server to a command that failed.
+type socketError struct {
+	Error string `json:"error"`
+}
+
+// cmdServeSocket runs the socket server on a Unix domain socket at path until it fails. Only the owner of the socket
+// may connect to it.
+func cmdServeSocket(path string) error {
+	ln, err := listenSocket(path)
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+
+	return serveSocket(ln)
+}
+
+// listenSocket listens on a Unix domain socket at path with mode 0600. The socket is created in a private directory and
+// linked to path once its mode is set, so that others can not connect to it in between.
+func listenSocket(path string) (net.Listener, error) {
+	if path == "" {
+		return nil, errors.New("The socket mode requires -socket-path.")
+	}
+
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".secret-socket-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "socket")
+
+	ln, err := net.ListenUnix("unix", &net.UnixAddr{Name: name, Net: "unix"})
+	if err != nil {
+		return nil, err
+	}
+
+	// The socket in dir is removed with dir, socketListener removes it at path.
+	ln.SetUnlinkOnClose(false)
+
+	err = os.Chmod(name, 0o600)
+	if err == nil {
+		// Unlike a rename, a link does not replace an existing file at path.
+		err = os.Link(name, path)
+	}
+
+	if err != nil {
+		ln.Close()
+		return nil, err
+	}
+
+	return socketListener{ln, path}, nil
+}
+
+// socketListener is a listener on a Unix domain socket that removes the socket at path when it is closed.
+type socketListener struct {
+	*net.UnixListener
+	path string
+}
+
+func (l socketListener) Close() error {
+	err := l.UnixListener.Close()
+	os.Remove(l.path)
+
+	return err
+}
+
+// serveSocket serves the connections accepted from ln one at a time until ln is closed. Each line of a connection is
+// a JSON socketCommand, which is answered by a line with the json output of cmdGenerate or cmdRecover, or with a
+// socketError.
+func serveSocket(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+
+			return err
+		}
+
+		serveSocketConn(conn)
+	}
+}
+
+func serveSocketConn(conn net.Conn) {
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(socketConnTimeout))
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(nil, maxCeremonyRequestSize)
+
+	enc := json.NewEncoder(conn)
+
+	for scanner.Scan() {
+		var out bytes.Buffer
+
+		err := handleSocketCommand(scanner.Bytes(), &out)
+		if err != nil {
+			err = enc.Encode(socketError{Error: err.Error()})
+		} else {
+			_, err = out.WriteTo(conn)
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// handleSocketCommand runs the socketCommand in line and writes its response to out.
+func handleSocketCommand(line []byte, out io.Writer) error {
+	var cmd socketCommand
+
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(&cmd)
+	if err != nil {
+		return fmt.Errorf("Invalid command: %w", err)
+	}
+
+	switch cmd.Command {
+	case "generate":
+		return generateJSON(cmd.N, cmd.K, out)
+	case "recover":
+		return recoverJSON(cmd.Shares, out)
+	default:
+		return fmt.Errorf("Unknown command %q.", cmd.Command)
+	}
+}
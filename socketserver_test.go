@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSocketServer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret.sock")
+
+	ln, err := listenSocket(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if fi.Mode().Perm() != 0o600 {
+		t.Errorf("want mode %o, have %o", 0o600, fi.Mode().Perm())
+	}
+
+	done := make(chan error)
+	go func() { done <- serveSocket(ln) }()
+
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer conn.Close()
+
+	var (
+		enc     = json.NewEncoder(conn)
+		scanner = bufio.NewScanner(conn)
+	)
+
+	send := func(cmd socketCommand, resp any) {
+		t.Helper()
+
+		err := enc.Encode(cmd)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if !scanner.Scan() {
+			t.Fatalf("no response: %v", scanner.Err())
+		}
+
+		err = json.Unmarshal(scanner.Bytes(), resp)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	var generated jsonShares
+
+	send(socketCommand{Command: "generate", N: 5, K: 3}, &generated)
+
+	if generated.Secret == "" || generated.Threshold != 3 || len(generated.Shares) != 5 {
+		t.Fatalf("unexpected response %+v", generated)
+	}
+
+	var recovered jsonSecret
+
+	send(socketCommand{Command: "recover", Shares: generated.Shares[1:4]}, &recovered)
+
+	if recovered.Secret != generated.Secret {
+		t.Errorf("want secret %q, have %q", generated.Secret, recovered.Secret)
+	}
+
+	var failed socketError
+
+	send(socketCommand{Command: "recover", Shares: []string{"1,x"}}, &failed)
+
+	if failed.Error == "" {
+		t.Errorf("expected error for a malformed share, got %q", scanner.Text())
+	}
+
+	send(socketCommand{Command: "reshare"}, &failed)
+
+	if failed.Error != `Unknown command "reshare".` {
+		t.Errorf("unexpected error %q", failed.Error)
+	}
+
+	conn.Close()
+	ln.Close()
+
+	err = <-done
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, err = os.Stat(path)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("socket not removed: %v", err)
+	}
+}
+
+func TestListenSocket_existingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "secret.sock")
+
+	err := os.WriteFile(path, []byte("keep"), 0o600)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ln, err := listenSocket(path)
+	if err == nil {
+		ln.Close()
+		t.Fatal("expected error, got nil")
+	}
+
+	buf, err := os.ReadFile(path)
+	if err != nil || string(buf) != "keep" {
+		t.Errorf("existing file replaced: %q, %v", buf, err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(entries) != 1 {
+		t.Errorf("temporary directory not removed: %v", entries)
+	}
+}
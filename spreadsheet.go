@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// spreadsheetHeader is the header row of the spreadsheet CSV format.
+var spreadsheetHeader = []string{"Index", "Value", "Threshold", "TotalShares", "Label", "CreatedAt", "ExpiresAt"}
+
+// writeSpreadsheet writes shares as a CSV file with one row per share, for organizations that manage custodians in
+// spreadsheets. The value of each row is the share line of the text output, which is what a custodian needs for the
+// recovery. The label is the PRIMARY or BACKUP label of the share if backups is set. ExpiresAt is empty if
+// expiresAfter is zero.
+func writeSpreadsheet(out io.Writer, shares []storedShare, k, backups int, expiresAfter time.Duration) error {
+	w := csv.NewWriter(out)
+
+	err := w.Write(spreadsheetHeader)
+	if err != nil {
+		return err
+	}
+
+	created := time.Now().UTC().Truncate(time.Second)
+
+	expires := ""
+	if expiresAfter > 0 {
+		expires = created.Add(expiresAfter).Format(time.RFC3339)
+	}
+
+	for i, share := range shares {
+		label := "Share " + strconv.Itoa(i+1)
+		if backups > 0 {
+			label = shareLabel(i, len(shares), backups)
+		}
+
+		err := w.Write([]string{
+			share.index,
+			share.line,
+			strconv.Itoa(k),
+			strconv.Itoa(len(shares)),
+			label,
+			created.Format(time.RFC3339),
+			expires,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+
+	return w.Error()
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/csv"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSpreadsheet(t *testing.T) {
+	var (
+		buf       bytes.Buffer
+		secretBuf bytes.Buffer
+	)
+
+	opts := generateOptions{format: "spreadsheet", secretOut: &secretBuf, expiresAfter: 24 * time.Hour}
+
+	err := cmdGenerate(5, 3, opts, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(records) != 6 {
+		t.Fatalf("want 6 records, have %d: %q", len(records), records)
+	}
+
+	if !reflect.DeepEqual(records[0], spreadsheetHeader) {
+		t.Errorf("unexpected header: %q", records[0])
+	}
+
+	var shares []string
+
+	for i, record := range records[1:] {
+		if record[2] != "3" || record[3] != "5" || record[4] != "Share "+strconv.Itoa(i+1) {
+			t.Errorf("unexpected record: %q", record)
+		}
+
+		if !strings.HasPrefix(record[1], record[0]+",") {
+			t.Errorf("value %q is not the share with index %q", record[1], record[0])
+		}
+
+		created, err := time.Parse(time.RFC3339, record[5])
+		if err != nil {
+			t.Errorf("invalid CreatedAt: %s", err)
+		}
+
+		expires, err := time.Parse(time.RFC3339, record[6])
+		if err != nil {
+			t.Errorf("invalid ExpiresAt: %s", err)
+		}
+
+		if expires.Sub(created) != 24*time.Hour {
+			t.Errorf("unexpected expiry: %s - %s", created, expires)
+		}
+
+		shares = append(shares, record[1])
+	}
+
+	var (
+		errBuf bytes.Buffer
+		outBuf bytes.Buffer
+	)
+
+	err = cmdRecover(strings.NewReader(strings.Join(shares[:3], "\n")), recoverOptions{}, &errBuf, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	secret := strings.TrimSpace(strings.TrimPrefix(secretBuf.String(), "secret: "))
+	if outBuf.String() != secret+"\n" {
+		t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
+	}
+}
+
+func TestSpreadsheet_noSecretOut(t *testing.T) {
+	err := cmdGenerate(5, 3, generateOptions{format: "spreadsheet"}, &bytes.Buffer{})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestSpreadsheet_shareLines(t *testing.T) {
+	key := newSigningKey(t)
+
+	var buf bytes.Buffer
+
+	opts := generateOptions{
+		format:     "spreadsheet",
+		secretOut:  &bytes.Buffer{},
+		ceremonyID: "2026-10",
+		signingKey: key,
+		backups:    2,
+	}
+
+	err := cmdGenerate(5, 3, opts, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var labels []string
+
+	for _, record := range records[1:] {
+		labels = append(labels, record[4])
+
+		label, line := splitShareLabel(record[1])
+		if label != record[4] {
+			t.Errorf("value %q does not carry the label %q", record[1], record[4])
+		}
+
+		share, err := verifyShare(key.Public().(ed25519.PublicKey), line)
+		if err != nil {
+			t.Errorf("value %q: %s", record[1], err)
+		}
+
+		if !strings.HasPrefix(share, "id:2026-10:"+record[0]+",") {
+			t.Errorf("value %q does not carry the ceremony ID", record[1])
+		}
+	}
+
+	want := "PRIMARY-1 PRIMARY-2 PRIMARY-3 BACKUP-1 BACKUP-2"
+	if strings.Join(labels, " ") != want {
+		t.Errorf("unexpected labels. want %q, have %q", want, strings.Join(labels, " "))
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// ssmAPI is the part of the Systems Manager client used by ssmStore.
+type ssmAPI interface {
+	PutParameter(ctx context.Context, params *ssm.PutParameterInput, optFns ...func(*ssm.Options)) (*ssm.PutParameterOutput, error)
+	GetParametersByPath(ctx context.Context, params *ssm.GetParametersByPathInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error)
+}
+
+// ssmStore stores shares in the AWS Systems Manager Parameter Store as SecureString parameters named
+// <prefix>/share/<index>, so that AWS encrypts them with KMS.
+type ssmStore struct {
+	client     ssmAPI
+	prefix     string
+	kmsKeyID   string // The KMS key the parameters are encrypted with. The AWS managed key is used if it is empty.
+	ceremonyID string
+}
+
+func newSSMStore(client ssmAPI, prefix, kmsKeyID, ceremonyID string) *ssmStore {
+	return &ssmStore{client: client, prefix: strings.TrimSuffix(prefix, "/"), kmsKeyID: kmsKeyID, ceremonyID: ceremonyID}
+}
+
+func (s *ssmStore) StoreShares(shares []storedShare) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	for _, share := range shares {
+		tags := []types.Tag{
+			{Key: aws.String("managed-by"), Value: aws.String("github.com/farhaven/secret")},
+			{Key: aws.String("share-index"), Value: aws.String(share.index)},
+		}
+
+		if s.ceremonyID != "" {
+			tags = append(tags, types.Tag{Key: aws.String("ceremony-id"), Value: aws.String(s.ceremonyID)})
+		}
+
+		input := &ssm.PutParameterInput{
+			Name:  aws.String(s.prefix + "/share/" + share.index),
+			Value: aws.String(share.line),
+			Type:  types.ParameterTypeSecureString,
+			Tags:  tags,
+		}
+
+		if s.kmsKeyID != "" {
+			input.KeyId = aws.String(s.kmsKeyID)
+		}
+
+		_, err := s.client.PutParameter(ctx, input)
+		if err != nil {
+			return fmt.Errorf("storing share in Parameter Store: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *ssmStore) LoadShares() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var params []types.Parameter
+
+	paginator := ssm.NewGetParametersByPathPaginator(s.client, &ssm.GetParametersByPathInput{
+		Path:           aws.String(s.prefix + "/share"),
+		WithDecryption: aws.Bool(true),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing shares in Parameter Store: %w", err)
+		}
+
+		params = append(params, page.Parameters...)
+	}
+
+	sort.Slice(params, func(i, j int) bool {
+		return aws.ToString(params[i].Name) < aws.ToString(params[j].Name)
+	})
+
+	lines := make([]string, 0, len(params))
+
+	for _, p := range params {
+		if p.Type != types.ParameterTypeSecureString {
+			return nil, fmt.Errorf("Share %s is not a SecureString parameter.", aws.ToString(p.Name))
+		}
+
+		lines = append(lines, aws.ToString(p.Value))
+	}
+
+	return lines, nil
+}
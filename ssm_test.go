@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// fakeSSM is an in-memory implementation of ssmAPI. It returns one parameter per page to exercise the pagination.
+type fakeSSM struct {
+	params map[string]*ssm.PutParameterInput
+}
+
+func newFakeSSM() *fakeSSM {
+	return &fakeSSM{params: make(map[string]*ssm.PutParameterInput)}
+}
+
+func (f *fakeSSM) PutParameter(ctx context.Context, params *ssm.PutParameterInput, optFns ...func(*ssm.Options)) (*ssm.PutParameterOutput, error) {
+	name := aws.ToString(params.Name)
+	if _, ok := f.params[name]; ok {
+		return nil, &types.ParameterAlreadyExists{Message: aws.String("parameter exists")}
+	}
+
+	f.params[name] = params
+
+	return &ssm.PutParameterOutput{Version: 1}, nil
+}
+
+func (f *fakeSSM) GetParametersByPath(ctx context.Context, params *ssm.GetParametersByPathInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error) {
+	var names []string
+
+	for name := range f.params {
+		if strings.HasPrefix(name, aws.ToString(params.Path)+"/") && name > aws.ToString(params.NextToken) {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		return &ssm.GetParametersByPathOutput{}, nil
+	}
+
+	first := names[0]
+	for _, name := range names {
+		if name < first {
+			first = name
+		}
+	}
+
+	p := f.params[first]
+
+	value := "encrypted"
+	if aws.ToBool(params.WithDecryption) || p.Type != types.ParameterTypeSecureString {
+		value = aws.ToString(p.Value)
+	}
+
+	out := &ssm.GetParametersByPathOutput{
+		Parameters: []types.Parameter{{Name: p.Name, Type: p.Type, Value: aws.String(value)}},
+	}
+
+	if len(names) > 1 {
+		out.NextToken = aws.String(first)
+	}
+
+	return out, nil
+}
+
+func TestSSM_roundtrip(t *testing.T) {
+	client := newFakeSSM()
+	store := newSSMStore(client, "/ceremonies/2026-10/", "alias/shares", "2026-10")
+
+	var genBuf bytes.Buffer
+
+	err := cmdGenerate(3, 2, generateOptions{sinks: []shareSink{store}}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(client.params) != 3 {
+		t.Fatalf("want 3 parameters, have %d", len(client.params))
+	}
+
+	for name, p := range client.params {
+		if !strings.HasPrefix(name, "/ceremonies/2026-10/share/") {
+			t.Errorf("unexpected parameter name %q", name)
+		}
+
+		if p.Type != types.ParameterTypeSecureString {
+			t.Errorf("parameter %s is a %s, not a SecureString", name, p.Type)
+		}
+
+		if aws.ToString(p.KeyId) != "alias/shares" {
+			t.Errorf("parameter %s is encrypted with %q", name, aws.ToString(p.KeyId))
+		}
+	}
+
+	lines, err := newSSMStore(client, "/ceremonies/2026-10", "", "").LoadShares()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(lines) != 3 {
+		t.Fatalf("want 3 shares, have %q", lines)
+	}
+
+	var outBuf bytes.Buffer
+
+	err = cmdRecover(strings.NewReader(strings.Join(lines, "\n")), recoverOptions{}, &bytes.Buffer{}, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !strings.HasPrefix(genBuf.String(), "secret: "+outBuf.String()) {
+		t.Errorf("unexpected recovered secret %q", outBuf.String())
+	}
+}
+
+func TestSSM_defaultKey(t *testing.T) {
+	client := newFakeSSM()
+
+	err := newSSMStore(client, "/secret", "", "").StoreShares([]storedShare{{"1", "1,19943338053965968504353533017903769217"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if p := client.params["/secret/share/1"]; p == nil || p.KeyId != nil {
+		t.Errorf("unexpected parameter %+v", p)
+	}
+}
+
+func TestSSM_errors(t *testing.T) {
+	client := newFakeSSM()
+	store := newSSMStore(client, "/secret", "", "")
+
+	shares := []storedShare{{"1", "1,19943338053965968504353533017903769217"}}
+
+	err := store.StoreShares(shares)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err = store.StoreShares(shares)
+	if err == nil {
+		t.Fatal("expected error for an existing parameter, got nil")
+	}
+
+	client.params["/secret/share/2"] = &ssm.PutParameterInput{Name: aws.String("/secret/share/2"), Type: types.ParameterTypeString, Value: aws.String("2,1")}
+
+	_, err = store.LoadShares()
+	if err == nil {
+		t.Fatal("expected error for a String parameter, got nil")
+	}
+}
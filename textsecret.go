@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/farhaven/secret/shamir"
+)
+
+// encodingHeader is the output header that names the encoding of a caller provided secret.
+const encodingHeader = "secret-encoding"
+
+// textEncoding is the value of the encoding header for secrets that are strings.
+const textEncoding = "text"
+
+// parseTextSecret converts a caller provided secret string, like a password or a recovery phrase, to the number that
+// is shared. The bytes of the string are the big endian digits of the number, so all of its bits are kept. The secret
+// must fit into the field.
+func parseTextSecret(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, errors.New("The secret must not be empty.")
+	}
+
+	if strings.HasPrefix(s, "\x00") {
+		// Leading zero bytes would be lost in the conversion.
+		return nil, errors.New("The secret must not start with a NUL byte.")
+	}
+
+	secret := new(big.Int).SetBytes([]byte(s))
+	if secret.Cmp(shamir.Prime) >= 0 {
+		return nil, fmt.Errorf("The secret is longer than the %d bits the field supports.", shamir.Prime.BitLen()-1)
+	}
+
+	return secret, nil
+}
+
+// encodeTextSecret formats a secret created by parseTextSecret as the original string.
+func encodeTextSecret(secret *big.Int) (string, error) {
+	return string(secret.Bytes()), nil
+}
+
+// readTextSecretFile reads a caller provided secret string from the first line of the file name, with surrounding
+// whitespace trimmed. Any further lines are ignored with a warning on diag.
+func readTextSecretFile(name string, diag io.Writer) (string, error) {
+	fh, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer fh.Close()
+
+	scanner := shamir.NewScanner(fh)
+
+	var line string
+	if scanner.Scan() {
+		line = strings.TrimSpace(scanner.Text())
+	}
+
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) != "" {
+			fmt.Fprintf(diag, "%s has more than one line, only the first line is used as the secret\n", name)
+			break
+		}
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+
+	return line, nil
+}
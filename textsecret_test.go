@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTextSecret_roundtrip(t *testing.T) {
+	for _, want := range []string{"hunter2", "correct horse", "ünïcödé"} {
+		t.Run(want, func(t *testing.T) {
+			secret, err := parseTextSecret(want)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			opts := generateOptions{
+				secret:       secret,
+				encodeSecret: encodeTextSecret,
+				header:       []string{encodingHeader + ": " + textEncoding},
+			}
+
+			var genBuf bytes.Buffer
+
+			err = cmdGenerate(5, 3, opts, &genBuf)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !strings.HasPrefix(genBuf.String(), "secret: "+want+"\n") {
+				t.Errorf("unexpected secret line in %q", genBuf.String())
+			}
+
+			var outBuf bytes.Buffer
+
+			err = cmdRecover(&genBuf, recoverOptions{}, &bytes.Buffer{}, &outBuf)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if outBuf.String() != want+"\n" {
+				t.Errorf("unexpected recovered secret. want %q, have %q", want, outBuf.String())
+			}
+		})
+	}
+}
+
+func TestParseTextSecret_errors(t *testing.T) {
+	for _, s := range []string{"", "\x00abc", strings.Repeat("x", 17), "\xff" + strings.Repeat("x", 15)} {
+		_, err := parseTextSecret(s)
+		if err == nil {
+			t.Errorf("expected error for %q, got nil", s)
+		}
+	}
+
+	// The largest string that fits is 16 bytes with the highest bit clear.
+	_, err := parseTextSecret("\x7f" + strings.Repeat("x", 14) + "\xfe")
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestRecover_unknownEncoding(t *testing.T) {
+	input := "secret-encoding: rot13\n1,19943338053965968504353533017903769217\n2,161872477868088873785792630750634181303\n"
+
+	err := cmdRecover(strings.NewReader(input), recoverOptions{}, &bytes.Buffer{}, &bytes.Buffer{})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestReadTextSecretFile(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		content  string
+		wantWarn bool
+	}{
+		{"single line", "hunter2", false},
+		{"trailing newline", "  hunter2 \n\n", false},
+		{"multiple lines", "hunter2\ncorrect horse\n", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			name := filepath.Join(t.TempDir(), "secret.txt")
+
+			err := os.WriteFile(name, []byte(tc.content), 0o600)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			var diag bytes.Buffer
+
+			have, err := readTextSecretFile(name, &diag)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if have != "hunter2" {
+				t.Errorf("want secret %q, have %q", "hunter2", have)
+			}
+
+			if warned := diag.Len() != 0; warned != tc.wantWarn {
+				t.Errorf("unexpected diagnostic %q", diag.String())
+			}
+		})
+	}
+
+	_, err := readTextSecretFile(filepath.Join(t.TempDir(), "missing"), &bytes.Buffer{})
+	if err == nil {
+		t.Fatal("expected error for a missing file, got nil")
+	}
+}
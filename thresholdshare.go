@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// shareThreshold returns the threshold the shares claim with their threshold field. Shares that disagree are reported
+// to diag and the threshold claimed by most shares is used. It is an error if no threshold is claimed by most shares.
+// The threshold is zero if no share claims one.
+func shareThreshold(claims []int, diag io.Writer) (int, error) {
+	if len(claims) == 0 {
+		return 0, nil
+	}
+
+	counts := make(map[int]int)
+	for _, k := range claims {
+		counts[k]++
+	}
+
+	var (
+		seen      []int
+		threshold int
+		tie       bool
+	)
+
+	for k, count := range counts {
+		seen = append(seen, k)
+
+		switch {
+		case count > counts[threshold]:
+			threshold, tie = k, false
+		case count == counts[threshold]:
+			tie = true
+		}
+	}
+
+	sort.Ints(seen)
+
+	if tie {
+		return 0, fmt.Errorf("The shares disagree about the threshold, they claim %v equally often.", seen)
+	}
+
+	if len(seen) > 1 {
+		fmt.Fprintf(diag, "shares claim different thresholds %v, using %d\n", seen, threshold)
+	}
+
+	fmt.Fprintf(diag, "need at least %d shares\n", threshold)
+
+	return threshold, nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGenerate_thresholdInShare(t *testing.T) {
+	var genBuf bytes.Buffer
+
+	err := cmdGenerate(5, 3, generateOptions{thresholdInShare: true}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(genBuf.String()), "\n")
+	secret := strings.TrimPrefix(lines[0], "secret: ")
+	shares := lines[3:]
+
+	for _, share := range shares {
+		if !strings.HasSuffix(share, ",3") || strings.Count(share, ",") != 2 {
+			t.Errorf("share %q does not end in the threshold", share)
+		}
+	}
+
+	var (
+		errBuf bytes.Buffer
+		outBuf bytes.Buffer
+	)
+
+	err = cmdRecover(strings.NewReader(strings.Join(shares[:3], "\n")), recoverOptions{}, &errBuf, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if outBuf.String() != secret+"\n" {
+		t.Errorf("unexpected recovered secret. want %q, have %q", secret, outBuf.String())
+	}
+
+	if errBuf.String() != "need at least 3 shares\n" {
+		t.Errorf("unexpected diagnostic: %q", errBuf.String())
+	}
+
+	err = cmdRecover(strings.NewReader(strings.Join(shares[:2], "\n")), recoverOptions{}, &bytes.Buffer{}, &bytes.Buffer{})
+
+	var insufficient *ErrInsufficientShares
+	if !errors.As(err, &insufficient) || insufficient.Need != 3 {
+		t.Errorf("want ErrInsufficientShares for 3 shares, have %v", err)
+	}
+}
+
+func TestShareThreshold(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		claims   []int
+		want     int
+		wantDiag string
+		wantErr  bool
+	}{
+		{"none", nil, 0, "", false},
+		{"agree", []int{3, 3, 3}, 3, "need at least 3 shares\n", false},
+		{"majority", []int{3, 2, 3}, 3, "shares claim different thresholds [2 3], using 3\nneed at least 3 shares\n", false},
+		{"tie", []int{3, 2, 2, 3, 4}, 0, "", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var diag bytes.Buffer
+
+			have, err := shareThreshold(tc.claims, &diag)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if have != tc.want {
+				t.Errorf("want threshold %d, have %d", tc.want, have)
+			}
+
+			if diag.String() != tc.wantDiag {
+				t.Errorf("unexpected diagnostic: %q", diag.String())
+			}
+		})
+	}
+}
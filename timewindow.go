@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// timeWindowHeader is the name of the header line with the time window the secret may be recovered in.
+const timeWindowHeader = "time-window"
+
+// timeWindow is a time range, such as a maintenance window, that recovery is restricted to. The start is inclusive, the
+// end is exclusive.
+type timeWindow struct {
+	start, end time.Time
+}
+
+// parseTimeWindow parses a time window from RFC 3339 start and end times.
+func parseTimeWindow(start, end string) (timeWindow, error) {
+	if start == "" || end == "" {
+		return timeWindow{}, errors.New("A time window requires -window-start and -window-end.")
+	}
+
+	var (
+		w   timeWindow
+		err error
+	)
+
+	w.start, err = time.Parse(time.RFC3339, start)
+	if err != nil {
+		return timeWindow{}, fmt.Errorf("Invalid window start %q: %w", start, err)
+	}
+
+	w.end, err = time.Parse(time.RFC3339, end)
+	if err != nil {
+		return timeWindow{}, fmt.Errorf("Invalid window end %q: %w", end, err)
+	}
+
+	if !w.start.Before(w.end) {
+		return timeWindow{}, fmt.Errorf("The window start %s is not before its end %s.", start, end)
+	}
+
+	return w, nil
+}
+
+// parseTimeWindowHeader parses the value of a time window header.
+func parseTimeWindowHeader(value string) (timeWindow, error) {
+	start, end, ok := strings.Cut(value, "/")
+	if !ok {
+		return timeWindow{}, fmt.Errorf("Invalid time window header %q.", value)
+	}
+
+	return parseTimeWindow(start, end)
+}
+
+// header returns the value of the time window header for w.
+func (w timeWindow) header() string {
+	return w.start.Format(time.RFC3339) + "/" + w.end.Format(time.RFC3339)
+}
+
+// check returns an error if now is outside of w.
+func (w timeWindow) check(now time.Time) error {
+	if now.Before(w.start) || !now.Before(w.end) {
+		return fmt.Errorf("Recovery is only allowed between %s and %s.", w.start.Format(time.RFC3339), w.end.Format(time.RFC3339))
+	}
+
+	return nil
+}
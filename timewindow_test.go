@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeWindow_recover(t *testing.T) {
+	w, err := parseTimeWindow("2024-01-01T10:00:00Z", "2024-01-01T12:00:00Z")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var genBuf bytes.Buffer
+
+	err = cmdGenerate(3, 2, generateOptions{header: []string{timeWindowHeader + ": " + w.header()}}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	unlocked := strings.Replace(genBuf.String(), timeWindowHeader+": ", "ignored: ", 1)
+
+	for _, tc := range []struct {
+		name  string
+		now   string
+		allow bool
+	}{
+		{"before", "2024-01-01T09:59:59Z", false},
+		{"start", "2024-01-01T10:00:00Z", true},
+		{"during", "2024-01-01T11:00:00Z", true},
+		{"during in another zone", "2024-01-01T12:30:00+01:00", true},
+		{"end", "2024-01-01T12:00:00Z", false},
+		{"after", "2024-01-02T11:00:00Z", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			now, err := time.Parse(time.RFC3339, tc.now)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			clock := func() time.Time { return now }
+
+			for _, c := range []struct {
+				input string
+				opts  recoverOptions
+			}{
+				{genBuf.String(), recoverOptions{now: clock}},
+				{unlocked, recoverOptions{now: clock, timeWindow: &w}},
+			} {
+				var outBuf bytes.Buffer
+
+				err = cmdRecover(strings.NewReader(c.input), c.opts, &bytes.Buffer{}, &outBuf)
+				if tc.allow && err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				if !tc.allow && err == nil {
+					t.Fatal("expected error, got nil")
+				}
+
+				if !tc.allow && outBuf.Len() != 0 {
+					t.Errorf("secret recovered outside of the window: %q", outBuf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestParseTimeWindow_errors(t *testing.T) {
+	for _, tc := range [][2]string{
+		{"", "2024-01-01T12:00:00Z"},
+		{"2024-01-01T10:00:00Z", ""},
+		{"yesterday", "2024-01-01T12:00:00Z"},
+		{"2024-01-01T10:00:00Z", "2024-01-01T10:00:00Z"},
+		{"2024-01-01T12:00:00Z", "2024-01-01T10:00:00Z"},
+	} {
+		_, err := parseTimeWindow(tc[0], tc[1])
+		if err == nil {
+			t.Errorf("expected error for %q, got nil", tc)
+		}
+	}
+
+	_, err := parseTimeWindowHeader("2024-01-01T10:00:00Z")
+	if err == nil {
+		t.Error("expected error, got nil")
+	}
+}
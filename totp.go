@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+)
+
+// totpHeader is the output header that carries the length, the padding and a check code of a split TOTP seed.
+const totpHeader = "totp"
+
+// totpCheckTime is the time of the check code in the totp header. Recovery compares the code of the recovered seed at
+// this time against it.
+var totpCheckTime = time.Unix(0, 0)
+
+// totpParams are the properties of a TOTP seed that are lost when it is converted to a number.
+type totpParams struct {
+	length int    // Length of the raw seed in bytes, so that leading zero bytes are restored.
+	padded bool   // The base32 encoding of the seed has trailing = padding.
+	check  string // TOTP code of the seed at totpCheckTime.
+}
+
+// encoding returns the base32 encoding of seeds with the parameters p.
+func (p totpParams) encoding() *base32.Encoding {
+	if p.padded {
+		return base32.StdEncoding
+	}
+
+	return base32.StdEncoding.WithPadding(base32.NoPadding)
+}
+
+// decodeTOTPSeed decodes a base32 TOTP seed as shown by authenticator apps. Spaces are ignored and lower case letters
+// are accepted.
+func decodeTOTPSeed(s string) ([]byte, totpParams, error) {
+	s = strings.ToUpper(strings.ReplaceAll(s, " ", ""))
+
+	params := totpParams{padded: strings.HasSuffix(s, "=")}
+
+	seed, err := params.encoding().DecodeString(s)
+	if err != nil {
+		return nil, totpParams{}, fmt.Errorf("decoding TOTP seed: %w", err)
+	}
+
+	if len(seed) == 0 {
+		return nil, totpParams{}, errors.New("decoding TOTP seed: empty seed")
+	}
+
+	params.length = len(seed)
+
+	params.check, err = totp.GenerateCode(params.encoding().EncodeToString(seed), totpCheckTime)
+	if err != nil {
+		return nil, totpParams{}, err
+	}
+
+	return seed, params, nil
+}
+
+// encode encodes secret as a base32 TOTP seed with the parameters p. It returns an error if the seed does not produce
+// the check code, which happens if the secret was recovered from the wrong shares.
+func (p totpParams) encode(secret *big.Int) (string, error) {
+	if secret.Sign() < 0 || secret.BitLen() > p.length*8 {
+		return "", errors.New("recovered secret is not a TOTP seed")
+	}
+
+	seed := p.encoding().EncodeToString(secret.FillBytes(make([]byte, p.length)))
+
+	code, err := totp.GenerateCode(seed, totpCheckTime)
+	if err != nil {
+		return "", err
+	}
+
+	if code != p.check {
+		return "", errors.New("recovered TOTP seed does not produce the check code")
+	}
+
+	return seed, nil
+}
+
+// header returns the value of the totp header for p, like "20 unpadded 123456".
+func (p totpParams) header() string {
+	padding := "unpadded"
+	if p.padded {
+		padding = "padded"
+	}
+
+	return fmt.Sprintf("%d %s %s", p.length, padding, p.check)
+}
+
+func parseTOTPHeader(v string) (totpParams, error) {
+	fields := strings.Fields(v)
+	if len(fields) != 3 {
+		return totpParams{}, fmt.Errorf("invalid TOTP header %q", v)
+	}
+
+	length, err := strconv.Atoi(fields[0])
+	if err != nil || length < 1 {
+		return totpParams{}, fmt.Errorf("invalid TOTP header %q", v)
+	}
+
+	params := totpParams{length: length, check: fields[2]}
+
+	switch fields[1] {
+	case "padded":
+		params.padded = true
+	case "unpadded":
+	default:
+		return totpParams{}, fmt.Errorf("invalid TOTP header %q", v)
+	}
+
+	return params, nil
+}
+
+// readTOTPSeed reads a base32 TOTP seed from the named file and returns it as a secret.
+func readTOTPSeed(name string) (*big.Int, totpParams, error) {
+	fh, err := openInput(name)
+	if err != nil {
+		return nil, totpParams{}, err
+	}
+	defer fh.Close()
+
+	buf, err := io.ReadAll(fh)
+	if err != nil {
+		return nil, totpParams{}, err
+	}
+
+	seed, params, err := decodeTOTPSeed(string(bytes.TrimSpace(buf)))
+	if err != nil {
+		return nil, totpParams{}, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return new(big.Int).SetBytes(seed), params, nil
+}
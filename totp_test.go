@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+)
+
+// rfc6238Seed is the base32 encoded SHA-1 seed "12345678901234567890" of the RFC 6238 test vectors.
+const rfc6238Seed = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTP_roundtrip(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		seed string
+		want string
+	}{
+		{"RFC 6238", rfc6238Seed, rfc6238Seed},
+		{"lower case with spaces", "gezd gnbv gy3t qojq gezd gnbv gy3t qojq", rfc6238Seed},
+		{"padded", "AAAQEAYEAUDAOCAJBIFQYDIOB4======", "AAAQEAYEAUDAOCAJBIFQYDIOB4======"},
+		{"leading zero bytes", "AAAAAAAAAEBAGBAFAYDQQCIKBMGA2DQP", "AAAAAAAAAEBAGBAFAYDQQCIKBMGA2DQP"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			name := filepath.Join(t.TempDir(), "seed")
+
+			err := os.WriteFile(name, []byte(tc.seed+"\n"), 0600)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			secret, params, err := readTOTPSeed(name)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			opts := generateOptions{
+				secret:       secret,
+				encodeSecret: params.encode,
+				header:       []string{totpHeader + ": " + params.header()},
+			}
+
+			var genBuf bytes.Buffer
+
+			err = cmdGenerate(5, 3, opts, &genBuf)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			lines := strings.Split(strings.TrimSpace(genBuf.String()), "\n")
+			if lines[0] != "secret: "+tc.want {
+				t.Errorf("unexpected secret line %q", lines[0])
+			}
+
+			var outBuf bytes.Buffer
+
+			err = cmdRecover(strings.NewReader(strings.Join(append(lines[1:3], lines[4:]...), "\n")), recoverOptions{}, &bytes.Buffer{}, &outBuf)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			have := strings.TrimSpace(outBuf.String())
+			if have != tc.want {
+				t.Errorf("unexpected recovered seed. want %q, have %q", tc.want, have)
+			}
+
+			now := time.Now()
+
+			wantCode, err := totp.GenerateCode(tc.want, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			haveCode, err := totp.GenerateCode(have, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if haveCode != wantCode {
+				t.Errorf("unexpected TOTP code. want %s, have %s", wantCode, haveCode)
+			}
+		})
+	}
+}
+
+func TestTOTP_knownCode(t *testing.T) {
+	_, params, err := decodeTOTPSeed(rfc6238Seed)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// The six digit suffix of the RFC 6238 SHA-1 test vector 94287082 at T=59.
+	code, err := totp.GenerateCode(rfc6238Seed, time.Unix(59, 0))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if code != "287082" {
+		t.Errorf("unexpected TOTP code. want 287082, have %s", code)
+	}
+
+	if params.length != 20 || params.padded {
+		t.Errorf("unexpected parameters %+v", params)
+	}
+}
+
+func TestTOTP_wrongShares(t *testing.T) {
+	secret, params, err := decodeTOTPSeed(rfc6238Seed)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	opts := generateOptions{
+		secret:       new(big.Int).SetBytes(secret),
+		encodeSecret: params.encode,
+		header:       []string{totpHeader + ": " + params.header()},
+	}
+
+	var genBuf bytes.Buffer
+
+	err = cmdGenerate(5, 3, opts, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Two shares of a threshold of three recover a wrong seed.
+	lines := strings.Split(strings.TrimSpace(genBuf.String()), "\n")
+
+	err = cmdRecover(strings.NewReader(strings.Join(lines[1:5], "\n")), recoverOptions{}, &bytes.Buffer{}, &bytes.Buffer{})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestDecodeTOTPSeed_invalid(t *testing.T) {
+	for _, s := range []string{"", "not base32!", "GEZDGNBVGY3TQOJQ1"} {
+		_, _, err := decodeTOTPSeed(s)
+		if err == nil {
+			t.Errorf("expected error for %q, got nil", s)
+		}
+	}
+
+	for _, v := range []string{"", "20 padded", "x padded 123456", "20 maybe 123456"} {
+		_, err := parseTOTPHeader(v)
+		if err == nil {
+			t.Errorf("expected error for header %q, got nil", v)
+		}
+	}
+}
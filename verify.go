@@ -0,0 +1,33 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/farhaven/secret/shamir"
+	"github.com/posener/sharedsecret"
+)
+
+// verifyShares recovers the secret from a random subset of k shares with recover, or shamir.RecoverSecret if recover is nil,
+// and returns an error if it does not match secret.
+func verifyShares(shares []sharedsecret.Share, secret *big.Int, k int, recover func([]sharedsecret.Share) *big.Int) error {
+	if recover == nil {
+		recover = shamir.RecoverSecret
+	}
+
+	indices, err := shamir.Perm(len(shares))
+	if err != nil {
+		return err
+	}
+
+	subset := make([]sharedsecret.Share, 0, k)
+	for _, i := range indices[:k] {
+		subset = append(subset, shares[i])
+	}
+
+	if !shamir.VerifyRecoveredSecret(recover(subset), secret.Bytes()) {
+		return errors.New("Verification failed: the shares do not recover the secret.")
+	}
+
+	return nil
+}
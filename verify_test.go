@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/posener/sharedsecret"
+)
+
+func TestVerifyBeforeDistribute(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := cmdGenerate(5, 3, generateOptions{verifyBeforeDistribute: true}, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if buf.Len() == 0 {
+		t.Error("expected output, got nothing")
+	}
+}
+
+func TestVerifyBeforeDistribute_failure(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		stored []storedShare
+		used   int
+	)
+
+	opts := generateOptions{
+		sinks:                  []shareSink{shareSinkFunc(func(shares []storedShare) error { stored = shares; return nil })},
+		verifyBeforeDistribute: true,
+		verifyRecover: func(shares []sharedsecret.Share) *big.Int {
+			used = len(shares)
+			return big.NewInt(42)
+		},
+	}
+
+	err := cmdGenerate(5, 3, opts, &buf)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	if used != 3 {
+		t.Errorf("want verification with 3 shares, have %d", used)
+	}
+
+	if buf.Len() != 0 || stored != nil {
+		t.Errorf("unexpected output after failed verification: %q, %v", buf.String(), stored)
+	}
+}
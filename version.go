@@ -0,0 +1,15 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// Version is the version of secret. Release builds set it with -ldflags "-X main.Version=v1.2.3".
+var Version = "dev"
+
+// cmdVersion writes the version of secret to out.
+func cmdVersion(out io.Writer) error {
+	_, err := fmt.Fprintf(out, "secret version %s\n", Version)
+	return err
+}
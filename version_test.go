@@ -0,0 +1,36 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestVersion(t *testing.T) {
+	fs := flag.NewFlagSet("secret", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	c := newCLIFlags(fs)
+
+	err := fs.Parse([]string{"-version"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !c.version {
+		t.Fatal("-version is not set")
+	}
+
+	var buf bytes.Buffer
+
+	err = cmdVersion(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !strings.Contains(buf.String(), "version") || buf.String() != "secret version "+Version+"\n" {
+		t.Errorf("unexpected output: %q", buf.String())
+	}
+}
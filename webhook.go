@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Headers of the webhook requests. The signature is the hex encoded HMAC-SHA256 of the timestamp, a dot and the body,
+// so that receivers can reject modified and replayed requests.
+const (
+	webhookTimestampHeader = "X-Secret-Timestamp"
+	webhookSignatureHeader = "X-Secret-Signature"
+)
+
+// webhookAttempts is how often a share is posted before the webhook sink gives up on a server error.
+const webhookAttempts = 5
+
+// webhookStore posts each share as JSON to its own webhook URL.
+type webhookStore struct {
+	urls       map[string]string // Webhook URL by share position, starting at "1".
+	key        []byte            // HMAC key the requests are signed with.
+	ceremonyID string
+
+	backoff time.Duration // Delay before the first retry, doubled for every further retry.
+	client  *http.Client
+}
+
+// parseWebhookMap parses a JSON webhook map like {"1": "https://a.example/share", "2": "https://b.example/share"}.
+func parseWebhookMap(m string) (map[string]string, error) {
+	var urls map[string]string
+
+	err := json.Unmarshal([]byte(m), &urls)
+	if err != nil {
+		return nil, fmt.Errorf("parsing webhook map: %w", err)
+	}
+
+	return urls, nil
+}
+
+func newWebhookStore(urls map[string]string, key, ceremonyID string, skipVerify bool) (*webhookStore, error) {
+	if key == "" {
+		return nil, errors.New("Webhook HMAC key must not be empty.")
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: skipVerify}
+
+	return &webhookStore{
+		urls:       urls,
+		key:        []byte(key),
+		ceremonyID: ceremonyID,
+		backoff:    time.Second,
+		client:     &http.Client{Transport: transport, Timeout: time.Minute},
+	}, nil
+}
+
+func (w *webhookStore) StoreShares(shares []storedShare) error {
+	for i, share := range shares {
+		pos := strconv.Itoa(i + 1)
+
+		u, ok := w.urls[pos]
+		if !ok {
+			return fmt.Errorf("No webhook URL for share %s.", pos)
+		}
+
+		index, err := strconv.Atoi(share.index)
+		if err != nil {
+			return err
+		}
+
+		body, err := json.Marshal(struct {
+			Index      int    `json:"index"`
+			Value      string `json:"value"`
+			CeremonyID string `json:"ceremony_id"`
+		}{index, share.line, w.ceremonyID})
+		if err != nil {
+			return err
+		}
+
+		err = w.post(u, body)
+		if err != nil {
+			return fmt.Errorf("posting share %s to webhook: %w", pos, err)
+		}
+	}
+
+	return nil
+}
+
+// post posts body to u and retries with exponential backoff while the server responds with a 5xx status.
+func (w *webhookStore) post(u string, body []byte) error {
+	backoff := w.backoff
+
+	for attempt := 1; ; attempt++ {
+		status, err := w.postOnce(u, body)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case status >= 200 && status < 300:
+			return nil
+		case status >= 500 && attempt < webhookAttempts:
+			time.Sleep(backoff)
+			backoff *= 2
+		default:
+			return fmt.Errorf("%d %s", status, http.StatusText(status))
+		}
+	}
+}
+
+func (w *webhookStore) postOnce(u string, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhookTimestampHeader, ts)
+	req.Header.Set(webhookSignatureHeader, webhookSignature(w.key, ts, body))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	io.Copy(io.Discard, resp.Body)
+
+	return resp.StatusCode, nil
+}
+
+// webhookSignature returns the signature header value for a request with the given timestamp and body.
+func webhookSignature(key []byte, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(ts + "."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// fakeWebhook records the shares posted to it and fails the first failures requests with a server error.
+type fakeWebhook struct {
+	mu       sync.Mutex
+	key      []byte
+	failures int
+	requests int
+	bodies   []map[string]interface{}
+}
+
+func (f *fakeWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests++
+
+	body, _ := io.ReadAll(r.Body)
+
+	if r.Header.Get(webhookSignatureHeader) != webhookSignature(f.key, r.Header.Get(webhookTimestampHeader), body) {
+		http.Error(w, "bad signature", http.StatusUnauthorized)
+		return
+	}
+
+	if f.failures > 0 {
+		f.failures--
+		http.Error(w, "try again", http.StatusServiceUnavailable)
+		return
+	}
+
+	var v map[string]interface{}
+
+	json.Unmarshal(body, &v)
+	f.bodies = append(f.bodies, v)
+}
+
+func newTestWebhookStore(t *testing.T, urls map[string]string) *webhookStore {
+	t.Helper()
+
+	store, err := newWebhookStore(urls, "hmac key", "2026-10", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	store.backoff = 0
+
+	return store
+}
+
+func TestWebhook_storeShares(t *testing.T) {
+	hook := &fakeWebhook{key: []byte("hmac key"), failures: 2}
+
+	srv := httptest.NewTLSServer(hook)
+	defer srv.Close()
+
+	store := newTestWebhookStore(t, map[string]string{"1": srv.URL + "/a", "2": srv.URL + "/b", "3": srv.URL + "/c"})
+
+	err := cmdGenerate(3, 2, generateOptions{sinks: []shareSink{store}, ceremonyID: "2026-10"}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(hook.bodies) != 3 {
+		t.Fatalf("want 3 shares, have %d", len(hook.bodies))
+	}
+
+	// The server errors are retried.
+	if hook.requests != 5 {
+		t.Errorf("want 5 requests, have %d", hook.requests)
+	}
+
+	for _, body := range hook.bodies {
+		if body["ceremony_id"] != "2026-10" {
+			t.Errorf("unexpected ceremony ID: %v", body)
+		}
+
+		if _, ok := body["index"].(float64); !ok {
+			t.Errorf("index is not a number: %v", body)
+		}
+	}
+}
+
+func TestWebhook_errors(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		key        string
+		failures   int
+		skipVerify bool
+		urls       func(url string) map[string]string
+	}{
+		{"missing URL", "hmac key", 0, true, func(url string) map[string]string { return map[string]string{"1": url} }},
+		{"wrong key", "other key", 0, true, func(url string) map[string]string { return map[string]string{"1": url, "2": url} }},
+		{"too many server errors", "hmac key", webhookAttempts, true, func(url string) map[string]string { return map[string]string{"1": url, "2": url} }},
+		{"unknown certificate", "hmac key", 0, false, func(url string) map[string]string { return map[string]string{"1": url, "2": url} }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			hook := &fakeWebhook{key: []byte("hmac key"), failures: tc.failures}
+
+			srv := httptest.NewUnstartedServer(hook)
+			srv.Config.ErrorLog = log.New(io.Discard, "", 0) // Silence the failing handshake with an unknown certificate.
+			srv.StartTLS()
+			defer srv.Close()
+
+			store, err := newWebhookStore(tc.urls(srv.URL), tc.key, "", tc.skipVerify)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			store.backoff = 0
+
+			err = store.StoreShares([]storedShare{
+				{"1", "1,19943338053965968504353533017903769217"},
+				{"2", "2,161872477868088873785792630750634181303"},
+			})
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestNewWebhookStore_emptyKey(t *testing.T) {
+	_, err := newWebhookStore(map[string]string{}, "", "", false)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/farhaven/secret/base58"
+)
+
+// wifHeader is the output header that carries the version and compression flag of a split WIF key.
+const wifHeader = "wif"
+
+const wifKeySize = 32
+
+// wifParams are the parts of a Bitcoin WIF key besides the raw private key.
+type wifParams struct {
+	version    byte // 0x80 for mainnet, 0xef for testnet.
+	compressed bool // The key belongs to a compressed public key.
+}
+
+// decodeWIF decodes a WIF key into the raw private key and its parameters.
+func decodeWIF(s string) ([]byte, wifParams, error) {
+	payload, err := base58.CheckDecode(s)
+	if err != nil {
+		return nil, wifParams{}, fmt.Errorf("decoding WIF key: %w", err)
+	}
+
+	var params wifParams
+
+	switch {
+	case len(payload) == 1+wifKeySize:
+	case len(payload) == 1+wifKeySize+1 && payload[len(payload)-1] == 0x01:
+		params.compressed = true
+	default:
+		return nil, wifParams{}, errors.New("decoding WIF key: invalid length")
+	}
+
+	params.version = payload[0]
+
+	return payload[1 : 1+wifKeySize], params, nil
+}
+
+// encode encodes secret as a WIF key with the parameters p. It returns an error if the secret is not a private key,
+// which happens if the secret was recovered from the wrong shares.
+func (p wifParams) encode(secret *big.Int) (string, error) {
+	if secret.Sign() < 0 || secret.BitLen() > wifKeySize*8 {
+		return "", errors.New("recovered secret is not a WIF key")
+	}
+
+	payload := append([]byte{p.version}, secret.FillBytes(make([]byte, wifKeySize))...)
+	if p.compressed {
+		payload = append(payload, 0x01)
+	}
+
+	return base58.CheckEncode(payload), nil
+}
+
+// header returns the value of the wif header for p, like "80 compressed".
+func (p wifParams) header() string {
+	compression := "uncompressed"
+	if p.compressed {
+		compression = "compressed"
+	}
+
+	return fmt.Sprintf("%02x %s", p.version, compression)
+}
+
+func parseWIFHeader(v string) (wifParams, error) {
+	version, compression, _ := strings.Cut(v, " ")
+
+	b, err := strconv.ParseUint(version, 16, 8)
+	if err != nil {
+		return wifParams{}, fmt.Errorf("invalid WIF header %q", v)
+	}
+
+	params := wifParams{version: byte(b)}
+
+	switch compression {
+	case "compressed":
+		params.compressed = true
+	case "uncompressed":
+	default:
+		return wifParams{}, fmt.Errorf("invalid WIF header %q", v)
+	}
+
+	return params, nil
+}
+
+// readWIF reads a WIF key from the named file and returns it as a secret.
+func readWIF(name string) (*big.Int, wifParams, error) {
+	fh, err := openInput(name)
+	if err != nil {
+		return nil, wifParams{}, err
+	}
+	defer fh.Close()
+
+	buf, err := io.ReadAll(fh)
+	if err != nil {
+		return nil, wifParams{}, err
+	}
+
+	key, params, err := decodeWIF(string(bytes.TrimSpace(buf)))
+	if err != nil {
+		return nil, wifParams{}, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return new(big.Int).SetBytes(key), params, nil
+}
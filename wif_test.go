@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Test vectors from the Bitcoin wiki.
+var wifVectors = []struct {
+	wif    string
+	raw    string
+	params wifParams
+}{
+	{"5HueCGU8rMjxEXxiPuD5BDku4MkFqeZyd4dZ1jvhTVqvbTLvyTJ", "0c28fca386c7a227600b2fe50b7cae11ec86d3bf1fbe471be89827e19d72aa1d", wifParams{0x80, false}},
+	{"KwdMAjGmerYanjeui5SHS7JkmpZvVipYvB2LJGU1ZxJwYvP98617", "0c28fca386c7a227600b2fe50b7cae11ec86d3bf1fbe471be89827e19d72aa1d", wifParams{0x80, true}},
+}
+
+func TestDecodeWIF(t *testing.T) {
+	for _, tc := range wifVectors {
+		key, params, err := decodeWIF(tc.wif)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if hex.EncodeToString(key) != tc.raw {
+			t.Errorf("unexpected key for %s. want %s, have %x", tc.wif, tc.raw, key)
+		}
+
+		if params != tc.params {
+			t.Errorf("unexpected parameters for %s. want %+v, have %+v", tc.wif, tc.params, params)
+		}
+	}
+
+	_, _, err := decodeWIF("5HueCGU8rMjxEXxiPuD5BDku4MkFqeZyd4dZ1jvhTVqvbTLvyTK")
+	if err == nil {
+		t.Error("expected an error for a wrong checksum")
+	}
+}
+
+func TestWIF_roundtrip(t *testing.T) {
+	for _, tc := range wifVectors {
+		name := filepath.Join(t.TempDir(), "key.wif")
+
+		err := os.WriteFile(name, []byte(tc.wif+"\n"), 0600)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		secret, params, err := readWIF(name)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		var genBuf bytes.Buffer
+
+		opts := generateOptions{
+			secret:       secret,
+			encodeSecret: params.encode,
+			header:       []string{wifHeader + ": " + params.header()},
+		}
+
+		err = cmdGenerate(5, 3, opts, &genBuf)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if !strings.HasPrefix(genBuf.String(), "secret: "+tc.wif+"\n") {
+			t.Errorf("unexpected secret line: %q", genBuf.String())
+		}
+
+		var (
+			errBuf bytes.Buffer
+			outBuf bytes.Buffer
+		)
+
+		err = cmdRecover(&genBuf, recoverOptions{}, &errBuf, &outBuf)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if errBuf.Len() != 0 {
+			t.Errorf("unexpected diagnostic: %q", errBuf.String())
+		}
+
+		if outBuf.String() != tc.wif+"\n" {
+			t.Errorf("unexpected recovered key. want %q, have %q", tc.wif, outBuf.String())
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// wizardStep is a step of the recovery wizard.
+type wizardStep int
+
+const (
+	wizardCount  wizardStep = iota // Asking how many shares are available.
+	wizardShares                   // Reading the shares one at a time.
+	wizardSecret                   // Showing the recovered secret.
+)
+
+// wizardEntry is a share typed into the recovery wizard along with the result of checking it.
+type wizardEntry struct {
+	line string
+	err  error
+}
+
+// wizardModel is the bubbletea model of the recovery wizard. It guides a recovery coordinator through entering the
+// shares and shows the recovered secret once k valid shares have been entered.
+type wizardModel struct {
+	k         int
+	step      wizardStep
+	available int
+	input     string
+	entries   []wizardEntry
+	valid     []string
+	secret    string
+	err       error // Problem with the last input, or with recovering the secret.
+}
+
+func newWizardModel(k int) wizardModel {
+	return wizardModel{k: k}
+}
+
+func (m wizardModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m wizardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch key.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyBackspace:
+		if m.input != "" {
+			m.input = m.input[:len(m.input)-1]
+		}
+
+		return m, nil
+	case tea.KeyEnter:
+		m.submit()
+		return m, nil
+	case tea.KeyRunes, tea.KeySpace:
+	default:
+		return m, nil
+	}
+
+	text := string(key.Runes)
+	if key.Type == tea.KeySpace {
+		text = " "
+	}
+
+	// Q only quits when it can not be part of the input, so that pasted shares are not cut short.
+	if m.input == "" && strings.EqualFold(text, "q") {
+		return m, tea.Quit
+	}
+
+	if m.step == wizardSecret {
+		if strings.EqualFold(text, "c") {
+			m.secret = ""
+		}
+
+		return m, nil
+	}
+
+	m.input += text
+
+	return m, nil
+}
+
+// submit handles the input once enter is pressed.
+func (m *wizardModel) submit() {
+	input := strings.TrimSpace(m.input)
+	m.input = ""
+	m.err = nil
+
+	switch m.step {
+	case wizardCount:
+		n, err := strconv.Atoi(input)
+		if err != nil || n < 1 {
+			m.err = fmt.Errorf("%q is not a number of shares", input)
+			return
+		}
+
+		m.available = n
+		m.step = wizardShares
+	case wizardShares:
+		if input == "" {
+			return
+		}
+
+		_, unlabeled := splitShareLabel(input)
+		_, share := splitCeremonyID(unlabeled)
+
+		_, err := parseShare(share)
+		m.entries = append(m.entries, wizardEntry{line: input, err: err})
+
+		if err != nil {
+			return
+		}
+
+		m.valid = append(m.valid, input)
+
+		if len(m.valid) >= m.k {
+			m.recover()
+		}
+	}
+}
+
+// recover recovers the secret from the valid shares with the same rules as cmdRecover.
+func (m *wizardModel) recover() {
+	var (
+		diag bytes.Buffer
+		out  bytes.Buffer
+	)
+
+	err := cmdRecover(strings.NewReader(strings.Join(m.valid, "\n")), recoverOptions{}, &diag, &out)
+	if err != nil {
+		m.err = err
+		return
+	}
+
+	m.secret = strings.TrimSpace(out.String())
+	m.step = wizardSecret
+}
+
+func (m wizardModel) View() string {
+	var b strings.Builder
+
+	b.WriteString("Secret recovery\n\n")
+
+	switch m.step {
+	case wizardCount:
+		fmt.Fprintf(&b, "At least %d shares are needed. How many shares are available? %s\n", m.k, m.input)
+	case wizardShares, wizardSecret:
+		for i, entry := range m.entries {
+			if entry.err != nil {
+				fmt.Fprintf(&b, "✗ share %d is invalid: %s\n", i+1, entry.err)
+				continue
+			}
+
+			fmt.Fprintf(&b, "✓ share %d\n", i+1)
+		}
+
+		have := len(m.valid)
+		if have > m.k {
+			have = m.k
+		}
+
+		fmt.Fprintf(&b, "\n[%s%s] %d of %d shares\n\n", strings.Repeat("#", have), strings.Repeat(" ", m.k-have), len(m.valid), m.k)
+
+		if m.step == wizardShares {
+			fmt.Fprintf(&b, "Paste share %d of %d available: %s\n", len(m.entries)+1, m.available, m.input)
+			break
+		}
+
+		if m.secret == "" {
+			b.WriteString("Secret: (cleared)\n")
+		} else {
+			fmt.Fprintf(&b, "Secret: %s\n", m.secret)
+		}
+
+		b.WriteString("\nPress C to clear the secret from the screen.\n")
+	}
+
+	if m.err != nil {
+		fmt.Fprintf(&b, "\n%s\n", m.err)
+	}
+
+	b.WriteString("Press Q to quit.\n")
+
+	return b.String()
+}
+
+// cmdRecoveryWizard runs the recovery wizard on the terminal.
+func cmdRecoveryWizard(k int) error {
+	if k < 1 {
+		return fmt.Errorf("Invalid threshold %d.", k)
+	}
+
+	_, err := tea.NewProgram(newWizardModel(k), tea.WithAltScreen()).Run()
+
+	return err
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/exp/teatest"
+)
+
+func TestRecoveryWizard(t *testing.T) {
+	tm := teatest.NewTestModel(t, newWizardModel(3), teatest.WithInitialTermSize(120, 40))
+
+	for _, input := range []string{
+		"4",
+		"1,19943338053965968504353533017903769217",
+		"2,foo",
+		"2,161872477868088873785792630750634181303",
+		"5,160274174127002500413544256698187925606",
+	} {
+		tm.Type(input)
+		tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	}
+
+	teatest.WaitFor(t, tm.Output(), func(out []byte) bool {
+		return bytes.Contains(out, []byte("Secret: 7uPIBqGKMPpProBYFFR3S"))
+	}, teatest.WithDuration(5*time.Second))
+
+	tm.Type("c")
+	tm.Type("q")
+
+	final := tm.FinalModel(t, teatest.WithFinalTimeout(5*time.Second)).(wizardModel)
+
+	if final.secret != "" {
+		t.Errorf("secret not cleared: %q", final.secret)
+	}
+
+	if len(final.entries) != 4 || final.entries[1].err == nil {
+		t.Errorf("unexpected entries: %+v", final.entries)
+	}
+}
+
+func TestRecoveryWizard_view(t *testing.T) {
+	var m tea.Model = newWizardModel(2)
+
+	for _, input := range []string{"3", "1,foo", "1,19943338053965968504353533017903769217"} {
+		for _, r := range input {
+			m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	}
+
+	view := m.View()
+
+	for _, want := range []string{"✗ share 1 is invalid", "✓ share 2", "[# ] 1 of 2 shares", "Paste share 3 of 3 available: "} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected view to contain %q, have:\n%s", want, view)
+		}
+	}
+
+	// Q is part of the input unless the input is empty.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("5,q")})
+	if !strings.Contains(m.View(), "available: 5,q") {
+		t.Errorf("q not added to the input:\n%s", m.View())
+	}
+
+	_, cmd := newWizardModel(2).Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	if cmd == nil {
+		t.Fatal("q does not quit")
+	}
+
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("q does not quit")
+	}
+}
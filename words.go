@@ -0,0 +1,163 @@
+package main
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/farhaven/secret/shamir"
+	"github.com/posener/sharedsecret"
+)
+
+// bip39English is the BIP-39 English word list, one word per line.
+//
+//go:embed bip39-english.txt
+var bip39English string
+
+var (
+	bip39Words  = strings.Fields(bip39English)
+	bip39Values = wordValues(bip39Words)
+)
+
+// indexWords spell out the digits of share indices, so that the index is not mistaken for a value word.
+var (
+	indexWords       = []string{"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine"}
+	indexWordsValues = wordValues(indexWords)
+)
+
+func wordValues(words []string) map[string]int {
+	values := make(map[string]int, len(words))
+	for i, word := range words {
+		values[word] = i
+	}
+
+	return values
+}
+
+// encodeWordsValue encodes v as BIP-39 words, each of which stands for 11 bits of v, most significant first.
+func encodeWordsValue(v *big.Int) string {
+	if v.Sign() == 0 {
+		return bip39Words[0]
+	}
+
+	var (
+		words []string
+		digit = new(big.Int)
+		rest  = new(big.Int).Set(v)
+		base  = big.NewInt(int64(len(bip39Words)))
+	)
+
+	for rest.Sign() > 0 {
+		rest.QuoRem(rest, base, digit)
+		words = append(words, bip39Words[digit.Int64()])
+	}
+
+	for i, j := 0, len(words)-1; i < j; i, j = i+1, j-1 {
+		words[i], words[j] = words[j], words[i]
+	}
+
+	return strings.Join(words, " ")
+}
+
+// decodeWordsValue decodes BIP-39 words created by encodeWordsValue.
+func decodeWordsValue(words []string) (*big.Int, error) {
+	if len(words) == 0 {
+		return nil, errors.New("no words")
+	}
+
+	v := new(big.Int)
+	base := big.NewInt(int64(len(bip39Words)))
+
+	for _, word := range words {
+		digit, ok := bip39Values[strings.ToLower(word)]
+		if !ok {
+			return nil, fmt.Errorf("unknown word %q", word)
+		}
+
+		v.Mul(v, base)
+		v.Add(v, big.NewInt(int64(digit)))
+	}
+
+	return v, nil
+}
+
+// encodeIndexWord spells out the decimal digits of the index x, like "four-two" for 42.
+func encodeIndexWord(x *big.Int) string {
+	digits := x.String()
+
+	words := make([]string, len(digits))
+	for i, d := range digits {
+		words[i] = indexWords[d-'0']
+	}
+
+	return strings.Join(words, "-")
+}
+
+// decodeIndexWord decodes an index created by encodeIndexWord.
+func decodeIndexWord(word string) (*big.Int, error) {
+	var digits strings.Builder
+
+	for _, w := range strings.Split(word, "-") {
+		d, ok := indexWordsValues[strings.ToLower(w)]
+		if !ok {
+			return nil, fmt.Errorf("unknown index word %q", w)
+		}
+
+		digits.WriteByte(byte('0' + d))
+	}
+
+	x, _ := new(big.Int).SetString(digits.String(), 10)
+	if x.Sign() <= 0 {
+		return nil, errors.New("invalid index")
+	}
+
+	return x, nil
+}
+
+// formatWordsShare returns share as its index word followed by the words of its value, like "four-two abandon ...".
+// The value is left-padded with "abandon", which stands for zero, to padding words.
+func formatWordsShare(share sharedsecret.Share, padding int) string {
+	x, y := shamir.ShareXY(share)
+
+	words := encodeWordsValue(y)
+	if n := padding - len(strings.Fields(words)); n > 0 {
+		words = strings.Repeat(bip39Words[0]+" ", n) + words
+	}
+
+	return encodeIndexWord(x) + " " + words
+}
+
+// isWordsShare reports whether text looks like a share created by formatWordsShare, that is whether it starts with an
+// index word followed by more words.
+func isWordsShare(text string) bool {
+	words := strings.Fields(text)
+	if len(words) < 2 {
+		return false
+	}
+
+	_, err := decodeIndexWord(words[0])
+
+	return err == nil
+}
+
+// parseWordsShare parses a share created by formatWordsShare.
+func parseWordsShare(text string) (sharedsecret.Share, error) {
+	words := strings.Fields(text)
+	if len(words) < 2 {
+		return sharedsecret.Share{}, errors.New("expected an index word and value words")
+	}
+
+	x, err := decodeIndexWord(words[0])
+	if err != nil {
+		return sharedsecret.Share{}, err
+	}
+
+	y, err := decodeWordsValue(words[1:])
+	if err != nil {
+		return sharedsecret.Share{}, err
+	}
+
+	return shamir.NewShare(x, y), nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/farhaven/secret/shamir"
+)
+
+func TestWords_list(t *testing.T) {
+	if len(bip39Words) != 2048 {
+		t.Fatalf("unexpected number of words: %d", len(bip39Words))
+	}
+
+	if len(bip39Values) != len(bip39Words) {
+		t.Errorf("word list contains duplicates")
+	}
+
+	if bip39Words[0] != "abandon" || bip39Words[2047] != "zoo" {
+		t.Errorf("unexpected word list: %q ... %q", bip39Words[0], bip39Words[2047])
+	}
+}
+
+func TestWords_valueRoundTrip(t *testing.T) {
+	for _, v := range []*big.Int{
+		big.NewInt(0),
+		big.NewInt(1),
+		big.NewInt(2047),
+		big.NewInt(2048),
+		new(big.Int).Sub(shamir.Prime, big.NewInt(1)),
+	} {
+		t.Run(v.String(), func(t *testing.T) {
+			words := encodeWordsValue(v)
+
+			have, err := decodeWordsValue(strings.Fields(words))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if have.Cmp(v) != 0 {
+				t.Errorf("want %s, have %s", v, have)
+			}
+
+			if encodeWordsValue(have) != words {
+				t.Errorf("%q re-encoded as %q", words, encodeWordsValue(have))
+			}
+		})
+	}
+}
+
+func TestWords_share(t *testing.T) {
+	share := shamir.NewShare(big.NewInt(42), big.NewInt(2048+3))
+
+	text := formatWordsShare(share, 0)
+	if text != "four-two ability about" {
+		t.Fatalf("unexpected share text: %q", text)
+	}
+
+	have, err := parseWordsShare(strings.ToUpper(text))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if have.String() != share.String() {
+		t.Errorf("want %s, have %s", share, have)
+	}
+}
+
+func TestWords_parseErrors(t *testing.T) {
+	for _, text := range []string{
+		"four",
+		"zero abandon",
+		"four-x abandon",
+		"four notaword",
+	} {
+		t.Run(text, func(t *testing.T) {
+			_, err := parseWordsShare(text)
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+		})
+	}
+}
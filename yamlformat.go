@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/farhaven/secret/shamir"
+	"github.com/posener/sharedsecret"
+	"gopkg.in/yaml.v3"
+)
+
+// yamlShares is the output of cmdGenerate in the yaml format. The secret is left out if it is written to a separate
+// output.
+type yamlShares struct {
+	Secret    string      `yaml:"secret,omitempty"`
+	Threshold int         `yaml:"threshold"`
+	Shares    []yamlShare `yaml:"shares"`
+}
+
+// yamlShare is a share in the yaml format. The value is a string, since it does not fit into a YAML integer.
+type yamlShare struct {
+	Index int64  `yaml:"index"`
+	Value string `yaml:"value"`
+}
+
+// writeYAMLShares writes the secret and shares as a single YAML document. The document starts with "---", so that
+// cmdRecover detects it even without the secret.
+func writeYAMLShares(out io.Writer, secret string, k int, shares []sharedsecret.Share) error {
+	v := yamlShares{Secret: secret, Threshold: k, Shares: make([]yamlShare, len(shares))}
+	for i, share := range shares {
+		x, y := shamir.ShareXY(share)
+		v.Shares[i] = yamlShare{Index: x.Int64(), Value: y.String()}
+	}
+
+	buf, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = out.Write(append([]byte("---\n"), buf...))
+
+	return err
+}
+
+// readYAMLShares converts the input to the text format if it is the yaml output of cmdGenerate, which starts with
+// "---" or "secret:". Input that only starts with "secret:" is the text output of cmdGenerate unless it parses as YAML.
+// Other input is returned unchanged.
+func readYAMLShares(in io.Reader) (io.Reader, error) {
+	buf, err := io.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+
+	first, _, _ := strings.Cut(strings.TrimSpace(string(buf)), "\n")
+	first = strings.TrimSpace(first)
+
+	isDocument := first == "---"
+	if !isDocument && !strings.HasPrefix(first, "secret:") {
+		return bytes.NewReader(buf), nil
+	}
+
+	var v yamlShares
+
+	err = yaml.Unmarshal(buf, &v)
+	if err != nil || len(v.Shares) == 0 {
+		if !isDocument {
+			return bytes.NewReader(buf), nil
+		}
+
+		if err == nil {
+			err = errors.New("no shares")
+		}
+
+		return nil, fmt.Errorf("reading YAML shares: %w", err)
+	}
+
+	var text strings.Builder
+
+	if v.Threshold > 0 {
+		fmt.Fprintf(&text, "shares (need at least %d of these for recovery):\n", v.Threshold)
+	}
+
+	for _, share := range v.Shares {
+		fmt.Fprintf(&text, "%d,%s\n", share.Index, share.Value)
+	}
+
+	return strings.NewReader(text.String()), nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestYAML_roundtrip(t *testing.T) {
+	var genBuf bytes.Buffer
+
+	err := cmdGenerate(5, 3, generateOptions{format: "yaml"}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !strings.HasPrefix(genBuf.String(), "---\nsecret: ") {
+		t.Errorf("unexpected output: %q", genBuf.String())
+	}
+
+	var generated yamlShares
+
+	err = yaml.Unmarshal(genBuf.Bytes(), &generated)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if generated.Secret == "" || generated.Threshold != 3 || len(generated.Shares) != 5 {
+		t.Fatalf("unexpected output %+v", generated)
+	}
+
+	// Only three shares are left for the recovery.
+	generated.Shares = generated.Shares[2:]
+
+	partial, err := yaml.Marshal(generated)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, tc := range []struct {
+		name  string
+		input string
+	}{
+		{"generated", genBuf.String()},
+		{"without document start", string(partial)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var outBuf bytes.Buffer
+
+			err := cmdRecover(strings.NewReader(tc.input), recoverOptions{}, &bytes.Buffer{}, &outBuf)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if outBuf.String() != generated.Secret+"\n" {
+				t.Errorf("unexpected recovered secret. want %q, have %q", generated.Secret, outBuf.String())
+			}
+		})
+	}
+}
+
+func TestYAML_secretOut(t *testing.T) {
+	var (
+		genBuf    bytes.Buffer
+		secretBuf bytes.Buffer
+	)
+
+	err := cmdGenerate(3, 2, generateOptions{format: "yaml", secretOut: &secretBuf}, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if strings.Contains(genBuf.String(), "secret:") {
+		t.Errorf("secret written to the shares: %q", genBuf.String())
+	}
+
+	var outBuf bytes.Buffer
+
+	err = cmdRecover(&genBuf, recoverOptions{}, &bytes.Buffer{}, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if "secret: "+outBuf.String() != secretBuf.String() {
+		t.Errorf("unexpected recovered secret. want %q, have %q", secretBuf.String(), outBuf.String())
+	}
+}
+
+func TestReadYAMLShares_invalid(t *testing.T) {
+	_, err := readYAMLShares(strings.NewReader("---\nthreshold: 2\nshares: []\n"))
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	in, err := readYAMLShares(strings.NewReader("secret: abc\nshares (need at least 2 of these for recovery):\n1,2\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var buf bytes.Buffer
+	buf.ReadFrom(in)
+
+	if !strings.HasPrefix(buf.String(), "secret: abc\n") {
+		t.Errorf("text output was changed: %q", buf.String())
+	}
+}
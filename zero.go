@@ -0,0 +1,25 @@
+package main
+
+import (
+	"math/big"
+	"runtime"
+)
+
+// zeroSecret overwrites the memory backing secret with zeros, so that the secret does not stay in memory after it is
+// no longer needed. The value of secret is zero afterwards. Copies of the secret, like its encoded text, are not
+// affected.
+func zeroSecret(secret *big.Int) {
+	if secret == nil {
+		return
+	}
+
+	words := secret.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+
+	// Keep the writes from being optimized away before secret is unreachable.
+	runtime.KeepAlive(words)
+
+	secret.SetInt64(0)
+}
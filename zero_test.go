@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+// secretMemory records where the words of a secret are stored, so that they can be inspected after the secret is
+// out of scope.
+type secretMemory struct {
+	ptr *big.Word
+	len int
+}
+
+func recordSecretMemory(secret *big.Int) secretMemory {
+	words := secret.Bits()
+	return secretMemory{ptr: unsafe.SliceData(words), len: len(words)}
+}
+
+func (m secretMemory) cleared() bool {
+	for _, w := range unsafe.Slice(m.ptr, m.len) {
+		if w != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestZeroSecret(t *testing.T) {
+	secret, _ := new(big.Int).SetString("19943338053965968504353533017903769217", 10)
+	mem := recordSecretMemory(secret)
+
+	zeroSecret(secret)
+
+	if !mem.cleared() {
+		t.Error("secret memory not cleared")
+	}
+
+	if secret.Sign() != 0 {
+		t.Errorf("want zero, have %s", secret)
+	}
+
+	zeroSecret(nil)
+}
+
+func TestZeroSecret_commands(t *testing.T) {
+	var (
+		generated secretMemory
+		genBuf    bytes.Buffer
+	)
+
+	opts := generateOptions{
+		encodeSecret: func(secret *big.Int) (string, error) {
+			generated = recordSecretMemory(secret)
+			return secret.Text(62), nil
+		},
+	}
+
+	err := cmdGenerate(5, 3, opts, &genBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if generated.ptr == nil || !generated.cleared() {
+		t.Error("generated secret not cleared")
+	}
+
+	var recovered secretMemory
+
+	recoverOpts := recoverOptions{
+		encodeSecret: func(secret *big.Int) (string, error) {
+			recovered = recordSecretMemory(secret)
+			return secret.Text(62), nil
+		},
+	}
+
+	var outBuf bytes.Buffer
+
+	err = cmdRecover(strings.NewReader(genBuf.String()), recoverOpts, &bytes.Buffer{}, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if recovered.ptr == nil || !recovered.cleared() {
+		t.Error("recovered secret not cleared")
+	}
+
+	if !strings.HasPrefix(genBuf.String(), "secret: "+strings.TrimSpace(outBuf.String())+"\n") {
+		t.Errorf("unexpected recovered secret: %q", outBuf.String())
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/farhaven/secret/shamir"
+	"github.com/yeka/zip"
+)
+
+const (
+	envelopeSecretFile    = "secret.txt"
+	envelopeChecksumsFile = "SHA256SUMS"
+)
+
+// cmdGenerateEnvelope generates n shares of a random secret and seals them, the secret and a checksum file in an AES-256
+// encrypted ZIP file written to envelope. The password of the ZIP file is split into n sub-passwords in turn, k of
+// which open the envelope. Only the sub-passwords are written to out. The shares and the sub-passwords carry the
+// ceremony ID if it is set.
+func cmdGenerateEnvelope(n, k int, password, ceremonyID string, envelope io.Writer, out io.Writer) error {
+	if k > n {
+		return errors.New("There will not be enough shares to recover the secret.")
+	}
+
+	if n < 1 || k < 1 {
+		return errors.New("Number of shares must be larger than 1.")
+	}
+
+	if password == "" {
+		return errors.New("Envelope password must not be empty.")
+	}
+
+	err := validateCeremonyID(ceremonyID)
+	if err != nil {
+		return err
+	}
+
+	shares, secret, err := shamir.GenerateShares(n, k, nil, shamir.PoolSize(n))
+	if err != nil {
+		return err
+	}
+
+	passwords, _, err := shamir.GenerateShares(n, k, new(big.Int).SetBytes([]byte(password)), shamir.PoolSize(n))
+	if err != nil {
+		return err
+	}
+
+	files := map[string]string{envelopeSecretFile: "secret: " + secret.Text(62) + "\n"}
+	for _, share := range shares {
+		files["share-"+shareIndex(share)+".txt"] = withCeremonyID(ceremonyID, share.String()) + "\n"
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var sums strings.Builder
+
+	for _, name := range names {
+		fmt.Fprintf(&sums, "%x  %s\n", sha256.Sum256([]byte(files[name])), name)
+	}
+
+	files[envelopeChecksumsFile] = sums.String()
+	names = append(names, envelopeChecksumsFile)
+
+	zw := zip.NewWriter(envelope)
+
+	for _, name := range names {
+		w, err := zw.Encrypt(name, password, zip.AES256Encryption)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.WriteString(w, files[name])
+		if err != nil {
+			return err
+		}
+	}
+
+	err = zw.Close()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "envelope passwords (need at least %d of these to open the envelope):\n", k)
+	for _, share := range passwords {
+		fmt.Fprintln(out, withCeremonyID(ceremonyID, share.String()))
+	}
+
+	return nil
+}
+
+// cmdOpenEnvelope recovers the password of an envelope created by cmdGenerateEnvelope from the sub-passwords read from
+// in, checks the contents of the envelope against its checksum file and writes the secret to out. Only sub-passwords
+// of the ceremony ceremonyID are used, or of the ceremony most sub-passwords belong to if it is empty.
+func cmdOpenEnvelope(envelope io.ReaderAt, size int64, in io.Reader, ceremonyID string, diag io.Writer, out io.Writer) error {
+	var read []ceremonyShare
+
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		t := strings.TrimSpace(scanner.Text())
+
+		if t == "" || strings.HasPrefix(t, "envelope passwords") {
+			continue
+		}
+
+		id, share := splitCeremonyID(t)
+
+		s, err := parseShare(share)
+		if err != nil {
+			fmt.Fprintf(diag, "reading share %q: %s\n", t, err)
+			continue
+		}
+
+		read = append(read, ceremonyShare{line: t, id: id, share: s})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	_, passwords, err := selectCeremony(ceremonyID, read, diag)
+	if err != nil {
+		return err
+	}
+
+	recovered, err := shamir.Combine(passwords)
+	if err != nil {
+		return err
+	}
+
+	password := string(recovered.Bytes())
+
+	zr, err := zip.NewReader(envelope, size)
+	if err != nil {
+		return fmt.Errorf("reading envelope: %w", err)
+	}
+
+	files := make(map[string][]byte)
+
+	for _, f := range zr.File {
+		f.SetPassword(password)
+
+		buf, err := readZipFile(f)
+		if err != nil {
+			return fmt.Errorf("Opening envelope failed, the passwords may be wrong: %s: %w", f.Name, err)
+		}
+
+		files[f.Name] = buf
+	}
+
+	sums, ok := files[envelopeChecksumsFile]
+	if !ok {
+		return errors.New("Envelope has no checksum file.")
+	}
+
+	checked := 0
+
+	for _, line := range strings.Split(strings.TrimSpace(string(sums)), "\n") {
+		sum, name, ok := strings.Cut(line, "  ")
+		if !ok {
+			return fmt.Errorf("Invalid checksum line %q.", line)
+		}
+
+		buf, ok := files[name]
+		if !ok {
+			return fmt.Errorf("Envelope is missing %s.", name)
+		}
+
+		have := sha256.Sum256(buf)
+		if hex.EncodeToString(have[:]) != sum {
+			return fmt.Errorf("Checksum of %s does not match, the envelope was tampered with.", name)
+		}
+
+		checked++
+	}
+
+	if checked != len(files)-1 {
+		return errors.New("Envelope contains files without checksum, the envelope was tampered with.")
+	}
+
+	secret, ok := strings.CutPrefix(strings.TrimSpace(string(files[envelopeSecretFile])), "secret: ")
+	if !ok {
+		return errors.New("Envelope has no secret file.")
+	}
+
+	fmt.Fprintln(out, secret)
+
+	return nil
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return io.ReadAll(rc)
+}
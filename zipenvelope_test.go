@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/farhaven/secret/shamir"
+	"github.com/yeka/zip"
+)
+
+func TestEnvelope_seal(t *testing.T) {
+	var (
+		envelope bytes.Buffer
+		outBuf   bytes.Buffer
+	)
+
+	err := cmdGenerateEnvelope(5, 3, "hunter2", "", &envelope, &outBuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(outBuf.String()), "\n")
+	if len(lines) != 6 || lines[0] != "envelope passwords (need at least 3 of these to open the envelope):" {
+		t.Fatalf("unexpected output: %q", outBuf.String())
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(envelope.Bytes()), int64(envelope.Len()))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	files := make(map[string][]byte)
+
+	for _, f := range zr.File {
+		if !f.IsEncrypted() {
+			t.Errorf("%s is not encrypted", f.Name)
+		}
+
+		f.SetPassword("hunter2")
+
+		files[f.Name], err = readZipFile(f)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	if len(files) != 7 || files[envelopeSecretFile] == nil || files[envelopeChecksumsFile] == nil {
+		t.Fatalf("unexpected envelope contents: %q", files)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		password []string
+		wantErr  bool
+	}{
+		{"enough passwords", lines[2:5], false},
+		{"too few passwords", lines[1:3], true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				errBuf bytes.Buffer
+				outBuf bytes.Buffer
+			)
+
+			in := strings.NewReader(strings.Join(tc.password, "\n"))
+
+			err := cmdOpenEnvelope(bytes.NewReader(envelope.Bytes()), int64(envelope.Len()), in, "", &errBuf, &outBuf)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, opened %q", outBuf.String())
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if want := strings.TrimPrefix(string(files[envelopeSecretFile]), "secret: "); outBuf.String() != want {
+				t.Errorf("unexpected secret. want %q, have %q", want, outBuf.String())
+			}
+		})
+	}
+}
+
+func TestEnvelope_tampered(t *testing.T) {
+	// An envelope with a share file that is not covered by the checksums.
+	var envelope bytes.Buffer
+
+	zw := zip.NewWriter(&envelope)
+
+	for name, content := range map[string]string{
+		envelopeSecretFile:    "secret: abc\n",
+		envelopeChecksumsFile: "0000000000000000000000000000000000000000000000000000000000000000  secret.txt\n",
+	} {
+		w, err := zw.Encrypt(name, "hunter2", zip.AES256Encryption)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		w.Write([]byte(content))
+	}
+
+	zw.Close()
+
+	passwords, _, err := shamir.GenerateShares(3, 2, new(big.Int).SetBytes([]byte("hunter2")), 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	in := strings.NewReader(passwords[0].String() + "\n" + passwords[1].String())
+
+	err = cmdOpenEnvelope(bytes.NewReader(envelope.Bytes()), int64(envelope.Len()), in, "", &bytes.Buffer{}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "tampered") {
+		t.Errorf("unexpected error for a tampered envelope: %v", err)
+	}
+}